@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/nomnel/ghi/internal/api"
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/config"
+	"github.com/nomnel/ghi/internal/gh"
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// setup resolves the configuration and global flags before any command runs:
+// it creates the backend selected by --backend for the repository given by
+// --repo (unless one was injected) and picks the directory the repository's
+// files live in. Flags override environment variables, which override
+// .ghi.yaml.
+func (a *app) setup(cmd *cobra.Command, args []string) error {
+	if err := a.setupOutput(cmd); err != nil {
+		return err
+	}
+	
+	if a.cfg == nil {
+		cfg, err := config.Find()
+		if err != nil {
+			return model.NewIOError("failed to load "+config.FileName, err)
+		}
+		a.cfg = cfg
+	}
+	
+	name, _ := cmd.Flags().GetString("backend")
+	if name == "" {
+		name = os.Getenv("GHI_BACKEND")
+	}
+	if name == "" {
+		name = a.cfg.Backend
+	}
+	
+	repo, _ := cmd.Flags().GetString("repo")
+	if repo == "" {
+		repo = a.cfg.Repo
+	}
+	if repo != "" {
+		if _, _, err := backend.SplitRepo(repo); err != nil {
+			return model.NewUsageError(err.Error())
+		}
+	}
+	
+	a.verbose, _ = cmd.Flags().GetBool("verbose")
+	a.dryRun, _ = cmd.Flags().GetBool("dry-run")
+	if a.connect == nil {
+		// Every repository shares the retrier: they draw on the same quota.
+		retry := backend.NewRetrier()
+		if a.verbose {
+			retry.Log = a.errOut
+		}
+		a.connect = func(repo string) (backend.Backend, error) {
+			return newBackend(name, repo, a.cfg.Timeout, retry)
+		}
+	}
+	if a.client != nil {
+		a.connected = append(a.connected, a.client)
+	}
+	connect := a.connect
+	a.connect = func(repo string) (backend.Backend, error) {
+		b, err := connect(repo)
+		if err == nil {
+			a.connected = append(a.connected, b)
+		}
+		return b, err
+	}
+	if a.client == nil {
+		b, err := a.connect(repo)
+		if err != nil {
+			return err
+		}
+		a.client = b
+	}
+	a.repo = repo
+	
+	workspace := a.cfg.Workspace
+	if cmd.Flags().Changed("workspace") {
+		workspace, _ = cmd.Flags().GetBool("workspace")
+	} else if env := os.Getenv("GHI_WORKSPACE"); env != "" {
+		workspace, _ = strconv.ParseBool(env)
+	}
+	
+	switch {
+	case !workspace:
+		a.dir = a.cfg.IssuesDir
+	case repo != "":
+		a.dir = filepath.Join(a.cfg.IssuesDir, repo)
+	case spansWorkspace(cmd):
+		// Resolved per repository by eachRepo.
+		a.dir = ""
+	default:
+		current, err := a.client.Repository()
+		if err != nil {
+			return backendError(err)
+		}
+		a.dir = filepath.Join(a.cfg.IssuesDir, current)
+	}
+	a.files = &fileIndex{}
+	
+	return nil
+}
+
+// newBackend selects the gh CLI adapter (the default) or the native API
+// client for repo, or for the current repository when repo is empty.
+func newBackend(name string, repo string, timeout time.Duration, retry *backend.Retrier) (backend.Backend, error) {
+	switch name {
+	case "", "gh":
+		c := gh.New()
+		c.Repo = repo
+		c.Timeout = timeout
+		c.Retry = retry
+		return c, nil
+	case "api":
+		c, err := api.NewFromEnvironment(repo)
+		if err != nil {
+			return nil, backendError(err)
+		}
+		c.HTTPClient.Timeout = timeout
+		c.Retry = retry
+		return c, nil
+	}
+	return nil, model.NewUsageError(fmt.Sprintf("unknown backend %q: use 'gh' or 'api'", name))
+}
+
+// reportQuotas prints the remaining rate limit quota in verbose mode. The
+// repositories of a workspace share the quota of one token, so each resource
+// is reported once, with the lowest count seen.
+func (a *app) reportQuotas() {
+	quotas := map[string]backend.Quota{}
+	for _, b := range a.connected {
+		r, ok := b.(backend.QuotaReporter)
+		if !ok {
+			continue
+		}
+		list, err := r.Quotas()
+		if err != nil {
+			fmt.Fprintf(a.errOut, "Warning: failed to read rate limit: %v\n", err)
+			continue
+		}
+		for _, q := range list {
+			if prev, ok := quotas[q.Resource]; !ok || q.Remaining < prev.Remaining {
+				quotas[q.Resource] = q
+			}
+		}
+	}
+	
+	for _, resource := range slices.Sorted(maps.Keys(quotas)) {
+		q := quotas[resource]
+		fmt.Fprintf(a.errOut, "Rate limit (%s): %d of %d remaining, resets at %s\n", q.Resource, q.Remaining, q.Limit, q.Reset.Local().Format("15:04:05"))
+	}
+}
+
+// backendError converts a failed GitHub operation into an exit error. Invalid
+// requests (unknown flags, bad filter values) are usage errors; everything
+// else is an environment error.
+func backendError(err error) error {
+	if backend.IsKind(err, backend.KindInvalid) {
+		return model.NewUsageError(err.Error())
+	}
+	return model.NewEnvError("", err)
+}
\ No newline at end of file
@@ -0,0 +1,506 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/merge"
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// pullWorkers bounds how many issue files are written concurrently.
+const pullWorkers = 8
+
+// forEach calls fn for every index in [0, n) on at most workers goroutines.
+func forEach(n, workers int, fn func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i := range n {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			fn(i)
+		})
+	}
+	wg.Wait()
+}
+
+// maxIssueRange bounds how many issues one range such as 20-30 may name, so
+// a typo cannot queue millions of requests.
+const maxIssueRange = 1000
+
+// parseIssueArgs expands issue numbers and inclusive ranges such as 20-30,
+// dropping duplicates while keeping the order they were given in.
+func parseIssueArgs(args []string) ([]string, error) {
+	var numbers []string
+	seen := map[string]bool{}
+	add := func(n int) {
+		s := strconv.Itoa(n)
+		if !seen[s] {
+			seen[s] = true
+			numbers = append(numbers, s)
+		}
+	}
+	
+	for _, arg := range args {
+		if model.IsNumeric(arg) {
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid issue number: %s", arg)
+			}
+			add(n)
+			continue
+		}
+		
+		from, to, ok := strings.Cut(arg, "-")
+		if !ok || !model.IsNumeric(from) || !model.IsNumeric(to) {
+			return nil, fmt.Errorf("invalid issue number or range: %s", arg)
+		}
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid issue range: %s", arg)
+		}
+		end, err := strconv.Atoi(to)
+		if err != nil || start > end {
+			return nil, fmt.Errorf("invalid issue range: %s", arg)
+		}
+		if end-start >= maxIssueRange {
+			return nil, fmt.Errorf("issue range %s is too large: at most %d issues per range (use --all instead)", arg, maxIssueRange)
+		}
+		for n := start; n <= end; n++ {
+			add(n)
+		}
+	}
+	
+	return numbers, nil
+}
+
+type pullResult struct {
+	number    string
+	updatedAt string
+	outcome   pullOutcome
+	conflicts []string
+	err       error
+}
+
+func (a *app) runBulkPull(cmd *cobra.Command, numberArgs []string, listArgs []string, all bool, force bool, comments bool) error {
+	const usage = "Usage: ghi pull <issue-number>... | --all | -- LIST_OPTIONS..."
+	
+	if all && (len(numberArgs) > 0 || listArgs != nil) {
+		return model.NewUsageError("--all cannot be combined with issue numbers or list options")
+	}
+	full, _ := cmd.Flags().GetBool("full")
+	if full && !all {
+		return model.NewUsageError("--full can only be used with --all")
+	}
+	if !all && len(numberArgs) == 0 && listArgs == nil {
+		return model.NewUsageError(usage)
+	}
+	
+	numbers, err := parseIssueArgs(numberArgs)
+	if err != nil {
+		return model.NewUsageError(fmt.Sprintf("%v\n%s", err, usage))
+	}
+	
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return model.NewIOError("failed to create issues directory", err)
+	}
+	
+	var issues []*model.IssueData
+	var missing []string
+	bases := store.Open(a.dir)
+	state, _ := cmd.Flags().GetString("state")
+	var mark, since time.Time
+	
+	if all {
+		if mark, err = bases.LoadWatermark(state); err != nil {
+			return model.NewIOError("failed to load watermark", err)
+		}
+		switch value, _ := cmd.Flags().GetString("since"); {
+		case value != "":
+			if since, err = parseSince(value, time.Now()); err != nil {
+				return model.NewUsageError(err.Error())
+			}
+		case !full:
+			since = mark
+		}
+		
+		issues, err = a.client.ListAllIssues(state, since)
+		if err != nil {
+			return backendError(err)
+		}
+	} else {
+		if listArgs != nil {
+			opts, err := backend.ParseListArgs(listArgs)
+			if err != nil {
+				return model.NewUsageError(err.Error())
+			}
+			items, err := a.client.ListIssues(opts)
+			if err != nil {
+				return backendError(err)
+			}
+			more := make([]string, 0, len(items))
+			for _, item := range items {
+				more = append(more, strconv.Itoa(item.Number))
+			}
+			numbers, _ = parseIssueArgs(append(numbers, more...))
+		}
+		
+		found, err := a.client.ViewIssues(numbers)
+		if err != nil {
+			return backendError(err)
+		}
+		for _, n := range numbers {
+			if issue, ok := found[n]; ok {
+				issues = append(issues, issue)
+			} else {
+				missing = append(missing, n)
+			}
+		}
+	}
+	
+	results := make([]pullResult, len(issues))
+	forEach(len(issues), pullWorkers, func(i int) {
+		r := &results[i]
+		r.number = strconv.Itoa(issues[i].Number)
+		r.updatedAt = issues[i].UpdatedAt
+		
+		// A mirror refresh never touches files being edited.
+		if all && !force {
+			modified, err := a.locallyModified(bases, r.number)
+			if err != nil || modified {
+				r.outcome, r.err = pullSkipped, err
+				return
+			}
+		}
+		r.outcome, r.conflicts, r.err = a.pullIssue(issues[i], force, comments)
+	})
+	
+	// Unless --since skipped part of what changed since the old watermark,
+	// the watermark moves up to the newest issue seen, but not past one that
+	// still has to be pulled.
+	if all && (since.IsZero() || !since.After(mark)) {
+		if next := nextWatermark(mark, results); !next.Equal(mark) {
+			if err := bases.SaveWatermark(state, next); err != nil {
+				return model.NewIOError("failed to save watermark", err)
+			}
+		}
+	}
+	
+	return a.reportBulkPull(results, missing)
+}
+
+// locallyModified reports whether an issue's file was edited since its last
+// pull. Files that cannot be parsed count as edited.
+func (a *app) locallyModified(bases *store.Store, issueNumber string) (bool, error) {
+	base, err := bases.Load(issueNumber)
+	if err != nil {
+		return false, model.NewIOError("failed to load base snapshot", err)
+	}
+	if base == nil {
+		return false, nil
+	}
+	
+	local, err := a.readLocal(a.issuePath(issueNumber, ""), base)
+	if err != nil {
+		return !os.IsNotExist(err), nil
+	}
+	return merge.Modified(base.Snapshot, local.Snapshot) || merge.CommentsModified(base.Comments, local.Comments), nil
+}
+
+// nextWatermark returns the newest update time among the pulled issues, or
+// the oldest of the issues that were skipped or failed if that is earlier,
+// so the next incremental pull fetches those again.
+func nextWatermark(mark time.Time, results []pullResult) time.Time {
+	next := mark
+	var pending time.Time
+	for _, r := range results {
+		t, err := time.Parse(time.RFC3339, r.updatedAt)
+		if err != nil {
+			continue
+		}
+		if t.After(next) {
+			next = t
+		}
+		if (r.err != nil || r.outcome == pullSkipped) && (pending.IsZero() || t.Before(pending)) {
+			pending = t
+		}
+	}
+	if !pending.IsZero() && pending.Before(next) {
+		return pending
+	}
+	return next
+}
+
+// parseSince reads a --since value: an RFC 3339 time, a date, or a duration
+// before now such as 36h or 7d.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a date (2024-05-01), a time (2024-05-01T12:00:00Z) or a duration (48h, 7d)", value)
+}
+
+func (a *app) reportBulkPull(results []pullResult, missing []string) error {
+	groups := map[pullOutcome][]string{}
+	var failed []pullResult
+	var conflicted []string
+	
+	a.listResults()
+	for _, r := range results {
+		if r.err != nil {
+			a.emit(a.failedResult(r.number, a.issuePath(r.number, ""), r.err))
+			failed = append(failed, r)
+			continue
+		}
+		result := a.result(r.number, a.issuePath(r.number, ""), r.outcome.String())
+		result.Conflicts = r.conflicts
+		a.emit(result)
+		groups[r.outcome] = append(groups[r.outcome], "#"+r.number)
+		if r.outcome == pullConflict {
+			conflicted = append(conflicted, a.issuePath(r.number, ""))
+		}
+	}
+	
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	for _, g := range []struct {
+		name    string
+		outcome pullOutcome
+	}{
+		{"created", pullCreated},
+		{"updated", pullUpdated},
+		{"unchanged", pullUnchanged},
+		{"merged", pullMerged},
+		{"kept local", pullKept},
+		{"conflict", pullConflict},
+		{"skipped", pullSkipped},
+	} {
+		if list := groups[g.outcome]; len(list) > 0 {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", g.name, len(list), strings.Join(list, " "))
+		}
+	}
+	for _, n := range missing {
+		a.emit(a.result(n, "", "not_found"))
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(w, "not found\t%d\t#%s\n", len(missing), strings.Join(missing, " #"))
+	}
+	if len(results) == 0 && len(missing) == 0 {
+		fmt.Fprintln(w, "No issues to pull")
+	}
+	w.Flush()
+	
+	for _, r := range results {
+		if r.err == nil && r.outcome == pullSkipped {
+			fmt.Fprintf(a.errOut, "Skipped %s: modified locally. Push it, or run 'ghi pull %s' to merge the remote changes.\n", a.issuePath(r.number, ""), r.number)
+		}
+	}
+	for _, r := range failed {
+		fmt.Fprintf(a.errOut, "#%s: %v\n", r.number, r.err)
+	}
+	
+	switch {
+	case len(failed) > 0:
+		code := model.ExitIO
+		var exitErr *model.ExitError
+		if errors.As(failed[0].err, &exitErr) {
+			code = exitErr.Code
+		}
+		return &model.ExitError{Code: code, Message: fmt.Sprintf("failed to pull %d issue(s)", len(failed))}
+	case len(missing) > 0:
+		return model.NewEnvError(fmt.Sprintf("%d issue(s) not found", len(missing)), nil)
+	case len(conflicted) > 0:
+		return model.NewConflictError(fmt.Sprintf("Conflicts in %s. Resolve them and push.", strings.Join(conflicted, ", ")))
+	}
+	return nil
+}
+
+// pushWorkers is kept small because GitHub discourages concurrent mutations
+// and answers bursts with secondary rate limits.
+const pushWorkers = 3
+
+type pushResult struct {
+	number    string
+	outcome   pushOutcome
+	conflicts []string
+	err       error
+	// mutations is what a dry run would do.
+	mutations []mutation
+}
+
+func (a *app) runBulkPush(args []string, all bool) error {
+	const usage = "Usage: ghi push <issue-number>... | --all"
+	
+	if all && len(args) > 0 {
+		return model.NewUsageError("--all cannot be combined with issue numbers")
+	}
+	if !all && len(args) == 0 {
+		return model.NewUsageError(usage)
+	}
+	
+	var numbers []string
+	if all {
+		var err error
+		numbers, err = a.modifiedIssueNumbers()
+		if err != nil {
+			return err
+		}
+	} else {
+		var err error
+		numbers, err = parseIssueArgs(args)
+		if err != nil {
+			return model.NewUsageError(fmt.Sprintf("%v\n%s", err, usage))
+		}
+	}
+	
+	a.listResults()
+	if len(numbers) == 0 {
+		fmt.Fprintln(a.out, "No modified issues to push")
+		return nil
+	}
+	
+	results := make([]pushResult, len(numbers))
+	var printMu sync.Mutex
+	
+	// Rate limits are retried by the backend, which shares one retrier
+	// across the workers.
+	forEach(len(numbers), pushWorkers, func(i int) {
+		r := &results[i]
+		r.number = numbers[i]
+		if a.dryRun {
+			r.err = a.planPush(r)
+		} else {
+			r.outcome, r.conflicts, r.err = a.pushIssue(r.number)
+		}
+		
+		printMu.Lock()
+		defer printMu.Unlock()
+		for _, m := range r.mutations {
+			m.write(a.out)
+		}
+		switch {
+		case r.err != nil:
+			fmt.Fprintf(a.out, "#%s failed: %v\n", r.number, r.err)
+		case a.dryRun && r.outcome == pushConflict:
+			fmt.Fprintf(a.out, "#%s would conflict (%s)\n", r.number, strings.Join(r.conflicts, ", "))
+		case a.dryRun:
+			fmt.Fprintf(a.out, "#%s would be %s\n", r.number, r.outcome)
+		case r.outcome == pushConflict:
+			fmt.Fprintf(a.out, "#%s conflict (%s)\n", r.number, strings.Join(r.conflicts, ", "))
+		case r.outcome == pushMerged:
+			fmt.Fprintf(a.out, "#%s merged remote changes and updated\n", r.number)
+		default:
+			fmt.Fprintf(a.out, "#%s updated\n", r.number)
+		}
+	})
+	
+	return a.reportBulkPush(results)
+}
+
+// modifiedIssueNumbers returns the files that differ from the version recorded
+// by the last pull. Files without a base are skipped with a warning, since it
+// is unknown whether they were edited.
+func (a *app) modifiedIssueNumbers() ([]string, error) {
+	all, err := a.localIssueNumbers()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, model.NewIOError(fmt.Sprintf("%s directory does not exist", a.dir), nil)
+		}
+		return nil, model.NewIOError("failed to read issues directory", err)
+	}
+	
+	bases := store.Open(a.dir)
+	var numbers []string
+	for _, n := range all {
+		base, err := bases.Load(n)
+		if err != nil {
+			return nil, model.NewIOError("failed to load base snapshot", err)
+		}
+		if base == nil {
+			fmt.Fprintf(a.errOut, "Skipping %s: no record of the last pull. Run 'ghi push %s' to push it explicitly.\n", a.issuePath(n, ""), n)
+			continue
+		}
+		
+		local, err := a.readLocal(a.issuePath(n, ""), base)
+		if err != nil || merge.Modified(base.Snapshot, local.Snapshot) || merge.CommentsModified(base.Comments, local.Comments) {
+			// Unreadable files are pushed too so the error is reported per issue.
+			numbers = append(numbers, n)
+		}
+	}
+	
+	return numbers, nil
+}
+
+func (a *app) reportBulkPush(results []pushResult) error {
+	var failures []string
+	code := model.ExitConflict
+	failed := false
+	pushed := 0
+	
+	for _, r := range results {
+		path := a.issuePath(r.number, "")
+		if r.err != nil {
+			a.emit(a.failedResult(r.number, path, r.err))
+		} else {
+			result := a.result(r.number, path, r.outcome.String())
+			result.Conflicts = r.conflicts
+			if a.dryRun {
+				result.DryRun = true
+				result.Mutations = r.mutations
+			}
+			a.emit(result)
+		}
+		
+		switch {
+		case r.err != nil:
+			// Report the first real error's exit code; conflicts alone exit 4.
+			if !failed {
+				failed = true
+				code = model.ExitIO
+				var exitErr *model.ExitError
+				if errors.As(r.err, &exitErr) {
+					code = exitErr.Code
+				}
+			}
+			failures = append(failures, fmt.Sprintf("  #%s: %v", r.number, r.err))
+		case r.outcome == pushConflict:
+			failures = append(failures, fmt.Sprintf("  #%s: conflicts in %s (%s)", r.number, path, strings.Join(r.conflicts, ", ")))
+		default:
+			pushed++
+		}
+	}
+	
+	if a.dryRun {
+		fmt.Fprintf(a.out, "Would push %d of %d issue(s)\n%s\n", pushed, len(results), dryRunNote)
+	} else {
+		fmt.Fprintf(a.out, "Pushed %d of %d issue(s)\n", pushed, len(results))
+	}
+	
+	if len(failures) > 0 {
+		return &model.ExitError{
+			Code:    code,
+			Message: fmt.Sprintf("failed to push %d issue(s):\n%s", len(failures), strings.Join(failures, "\n")),
+		}
+	}
+	return nil
+}
\ No newline at end of file
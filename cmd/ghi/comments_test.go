@@ -0,0 +1,148 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func TestPullComments(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.backend.AddRemoteComment(1, "alice", "First!")
+	
+	h.mustRun(0, "pull", "1")
+	if strings.Contains(h.read("issues/1.md"), "First!") {
+		t.Fatalf("comments pulled without --comments")
+	}
+	
+	h.mustRun(0, "pull", "--comments", "1")
+	got := h.read("issues/1.md")
+	if !strings.HasPrefix(got, sampleFile+"\n<!-- ghi:comments") {
+		t.Errorf("comments section does not follow the body:\n%s", got)
+	}
+	if !strings.Contains(got, "author=@alice") || !strings.Contains(got, "\nFirst!\n<!-- /ghi:comment -->\n") {
+		t.Errorf("comment missing:\n%s", got)
+	}
+	
+	// Once present, comments stay in sync without the flag.
+	h.backend.AddRemoteComment(1, "bob", "Second")
+	h.mustRun(0, "pull", "1")
+	if got := h.read("issues/1.md"); !strings.Contains(got, "Second") {
+		t.Errorf("new comment not pulled:\n%s", got)
+	}
+}
+
+func TestPushComments(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	mine := h.backend.AddRemoteComment(1, "me", "my comment")
+	h.backend.AddRemoteComment(1, "alice", "her comment")
+	h.mustRun(0, "pull", "--comments", "1")
+	
+	// A round trip does not touch comments.
+	h.backend.Calls = nil
+	h.mustRun(0, "push", "1")
+	if slices.ContainsFunc(h.backend.Calls, func(c string) bool { return strings.Contains(c, "Comment ") }) {
+		t.Errorf("unchanged comments pushed: %v", h.backend.Calls)
+	}
+	
+	content := h.read("issues/1.md")
+	content = strings.Replace(content, "my comment", "my edited comment", 1)
+	content += "A new comment\n"
+	h.write("issues/1.md", content)
+	
+	h.mustRun(0, "status")
+	if !strings.Contains(h.out.String(), "locally modified") {
+		t.Errorf("status = %q", h.out.String())
+	}
+	
+	h.mustRun(0, "push", "1")
+	
+	comments := h.backend.Comments(1)
+	if len(comments) != 3 {
+		t.Fatalf("comments = %+v", comments)
+	}
+	if comments[0].ID != mine || comments[0].Body != "my edited comment" {
+		t.Errorf("own comment = %+v", comments[0])
+	}
+	if comments[2].Body != "A new comment" || comments[2].Author != "me" {
+		t.Errorf("new comment = %+v", comments[2])
+	}
+	
+	// The posted comment moves into the read-only part of the file.
+	got := h.read("issues/1.md")
+	if !strings.HasSuffix(got, "-->\n") || strings.Count(got, "A new comment") != 1 {
+		t.Errorf("new comment section not cleared:\n%s", got)
+	}
+	h.mustRun(0, "push", "1")
+	if len(h.backend.Comments(1)) != 3 {
+		t.Errorf("comment posted twice")
+	}
+}
+
+func TestPushRejectsEditsToOthersComments(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.backend.AddRemoteComment(1, "alice", "her comment")
+	h.mustRun(0, "pull", "--comments", "1")
+	
+	h.write("issues/1.md", strings.Replace(h.read("issues/1.md"), "her comment", "rewritten", 1))
+	
+	h.mustRun(int(model.ExitIO), "push", "1")
+	if !strings.Contains(h.errOut.String(), "@alice") {
+		t.Errorf("stderr = %q", h.errOut.String())
+	}
+	if slices.Contains(h.backend.Calls, "EditIssue 1") {
+		t.Errorf("issue pushed despite the invalid edit")
+	}
+}
+
+func TestCommentEditedOnBothSidesConflicts(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	id := h.backend.AddRemoteComment(1, "me", "original")
+	h.mustRun(0, "pull", "--comments", "1")
+	
+	h.write("issues/1.md", strings.Replace(h.read("issues/1.md"), "original", "local edit", 1))
+	h.backend.EditRemoteComment(id, "remote edit")
+	
+	h.mustRun(int(model.ExitConflict), "push", "1")
+	if got := h.backend.Comments(1)[0].Body; got != "remote edit" {
+		t.Errorf("remote comment overwritten: %q", got)
+	}
+	
+	// The local text is kept; pushing again overwrites the remote edit.
+	h.mustRun(0, "push", "1")
+	if got := h.backend.Comments(1)[0].Body; got != "local edit" {
+		t.Errorf("remote comment = %q", got)
+	}
+}
+func TestMarkerLinesInBodiesRoundTrip(t *testing.T) {
+	h := newHarness(t)
+	quoted := "A ghi file ends like this:\n<!-- ghi:comments: only comments marked 'editable' can be changed -->\n<!-- ghi:new-comment: text below is posted as a new comment on push -->\nnot a comment\n"
+	h.backend.Add(model.IssueData{Title: "quoting", Body: quoted})
+	
+	// Without synced comments the whole file is the body.
+	h.mustRun(0, "pull", "1")
+	h.mustRun(0, "push", "1")
+	if got := h.backend.Issue(1).Body; got != quoted {
+		t.Errorf("body after push = %q", got)
+	}
+	if n := len(h.backend.Comments(1)); n != 0 {
+		t.Errorf("push posted %d comment(s) from the body", n)
+	}
+	
+	// With synced comments marker lines are escaped in the file.
+	h.backend.AddRemoteComment(1, "alice", "quote:\n<!-- /ghi:comment -->\nend")
+	h.mustRun(0, "pull", "--comments", "1")
+	h.mustRun(0, "push", "1")
+	if got := h.backend.Issue(1).Body; got != quoted {
+		t.Errorf("body after push with comments = %q", got)
+	}
+	if comments := h.backend.Comments(1); len(comments) != 1 || comments[0].Body != "quote:\n<!-- /ghi:comment -->\nend" {
+		t.Errorf("comments = %+v", comments)
+	}
+}
\ No newline at end of file
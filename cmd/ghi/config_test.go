@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func TestConfigFilenameTemplate(t *testing.T) {
+	h := newHarness(t)
+	h.write(".ghi.yaml", "issues_dir: tracker\nfilename: \"{number}-{slug}.md\"\n")
+	h.backend.Add(sampleIssue())
+	
+	if err := os.Mkdir("sub", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir("sub")
+	
+	// The file is found from a subdirectory and paths are relative to it.
+	h.mustRun(0, "pull", "1")
+	if got := h.out.String(); got != "Saved to ../tracker/1-fix-login.md\n" {
+		t.Errorf("stdout = %q", got)
+	}
+	
+	// A renamed file is still found by its number.
+	if err := os.Rename("../tracker/1-fix-login.md", "../tracker/1-my-notes.md"); err != nil {
+		t.Fatal(err)
+	}
+	h.write("../tracker/1-my-notes.md", strings.Replace(h.read("../tracker/1-my-notes.md"), "line one", "edited", 1))
+	h.mustRun(0, "push", "1")
+	if got := h.backend.Issue(1).Body; !strings.HasPrefix(got, "edited\n") {
+		t.Errorf("remote body = %q", got)
+	}
+	
+	h.backend.Update(1, func(issue *model.IssueData) { issue.Title = "Renamed" })
+	h.mustRun(0, "pull", "1")
+	if _, err := os.Stat("../tracker/1-renamed.md"); err == nil {
+		t.Errorf("pull created a second file for a renamed title")
+	}
+	h.mustRun(0, "status")
+	if got := h.out.String(); !strings.Contains(got, "1-my-notes.md") || !strings.Contains(got, "clean") {
+		t.Errorf("status = %q", got)
+	}
+}
+
+func TestConfigFields(t *testing.T) {
+	h := newHarness(t)
+	h.write(".ghi.yaml", "fields: [title, state]\n")
+	h.backend.Add(sampleIssue())
+	
+	h.mustRun(0, "pull", "1")
+	got := h.read("issues/1.md")
+	if strings.Contains(got, "labels:") || strings.Contains(got, "milestone:") || !strings.Contains(got, "state: open") {
+		t.Errorf("issues/1.md =\n%s", got)
+	}
+	
+	// Fields that are not synced are not pushed even when present.
+	h.write("issues/1.md", strings.Replace(got, "state: open", "state: open\nlabels: []", 1))
+	h.mustRun(0, "push", "1")
+	if labels := h.backend.Issue(1).Labels; len(labels) != 1 {
+		t.Errorf("labels = %v", labels)
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	h := newHarness(t)
+	h.write(".ghi.yaml", "repo: octo/other\nlist:\n  labels: [bug]\n  limit: 1\n")
+	other := h.remote("octo/other")
+	other.Add(model.IssueData{Title: "one", Labels: []model.Label{{Name: "bug"}}})
+	other.Add(model.IssueData{Title: "two"})
+	other.Add(model.IssueData{Title: "three", Labels: []model.Label{{Name: "bug"}}})
+	
+	h.mustRun(0, "list")
+	if got, want := h.out.String(), "#3 three\nhttps://github.com/octo/other/issues/3\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+	
+	h.mustRun(0, "list", "--", "--limit", "5")
+	if got := h.out.String(); !strings.Contains(got, "#1 one") || strings.Contains(got, "#2") {
+		t.Errorf("stdout = %q", got)
+	}
+	
+	// --repo overrides the configured repository.
+	h.backend.Add(model.IssueData{Title: "current", Labels: []model.Label{{Name: "bug"}}})
+	h.mustRun(0, "--repo", "owner/repo", "list")
+	if got := h.out.String(); !strings.Contains(got, "#1 current") {
+		t.Errorf("stdout = %q", got)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	h := newHarness(t)
+	h.write(".ghi.yaml", "filename: \"{slug}.md\"\n")
+	
+	h.mustRun(int(model.ExitIO), "status")
+	if !strings.Contains(h.errOut.String(), "{number}") {
+		t.Errorf("stderr = %q", h.errOut.String())
+	}
+}
\ No newline at end of file
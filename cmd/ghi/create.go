@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/config"
+	"github.com/nomnel/ghi/internal/filefmt"
+	"github.com/nomnel/ghi/internal/issuetemplate"
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/store"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const createUsage = "Usage: ghi create <issue-title> [--body-file FILE] [--label NAME]... [--assignee LOGIN]... [--milestone TITLE] [--template NAME] [--editor]"
+
+// runCreate builds the new issue from a template, the flags and optionally
+// $EDITOR, creates it and writes its local file.
+func (a *app) runCreate(cmd *cobra.Command, args []string) error {
+	var draft model.IssueDraft
+	var form *issuetemplate.Form
+	
+	if name, _ := cmd.Flags().GetString("template"); name != "" {
+		t, err := findTemplate(name)
+		if err != nil {
+			return err
+		}
+		draft = model.IssueDraft{Title: t.Title, Body: t.Body, Labels: t.Labels, Assignees: t.Assignees}
+		form = t.Form
+	}
+	
+	titleGiven := len(args) == 1
+	if titleGiven {
+		draft.Title = args[0]
+	}
+	
+	bodyFile, _ := cmd.Flags().GetString("body-file")
+	if bodyFile != "" {
+		body, err := a.readBodyFile(bodyFile)
+		if err != nil {
+			return err
+		}
+		draft.Body = body
+	}
+	
+	labels, _ := cmd.Flags().GetStringSlice("label")
+	assignees, _ := cmd.Flags().GetStringSlice("assignee")
+	draft.Labels = appendUnique(draft.Labels, labels...)
+	draft.Assignees = appendUnique(draft.Assignees, assignees...)
+	if milestone, _ := cmd.Flags().GetString("milestone"); milestone != "" {
+		draft.Milestone = milestone
+	}
+	
+	// Without a title on the command line the issue is written in the editor.
+	edit, _ := cmd.Flags().GetBool("editor")
+	edit = edit || !titleGiven
+	
+	// An issue form needs filling in; without the editor it becomes a draft
+	// for push --new.
+	if form != nil && !edit && bodyFile == "" {
+		if a.dryRun {
+			result := a.result("", "", "saved")
+			result.Draft = a.draftPath(draft.Title)
+			a.planned(result, []mutation{{Op: "write_file", Path: result.Draft}})
+			fmt.Fprintln(a.out, dryRunNote)
+			return nil
+		}
+		path, err := a.saveDraft(draft)
+		if err != nil {
+			return model.NewIOError("failed to save draft", err)
+		}
+		result := a.result("", "", "saved")
+		result.Draft = path
+		a.emit(result)
+		fmt.Fprintf(a.out, "Fill in %s and run 'ghi push --new' to create the issue.\n", path)
+		return nil
+	}
+	
+	if edit {
+		editor := editorCommand()
+		if editor == "" {
+			if titleGiven {
+				return model.NewEnvError("no editor configured: set $VISUAL or $EDITOR", nil)
+			}
+			return model.NewUsageError(createUsage)
+		}
+		edited, err := a.editDraft(editor, draft)
+		if err != nil {
+			return err
+		}
+		draft = edited
+	}
+	
+	// keepDraft saves what the user wrote in the editor when the issue
+	// cannot be created, so it can be fixed and created later.
+	keepDraft := func() {
+		if !edit {
+			return
+		}
+		if path, err := a.saveDraft(draft); err == nil {
+			fmt.Fprintf(a.errOut, "Draft saved to %s; run 'ghi push --new' to create it.\n", path)
+		}
+	}
+	
+	draft.Title = strings.TrimSpace(draft.Title)
+	if draft.Title == "" {
+		if edit {
+			return model.NewUsageError("Aborted: the issue has no title")
+		}
+		return model.NewUsageError(createUsage)
+	}
+	
+	filled := draft
+	var err error
+	if filled.Body, _, err = renderForm(draft.Body); err != nil {
+		keepDraft()
+		return err
+	}
+	
+	if a.dryRun {
+		a.planned(a.result("", "", "created"), []mutation{createMutation(filled)})
+		fmt.Fprintln(a.out, dryRunNote)
+		return nil
+	}
+	
+	issueNumber, err := a.client.CreateIssue(filled)
+	if err != nil {
+		keepDraft()
+		return backendError(err)
+	}
+	
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to create local directory", issueNumber), err)
+	}
+	
+	issue, err := a.client.ViewIssue(strconv.Itoa(issueNumber))
+	if err != nil {
+		return model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to fetch details", issueNumber), err)
+	}
+	
+	content, err := filefmt.EncodeMarkdown(a.cfg.FilterFrontmatter(issue.Frontmatter()), []byte(issue.Body))
+	if err != nil {
+		return model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to encode markdown", issueNumber), err)
+	}
+	
+	filePath := a.issuePath(strconv.Itoa(issueNumber), issue.Title)
+	
+	if err := filefmt.AtomicWriteFile(filePath, content, 0o644); err != nil {
+		return model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to write local file", issueNumber), err)
+	}
+	a.files.track(strconv.Itoa(issueNumber), filePath)
+	
+	if err := store.Open(a.dir).Save(store.NewBase(strconv.Itoa(issueNumber), issue.UpdatedAt, issue.Snapshot())); err != nil {
+		return model.NewIOError(fmt.Sprintf("Issue #%d created and saved locally but failed to save base snapshot", issueNumber), err)
+	}
+	
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return model.NewIOError(fmt.Sprintf("Issue #%d created and saved locally but failed to resolve absolute path", issueNumber), err)
+	}
+	
+	a.emit(a.result(strconv.Itoa(issueNumber), filePath, "created"))
+	fmt.Fprintln(a.out, absPath)
+	return nil
+}
+
+// findTemplate looks up an issue template of the current checkout.
+func findTemplate(name string) (*issuetemplate.Template, error) {
+	dir := issuetemplate.Dir(".")
+	if dir == "" {
+		return nil, model.NewUsageError(fmt.Sprintf("template %q not found: no .github/ISSUE_TEMPLATE directory", name))
+	}
+	
+	templates, err := issuetemplate.Load(dir)
+	if err != nil {
+		return nil, model.NewIOError("failed to read issue templates", err)
+	}
+	
+	if t := issuetemplate.Find(templates, name); t != nil {
+		return t, nil
+	}
+	
+	names := make([]string, 0, len(templates))
+	for _, t := range templates {
+		names = append(names, t.Name)
+	}
+	return nil, model.NewUsageError(fmt.Sprintf("template %q not found; available: %s", name, strings.Join(names, ", ")))
+}
+
+// renderForm turns a filled-in issue form draft into the body GitHub would
+// have posted, reporting missing required fields. Other bodies are returned
+// unchanged.
+func renderForm(body string) (string, bool, error) {
+	file, ok := issuetemplate.FormRef(body)
+	if !ok {
+		return body, false, nil
+	}
+	
+	var templates []*issuetemplate.Template
+	if dir := issuetemplate.Dir("."); dir != "" {
+		var err error
+		if templates, err = issuetemplate.Load(dir); err != nil {
+			return "", true, model.NewIOError("failed to read issue templates", err)
+		}
+	}
+	t := issuetemplate.FindFile(templates, file)
+	if t == nil || t.Form == nil {
+		return "", true, model.NewIOError(fmt.Sprintf("issue form %s not found in .github/ISSUE_TEMPLATE", file), nil)
+	}
+	
+	rendered, err := t.Form.Render(body)
+	if err != nil {
+		return "", true, model.NewUsageError(fmt.Sprintf("incomplete issue form:\n%v", err))
+	}
+	return rendered, true, nil
+}
+
+func (a *app) readBodyFile(path string) (string, error) {
+	var body []byte
+	var err error
+	if path == "-" {
+		body, err = io.ReadAll(a.in)
+	} else {
+		body, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", model.NewIOError("failed to read body file", err)
+	}
+	return string(body), nil
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" && !slices.Contains(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// editorCommand returns the user's editor from $VISUAL or $EDITOR.
+func editorCommand() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(name)); editor != "" {
+			return editor
+		}
+	}
+	return ""
+}
+
+// runEditor opens path in the editor on the terminal and waits for it to exit.
+func runEditor(editor string, path string) error {
+	fields := strings.Fields(editor)
+	editorCmd := exec.Command(fields[0], append(fields[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return model.NewEnvError(fmt.Sprintf("editor %q failed", editor), err)
+	}
+	return nil
+}
+
+// editorDraft is the file the editor opens. Unlike an issue file it always
+// shows the title key, so there is something to fill in.
+type editorDraft struct {
+	Title     string   `yaml:"title"`
+	Labels    []string `yaml:"labels,omitempty"`
+	Assignees []string `yaml:"assignees,omitempty"`
+	Milestone string   `yaml:"milestone,omitempty"`
+}
+
+// editDraft opens the draft in the editor and reads back what was saved.
+func (a *app) editDraft(editor string, draft model.IssueDraft) (model.IssueDraft, error) {
+	tmpDir := filepath.Join(a.dir, "tmp")
+	if err := os.MkdirAll(tmpDir, 0o755); err != nil {
+		return draft, model.NewIOError("failed to create temp directory", err)
+	}
+	
+	tmpFile, err := os.CreateTemp(tmpDir, "new-issue-*.md")
+	if err != nil {
+		return draft, model.NewIOError("failed to create temp file", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+	
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(editorDraft{Title: draft.Title, Labels: draft.Labels, Assignees: draft.Assignees, Milestone: draft.Milestone}); err != nil {
+		tmpFile.Close()
+		return draft, model.NewIOError("failed to encode draft", err)
+	}
+	encoder.Close()
+	buf.WriteString("---\n")
+	buf.WriteString(draft.Body)
+	
+	if _, err := tmpFile.Write(buf.Bytes()); err != nil {
+		tmpFile.Close()
+		return draft, model.NewIOError("failed to write temp file", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return draft, model.NewIOError("failed to close temp file", err)
+	}
+	
+	if err := runEditor(editor, tmpPath); err != nil {
+		return draft, err
+	}
+	
+	raw, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return draft, model.NewIOError("failed to read edited draft", err)
+	}
+	fm, body, err := filefmt.DecodeMarkdown(raw)
+	if err != nil {
+		return draft, model.NewIOError("failed to parse edited draft", err)
+	}
+	
+	return model.IssueDraft{
+		Title:     fm.Title,
+		Body:      string(body),
+		Labels:    fm.Labels,
+		Assignees: fm.Assignees,
+		Milestone: fm.Milestone,
+	}, nil
+}
+
+// saveDraft writes an issue that could not be created to the drafts
+// directory and returns its path.
+func (a *app) saveDraft(draft model.IssueDraft) (string, error) {
+	fm := model.Frontmatter{Title: draft.Title, Labels: draft.Labels, Assignees: draft.Assignees, Milestone: draft.Milestone}
+	content, err := filefmt.EncodeMarkdown(fm, []byte(draft.Body))
+	if err != nil {
+		return "", err
+	}
+	
+	if err := os.MkdirAll(filepath.Join(a.dir, draftsDir), 0o755); err != nil {
+		return "", err
+	}
+	
+	path := a.draftPath(draft.Title)
+	return path, filefmt.AtomicWriteFile(path, content, 0o644)
+}
+
+// draftPath returns a free path for a new draft, named after its title.
+func (a *app) draftPath(title string) string {
+	dir := filepath.Join(a.dir, draftsDir)
+	slug := config.Slug(title)
+	if slug == "" {
+		slug = "issue"
+	}
+	path := filepath.Join(dir, slug+".md")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.md", slug, i))
+	}
+}
\ No newline at end of file
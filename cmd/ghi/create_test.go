@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func labelNames(issue *model.IssueData) []string {
+	var names []string
+	for _, l := range issue.Labels {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// setEditor makes $EDITOR a script that replaces the edited file with content.
+func setEditor(t *testing.T, content string) {
+	t.Helper()
+	t.Setenv("VISUAL", "")
+	if content == "" {
+		t.Setenv("EDITOR", "")
+		return
+	}
+	
+	script := "editor.sh"
+	if err := os.WriteFile("content.md", []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncp content.md \"$1\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDITOR", "./"+script)
+}
+
+func TestCreateWithMetadata(t *testing.T) {
+	h := newHarness(t)
+	h.stdin = "Steps to reproduce\n"
+	
+	h.mustRun(0, "create", "Crash on start", "--body-file", "-", "-l", "bug", "--label", "urgent", "-a", "octocat", "-m", "v1.0")
+	
+	got := h.backend.Issue(1)
+	if got.Body != "Steps to reproduce\n" || !slices.Equal(labelNames(got), []string{"bug", "urgent"}) || got.Assignees[0].Login != "octocat" || got.Milestone.Title != "v1.0" {
+		t.Fatalf("issue #1 = %+v", got)
+	}
+	if got := h.read("issues/1.md"); !strings.Contains(got, "milestone: v1.0\n") || !strings.HasSuffix(got, "---\nSteps to reproduce\n") {
+		t.Errorf("issues/1.md =\n%s", got)
+	}
+	
+	h.mustRun(0, "status")
+	if !strings.Contains(h.out.String(), "clean") {
+		t.Errorf("created issue is not clean:\n%s", h.out.String())
+	}
+}
+
+func TestCreateFromTemplate(t *testing.T) {
+	h := newHarness(t)
+	os.Mkdir(".git", 0o755)
+	h.write(".github/ISSUE_TEMPLATE/bug_report.md", "---\nname: Bug report\nabout: Something is broken\ntitle: \"[Bug] \"\nlabels: bug, triage\nassignees: octocat\n---\n## Steps\n\n## Expected\n")
+	
+	h.mustRun(0, "create", "--template", "bug report", "[Bug] Crash", "-l", "urgent")
+	got := h.backend.Issue(1)
+	if got.Title != "[Bug] Crash" || got.Body != "## Steps\n\n## Expected\n" || !slices.Equal(labelNames(got), []string{"bug", "triage", "urgent"}) || got.Assignees[0].Login != "octocat" {
+		t.Fatalf("issue #1 = %+v", got)
+	}
+	
+	h.mustRun(int(model.ExitUsage), "create", "--template", "feature", "Idea")
+	if !strings.Contains(h.errOut.String(), "available: Bug report") {
+		t.Errorf("stderr = %q", h.errOut.String())
+	}
+}
+
+func TestCreateInEditor(t *testing.T) {
+	h := newHarness(t)
+	setEditor(t, "---\ntitle: From editor\nlabels: [bug]\n---\nWritten in the editor\n")
+	
+	h.mustRun(0, "create")
+	got := h.backend.Issue(1)
+	if got.Title != "From editor" || got.Body != "Written in the editor\n" || !slices.Equal(labelNames(got), []string{"bug"}) {
+		t.Fatalf("issue #1 = %+v", got)
+	}
+	
+	// A failed create keeps the edited draft for push --new.
+	h.backend.Fail["CreateIssue"] = errors.New("timeout")
+	h.mustRun(int(model.ExitEnv), "create", "--editor", "Ignored title")
+	if got := h.read("issues/new/from-editor.md"); !strings.Contains(got, "Written in the editor") {
+		t.Errorf("draft =\n%s", got)
+	}
+	delete(h.backend.Fail, "CreateIssue")
+	h.mustRun(0, "push", "--new")
+	if got := h.backend.Issue(2); got == nil || got.Title != "From editor" {
+		t.Errorf("issue #2 = %+v", got)
+	}
+	
+	setEditor(t, "---\ntitle: \"\"\n---\n")
+	h.mustRun(int(model.ExitUsage), "create")
+	
+	setEditor(t, "")
+	h.mustRun(int(model.ExitUsage), "create")
+	if h.backend.Issue(3) != nil {
+		t.Errorf("issue created without a title")
+	}
+}
+
+const featureForm = `name: Feature request
+title: "[Feature]: "
+labels: enhancement
+body:
+  - type: textarea
+    attributes:
+      label: Problem
+    validations:
+      required: true
+  - type: dropdown
+    attributes:
+      label: Area
+      options: [CLI, Docs]
+`
+
+func TestCreateFromIssueForm(t *testing.T) {
+	h := newHarness(t)
+	os.Mkdir(".git", 0o755)
+	h.write(".github/ISSUE_TEMPLATE/feature.yml", featureForm)
+	h.write(".github/ISSUE_TEMPLATE/config.yml", "blank_issues_enabled: false\n")
+	setEditor(t, "")
+	
+	// Without an editor the form becomes a draft to fill in.
+	h.mustRun(0, "create", "--template", "feature", "[Feature]: Dark mode")
+	draft := h.read("issues/new/feature-dark-mode.md")
+	if !strings.Contains(draft, "<!-- ghi:form template=feature.yml") || !strings.Contains(draft, "### Problem\n") {
+		t.Fatalf("draft =\n%s", draft)
+	}
+	
+	h.mustRun(int(model.ExitUsage), "push", "--new")
+	if !strings.Contains(h.out.String(), `"Problem": is required`) || h.backend.Issue(1) != nil {
+		t.Fatalf("incomplete form was posted:\n%s", h.out.String())
+	}
+	
+	h.write("issues/new/feature-dark-mode.md", strings.Replace(draft, "### Area\n", "It is too bright.\n\n### Area\n", 1))
+	h.mustRun(0, "push", "--new")
+	want := "### Problem\n\nIt is too bright.\n\n### Area\n\n_No response_"
+	if got := h.backend.Issue(1); got == nil || got.Body != want || labelNames(got)[0] != "enhancement" {
+		t.Fatalf("issue #1 = %+v", got)
+	}
+	if got := h.read("issues/1.md"); !strings.HasSuffix(got, "---\n"+want) {
+		t.Errorf("issues/1.md =\n%s", got)
+	}
+	
+	// In the editor the form is filled in before anything is created.
+	setEditor(t, "---\ntitle: Docs\n---\n<!-- ghi:form template=feature.yml -->\n### Problem\nTypos.\n### Area\nDocs\n")
+	h.mustRun(0, "create", "--template", "feature")
+	if got := h.backend.Issue(2); got == nil || got.Body != "### Problem\n\nTypos.\n\n### Area\n\nDocs" {
+		t.Errorf("issue #2 = %+v", got)
+	}
+}
\ No newline at end of file
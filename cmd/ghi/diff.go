@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/diff"
+	"github.com/nomnel/ghi/internal/filefmt"
+	"github.com/nomnel/ghi/internal/gh"
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// diffContext is the number of unchanged lines shown around body changes.
+const diffContext = 3
+
+// changeSet is what diff reports: the fields and body lines a push would
+// change on GitHub, from the remote version to the local file. Fields the
+// file leaves out are not pushed and so never differ.
+type changeSet struct {
+	Repo   string             `json:"repo,omitempty"`
+	Number int                `json:"number"`
+	Path   string             `json:"path"`
+	Action string             `json:"action"`
+	Fields []diff.FieldChange `json:"fields,omitempty"`
+	Body   []diff.Hunk        `json:"body,omitempty"`
+}
+
+func (a *app) newChangeSet(number string, path string, fields []diff.FieldChange, remoteBody, localBody string) *changeSet {
+	cs := &changeSet{Repo: a.repo, Path: path, Action: "unchanged", Fields: fields, Body: diff.Hunks(remoteBody, localBody, diffContext)}
+	cs.Number, _ = strconv.Atoi(number)
+	if len(cs.Fields) > 0 || len(cs.Body) > 0 {
+		cs.Action = "differs"
+	}
+	return cs
+}
+
+func (cs *changeSet) differs() bool {
+	return cs.Action == "differs"
+}
+
+// diffOptions selects how diff shows a change set: built in, as a word diff,
+// or with git diff and extra git arguments.
+type diffOptions struct {
+	words   bool
+	git     bool
+	gitArgs []string
+}
+
+// diffFlags reads the diff options of cmd. Extra git diff arguments imply
+// --git.
+func diffFlags(cmd *cobra.Command, gitArgs []string) diffOptions {
+	opts := diffOptions{gitArgs: gitArgs}
+	opts.words, _ = cmd.Flags().GetBool("word")
+	opts.git, _ = cmd.Flags().GetBool("git")
+	opts.git = opts.git || len(gitArgs) > 0
+	return opts
+}
+
+// printChanges writes a change set as field changes followed by a unified
+// or word diff of the body.
+func (a *app) printChanges(cs *changeSet, remoteName string, words bool) {
+	if !cs.differs() {
+		return
+	}
+	fmt.Fprintf(a.out, "--- %s\n+++ %s\n", remoteName, cs.Path)
+	for _, c := range cs.Fields {
+		fmt.Fprintln(a.out, c.String())
+	}
+	if words {
+		diff.WriteWords(a.out, cs.Body)
+	} else {
+		diff.WriteUnified(a.out, cs.Body)
+	}
+}
+
+// issueChanges compares the managed fields of an issue the way push applies
+// them: empty scalars and missing lists are left alone.
+func issueChanges(remote, local model.Frontmatter) []diff.FieldChange {
+	var changes []diff.FieldChange
+	add := func(c *diff.FieldChange) {
+		if c != nil {
+			changes = append(changes, *c)
+		}
+	}
+	
+	if local.Title != "" {
+		add(diff.Scalar("title", remote.Title, local.Title))
+	}
+	if local.Labels != nil {
+		add(diff.Set("labels", remote.Labels, local.Labels))
+	}
+	if local.Assignees != nil {
+		add(diff.Set("assignees", remote.Assignees, local.Assignees))
+	}
+	if local.Milestone != "" || local.NoMilestone {
+		add(diff.Scalar("milestone", remote.Milestone, local.Milestone))
+	}
+	if local.State != "" && !strings.EqualFold(local.State, remote.State) {
+		add(diff.Scalar("state", remote.State, strings.ToLower(local.State)))
+	}
+	return changes
+}
+
+// prChanges compares the fields of a pull request file like issueChanges.
+// The draft flag is always managed.
+func prChanges(remote, local model.PRFrontmatter) []diff.FieldChange {
+	var changes []diff.FieldChange
+	add := func(c *diff.FieldChange) {
+		if c != nil {
+			changes = append(changes, *c)
+		}
+	}
+	
+	if local.Title != "" {
+		add(diff.Scalar("title", remote.Title, local.Title))
+	}
+	if local.Base != "" {
+		add(diff.Scalar("base", remote.Base, local.Base))
+	}
+	if local.Head != "" {
+		add(diff.Scalar("head", remote.Head, local.Head))
+	}
+	add(diff.Scalar("draft", strconv.FormatBool(remote.Draft), strconv.FormatBool(local.Draft)))
+	if local.Labels != nil {
+		add(diff.Set("labels", remote.Labels, local.Labels))
+	}
+	if local.Reviewers != nil {
+		add(diff.Set("reviewers", remote.Reviewers, local.Reviewers))
+	}
+	return changes
+}
+
+// diffIssue prints the differences between the remote issue and its local
+// file and returns them. With the git option git diff prints them instead,
+// and its exit code decides whether they differ.
+func (a *app) diffIssue(issueNumber string, localPath string, opts diffOptions) (*changeSet, error) {
+	localContent, err := os.ReadFile(localPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, model.NewIOError(fmt.Sprintf("%s not found. Run 'ghi pull %s' first.", localPath, issueNumber), nil)
+		}
+		return nil, model.NewIOError("failed to check local file", err)
+	}
+	
+	issue, err := a.client.ViewIssue(issueNumber)
+	if err != nil {
+		return nil, backendError(err)
+	}
+	
+	base, err := store.Open(a.dir).Load(issueNumber)
+	if err != nil {
+		return nil, model.NewIOError("failed to load base snapshot", err)
+	}
+	
+	// Compare comments too when the file syncs them.
+	var section *model.CommentSection
+	if base.SyncsComments() {
+		comments, err := a.client.ListComments(issueNumber)
+		if err != nil {
+			return nil, backendError(err)
+		}
+		section = &model.CommentSection{Comments: comments}
+	}
+	remoteBody := string(filefmt.AppendComments([]byte(issue.Body), section))
+	
+	if opts.git {
+		// The remote side is laid over the local file so keys ghi does
+		// not manage do not show up as differences.
+		content, err := filefmt.UpdateMarkdown(localContent, a.cfg.FilterFrontmatter(issue.Frontmatter()), a.cfg.Fields, []byte(remoteBody))
+		if err != nil {
+			return nil, model.NewIOError("failed to encode remote markdown", err)
+		}
+		return a.gitDiff(filepath.Join(a.dir, "tmp"), issueNumber, content, localPath, opts.gitArgs)
+	}
+	
+	local, err := a.readLocal(localPath, base)
+	if err != nil {
+		return nil, model.NewIOError(fmt.Sprintf("Invalid frontmatter in %s", localPath), err)
+	}
+	localBody := string(filefmt.AppendComments([]byte(local.Body), local.Comments))
+	
+	cs := a.newChangeSet(issueNumber, localPath, issueChanges(a.cfg.FilterFrontmatter(issue.Frontmatter()), local.Frontmatter), remoteBody, localBody)
+	a.printChanges(cs, "remote #"+issueNumber, opts.words)
+	return cs, nil
+}
+
+// gitDiff writes the remote version of a file to a temporary file in tmpDir
+// and prints its differences to the local file with git diff. The change set
+// it returns only tells whether there are any.
+func (a *app) gitDiff(tmpDir string, number string, remote []byte, localPath string, extraArgs []string) (*changeSet, error) {
+	if err := os.MkdirAll(tmpDir, 0o755); err != nil {
+		return nil, model.NewIOError("failed to create temp directory", err)
+	}
+	
+	tmpFile, err := os.CreateTemp(tmpDir, "remote-"+number+"-*.md")
+	if err != nil {
+		return nil, model.NewIOError("failed to create temp file", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+	
+	if _, err := tmpFile.Write(remote); err != nil {
+		tmpFile.Close()
+		return nil, model.NewIOError("failed to write temp file", err)
+	}
+	
+	if err := tmpFile.Close(); err != nil {
+		return nil, model.NewIOError("failed to close temp file", err)
+	}
+	
+	exitCode, err := gh.RunGitDiff(a.out, a.errOut, tmpPath, localPath, extraArgs)
+	if err != nil {
+		return nil, model.NewEnvError("", err)
+	}
+	
+	if exitCode > 1 {
+		return nil, model.NewEnvError(fmt.Sprintf("git diff failed with exit code %d", exitCode), nil)
+	}
+	
+	cs := &changeSet{Repo: a.repo, Path: localPath, Action: "unchanged"}
+	cs.Number, _ = strconv.Atoi(number)
+	if exitCode == 1 {
+		cs.Action = "differs"
+	}
+	return cs, nil
+}
\ No newline at end of file
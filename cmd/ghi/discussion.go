@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/filefmt"
+	"github.com/nomnel/ghi/internal/merge"
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// Discussions live in discussions/<n>.md next to the issues directory, with
+// their base snapshots in discussions/.ghi/. The title, category and body
+// sync like an issue file; the comment thread after the body is read-only.
+
+func (a *app) discussionDir() string {
+	return a.siblingDir("discussions")
+}
+
+func (a *app) discussionPath(number string) string {
+	return filepath.Join(a.discussionDir(), number+".md")
+}
+
+func (a *app) runDiscussionPull(cmd *cobra.Command, args []string) error {
+	if err := numberArgs(args, "Usage: ghi discussion pull <discussion-number>..."); err != nil {
+		return err
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	
+	if err := os.MkdirAll(a.discussionDir(), 0o755); err != nil {
+		return model.NewIOError("failed to create discussions directory", err)
+	}
+	if len(args) > 1 {
+		a.listResults()
+	}
+	
+	// Conflicts are reported after the remaining discussions are pulled.
+	var conflict error
+	for _, number := range args {
+		d, err := a.client.ViewDiscussion(number)
+		if err != nil {
+			return backendError(err)
+		}
+		
+		outcome, conflicts, err := a.pullDiscussion(d, force)
+		if err != nil {
+			return err
+		}
+		
+		filePath := a.discussionPath(number)
+		result := a.result(number, filePath, outcome.String())
+		result.Conflicts = conflicts
+		a.emit(result)
+		
+		switch outcome {
+		case pullConflict:
+			if conflict == nil {
+				conflict = model.NewConflictError(fmt.Sprintf("Conflicts in %s (%s). Resolve them and run 'ghi discussion push %s'.", filePath, strings.Join(conflicts, ", "), number))
+			}
+			fmt.Fprintf(a.out, "Conflicts in %s (%s)\n", filePath, strings.Join(conflicts, ", "))
+		case pullMerged:
+			fmt.Fprintf(a.out, "Merged remote changes into %s\n", filePath)
+		case pullKept:
+			fmt.Fprintf(a.out, "Kept local changes in %s (remote unchanged)\n", filePath)
+		default:
+			fmt.Fprintf(a.out, "Saved to %s\n", filePath)
+		}
+	}
+	return conflict
+}
+
+// pullDiscussion writes a fetched discussion to its file and records it as
+// the new base, merging local edits made since the last pull unless force is
+// set. The thread is always replaced with the remote one.
+func (a *app) pullDiscussion(d *model.DiscussionData, force bool) (pullOutcome, []string, error) {
+	number := strconv.Itoa(d.Number)
+	filePath := a.discussionPath(number)
+	
+	bases := store.Open(a.discussionDir())
+	base, err := bases.LoadDiscussion(number)
+	if err != nil {
+		return 0, nil, model.NewIOError("failed to load base snapshot", err)
+	}
+	
+	existing, err := os.ReadFile(filePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, nil, model.NewIOError(fmt.Sprintf("failed to read %s", filePath), err)
+	}
+	
+	snap := d.Snapshot()
+	outcome := pullUpdated
+	var conflicts []string
+	
+	switch {
+	case existing == nil:
+		outcome = pullCreated
+	case !force && base != nil:
+		local, err := readLocalDiscussion(filePath)
+		if err != nil {
+			return 0, nil, model.NewIOError(fmt.Sprintf("failed to read %s (use --force to overwrite)", filePath), err)
+		}
+		if merge.DiscussionModified(base.DiscussionSnapshot, *local) {
+			if d.UpdatedAt == base.UpdatedAt {
+				snap, outcome = *local, pullKept
+			} else {
+				res := merge.Discussion(base.DiscussionSnapshot, *local, snap)
+				snap, conflicts, outcome = res.Snapshot, res.Conflicts, pullMerged
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		outcome = pullConflict
+	}
+	
+	content, err := encodeLocalDiscussion(snap, d.Comments, existing)
+	if err != nil {
+		return 0, nil, err
+	}
+	if bytes.Equal(existing, content) {
+		if outcome == pullUpdated {
+			outcome = pullUnchanged
+		}
+	} else if err := filefmt.AtomicWriteFile(filePath, content, 0o644); err != nil {
+		return 0, nil, model.NewIOError("failed to write file", err)
+	}
+	
+	if err := bases.SaveDiscussion(&store.DiscussionBase{Number: number, UpdatedAt: d.UpdatedAt, DiscussionSnapshot: d.Snapshot()}); err != nil {
+		return 0, nil, model.NewIOError("failed to save base snapshot", err)
+	}
+	
+	return outcome, conflicts, nil
+}
+
+func (a *app) runDiscussionPush(cmd *cobra.Command, args []string) error {
+	if err := numberArgs(args, "Usage: ghi discussion push <discussion-number>..."); err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		a.listResults()
+	}
+	
+	for _, number := range args {
+		filePath := a.discussionPath(number)
+		outcome, conflicts, err := a.pushDiscussion(number)
+		if err != nil {
+			return err
+		}
+		
+		result := a.result(number, filePath, outcome.String())
+		result.Conflicts = conflicts
+		a.emit(result)
+		
+		if outcome == pushConflict {
+			return model.NewConflictError(fmt.Sprintf("Conflicts in %s (%s). Resolve them and run 'ghi discussion push %s'.", filePath, strings.Join(conflicts, ", "), number))
+		}
+		if outcome == pushMerged {
+			fmt.Fprintf(a.out, "Merged remote changes into %s\n", filePath)
+		}
+		fmt.Fprintf(a.out, "Updated discussion #%s from %s\n", number, filePath)
+	}
+	return nil
+}
+
+// pushDiscussion updates the title, body and category of the remote
+// discussion from its file, merging remote changes made since the last pull
+// first, as pushPR does. Edits to the thread are ignored.
+func (a *app) pushDiscussion(number string) (pushOutcome, []string, error) {
+	filePath := a.discussionPath(number)
+	
+	local, err := readLocalDiscussion(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil, model.NewIOError(fmt.Sprintf("%s not found. Run 'ghi discussion pull %s' first", filePath, number), nil)
+		}
+		if errors.Is(err, model.ErrMalformedFrontmatter) {
+			return 0, nil, model.NewIOError(fmt.Sprintf("Invalid frontmatter in %s", filePath), err)
+		}
+		return 0, nil, model.NewIOError("failed to read file", err)
+	}
+	
+	if merge.HasConflictMarkers(local.Body) {
+		return 0, nil, model.NewConflictError(fmt.Sprintf("%s has unresolved conflict markers. Resolve them and run 'ghi discussion push %s' again.", filePath, number))
+	}
+	
+	remote, err := a.client.ViewDiscussion(number)
+	if err != nil {
+		return 0, nil, backendError(err)
+	}
+	
+	bases := store.Open(a.discussionDir())
+	base, err := bases.LoadDiscussion(number)
+	if err != nil {
+		return 0, nil, model.NewIOError("failed to load base snapshot", err)
+	}
+	
+	// The answer is marked on a comment, which a file cannot do.
+	answered := remote.Answered
+	if base != nil {
+		answered = base.Frontmatter.Answered
+	}
+	if local.Frontmatter.Answered != answered {
+		return 0, nil, model.NewIOError(fmt.Sprintf("Invalid frontmatter in %s: answered is read-only; mark or unmark an answer on GitHub", filePath), nil)
+	}
+	
+	outcome := pushUpdated
+	if base != nil && remote.UpdatedAt != base.UpdatedAt {
+		res := merge.Discussion(base.DiscussionSnapshot, *local, remote.Snapshot())
+		if len(res.Conflicts) > 0 || !reflect.DeepEqual(res.Snapshot, *local) {
+			if err := writeLocalDiscussion(filePath, res.Snapshot, remote.Comments); err != nil {
+				return 0, nil, err
+			}
+		}
+		if len(res.Conflicts) > 0 {
+			if err := bases.SaveDiscussion(&store.DiscussionBase{Number: number, UpdatedAt: remote.UpdatedAt, DiscussionSnapshot: remote.Snapshot()}); err != nil {
+				return 0, nil, model.NewIOError("failed to save base snapshot", err)
+			}
+			return pushConflict, res.Conflicts, nil
+		}
+		if !reflect.DeepEqual(res.Snapshot, *local) {
+			outcome = pushMerged
+		}
+		local = &res.Snapshot
+	}
+	
+	if err := a.client.EditDiscussion(number, model.NewDiscussionEdit(local.Frontmatter, local.Body, remote)); err != nil {
+		return 0, nil, backendError(err)
+	}
+	
+	// As for issues, the remote updatedAt is only trusted if nobody else
+	// edited the discussion in the meantime.
+	newBase := &store.DiscussionBase{Number: number, DiscussionSnapshot: *local}
+	if after, err := a.client.ViewDiscussion(number); err == nil && !merge.DiscussionModified(after.Snapshot(), *local) {
+		newBase = &store.DiscussionBase{Number: number, UpdatedAt: after.UpdatedAt, DiscussionSnapshot: after.Snapshot()}
+	}
+	if err := bases.SaveDiscussion(newBase); err != nil {
+		return 0, nil, model.NewIOError("failed to save base snapshot", err)
+	}
+	
+	return outcome, nil, nil
+}
+
+func (a *app) runDiscussionList(cmd *cobra.Command, args []string) error {
+	category, _ := cmd.Flags().GetString("category")
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit <= 0 {
+		return model.NewUsageError("Usage: ghi discussion list [--category NAME] [--limit N]")
+	}
+	
+	discussions, err := a.client.ListDiscussions(category, limit)
+	if err != nil {
+		return backendError(err)
+	}
+	
+	a.listResults()
+	for _, d := range discussions {
+		a.emit(d)
+	}
+	
+	for i, d := range discussions {
+		answered := ""
+		if d.Answered {
+			answered = " (answered)"
+		}
+		fmt.Fprintf(a.out, "#%d [%s] %s%s\n", d.Number, d.Category, d.Title, answered)
+		fmt.Fprintln(a.out, d.URL)
+		if i < len(discussions)-1 {
+			fmt.Fprintln(a.out)
+		}
+	}
+	
+	return nil
+}
+
+const discussionCreateUsage = "Usage: ghi discussion create <title> --category NAME [--body-file FILE]"
+
+// runDiscussionCreate starts a discussion and writes its file.
+func (a *app) runDiscussionCreate(cmd *cobra.Command, args []string) error {
+	draft := model.DiscussionDraft{Title: strings.TrimSpace(args[0])}
+	draft.Category, _ = cmd.Flags().GetString("category")
+	if draft.Title == "" || draft.Category == "" {
+		return model.NewUsageError(discussionCreateUsage)
+	}
+	
+	if bodyFile, _ := cmd.Flags().GetString("body-file"); bodyFile != "" {
+		body, err := a.readBodyFile(bodyFile)
+		if err != nil {
+			return err
+		}
+		draft.Body = body
+	}
+	
+	number, err := a.client.CreateDiscussion(draft)
+	if err != nil {
+		return backendError(err)
+	}
+	
+	if err := os.MkdirAll(a.discussionDir(), 0o755); err != nil {
+		return model.NewIOError(fmt.Sprintf("Discussion #%d created on GitHub but failed to create local directory", number), err)
+	}
+	d, err := a.client.ViewDiscussion(strconv.Itoa(number))
+	if err != nil {
+		return model.NewIOError(fmt.Sprintf("Discussion #%d created on GitHub but failed to fetch details", number), err)
+	}
+	if _, _, err := a.pullDiscussion(d, true); err != nil {
+		return err
+	}
+	
+	filePath := a.discussionPath(strconv.Itoa(number))
+	a.emit(a.result(strconv.Itoa(number), filePath, "created"))
+	fmt.Fprintf(a.out, "Created discussion #%d in %s\n", number, filePath)
+	return nil
+}
+
+func readLocalDiscussion(path string) (*model.DiscussionSnapshot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	
+	fm, body, err := filefmt.DecodeDiscussionMarkdown(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &model.DiscussionSnapshot{Frontmatter: *fm, Body: string(body)}, nil
+}
+
+// encodeLocalDiscussion encodes a discussion file on top of its previous
+// content, so keys ghi does not manage survive.
+func encodeLocalDiscussion(snap model.DiscussionSnapshot, thread []model.DiscussionComment, prev []byte) ([]byte, error) {
+	content, err := filefmt.UpdateDiscussionMarkdown(prev, snap.Frontmatter, []byte(snap.Body), thread)
+	if err != nil {
+		return nil, model.NewIOError("failed to encode markdown", err)
+	}
+	return content, nil
+}
+
+func writeLocalDiscussion(path string, snap model.DiscussionSnapshot, thread []model.DiscussionComment) error {
+	prev, _ := os.ReadFile(path)
+	content, err := encodeLocalDiscussion(snap, thread, prev)
+	if err != nil {
+		return err
+	}
+	if err := filefmt.AtomicWriteFile(path, content, 0o644); err != nil {
+		return model.NewIOError("failed to write file", err)
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func sampleDiscussion() model.DiscussionData {
+	return model.DiscussionData{
+		Number:   12,
+		Title:    "Plugin API",
+		Body:     "Should plugins get hooks?\n",
+		Category: "Ideas",
+		Answered: true,
+		Comments: []model.DiscussionComment{
+			{ID: 101, Author: "alice", CreatedAt: "2024-05-01T10:00:00Z", Body: "Yes, before and after push.", IsAnswer: true, Replies: []model.DiscussionComment{
+				{ID: 102, Author: "bob", CreatedAt: "2024-05-01T11:00:00Z", Body: "+1"},
+			}},
+		},
+	}
+}
+
+const sampleDiscussionFile = `---
+title: Plugin API
+category: Ideas
+answered: true
+---
+Should plugins get hooks?
+
+<!-- ghi:thread: read-only, pull replaces it and push ignores it -->
+
+<!-- ghi:comment id=101 author=@alice created=2024-05-01T10:00:00Z answer -->
+Yes, before and after push.
+<!-- /ghi:comment -->
+
+<!-- ghi:reply to=101 id=102 author=@bob created=2024-05-01T11:00:00Z -->
++1
+<!-- /ghi:reply -->
+`
+
+func TestDiscussionPullPush(t *testing.T) {
+	h := newHarness(t)
+	h.backend.AddDiscussion(sampleDiscussion())
+	
+	h.mustRun(0, "discussion", "pull", "12")
+	if got := h.read("discussions/12.md"); got != sampleDiscussionFile {
+		t.Errorf("discussions/12.md =\n%s\nwant\n%s", got, sampleDiscussionFile)
+	}
+	
+	// Thread edits are not pushed.
+	edited := strings.NewReplacer(
+		"title: Plugin API", "title: Plugin hooks",
+		"category: Ideas", "category: general",
+		"Should plugins get hooks?", "Should plugins get hooks?\n\nSee #3.",
+		"+1", "+2",
+	).Replace(sampleDiscussionFile)
+	h.write("discussions/12.md", edited)
+	
+	h.mustRun(0, "discussion", "push", "12")
+	if h.out.String() != "Updated discussion #12 from discussions/12.md\n" {
+		t.Errorf("stdout = %q", h.out.String())
+	}
+	d := h.backend.Discussion(12)
+	if d.Title != "Plugin hooks" || d.Category != "General" || d.Body != "Should plugins get hooks?\n\nSee #3.\n" || d.Comments[0].Replies[0].Body != "+1" {
+		t.Errorf("remote = %+v", d)
+	}
+	
+	// A new reply comes in with the next pull; the thread is replaced.
+	h.backend.UpdateDiscussion(12, func(d *model.DiscussionData) {
+		d.Comments = append(d.Comments, model.DiscussionComment{ID: 103, Author: "carol", CreatedAt: "2024-05-02T09:00:00Z", Body: "Shipped."})
+	})
+	h.mustRun(0, "discussion", "pull", "12")
+	got := h.read("discussions/12.md")
+	if !strings.Contains(got, "category: General\n") || !strings.Contains(got, "\n+1\n") || !strings.Contains(got, "author=@carol") {
+		t.Errorf("discussions/12.md after pull =\n%s", got)
+	}
+	
+	h.write("discussions/12.md", strings.Replace(got, "answered: true\n", "", 1))
+	h.mustRun(int(model.ExitIO), "discussion", "push", "12")
+}
+
+func TestDiscussionPullMergesRemoteChanges(t *testing.T) {
+	h := newHarness(t)
+	h.backend.AddDiscussion(sampleDiscussion())
+	h.mustRun(0, "discussion", "pull", "12")
+	
+	h.write("discussions/12.md", strings.Replace(sampleDiscussionFile, "Should plugins get hooks?\n", "Should plugins get hooks?\n\nDraft API attached.\n", 1))
+	h.backend.UpdateDiscussion(12, func(d *model.DiscussionData) { d.Title = "Plugin hooks" })
+	
+	h.mustRun(0, "discussion", "pull", "12")
+	if h.out.String() != "Merged remote changes into discussions/12.md\n" {
+		t.Errorf("stdout = %q", h.out.String())
+	}
+	got := h.read("discussions/12.md")
+	if !strings.HasPrefix(got, "---\ntitle: Plugin hooks\n") || !strings.Contains(got, "Draft API attached.") {
+		t.Errorf("discussions/12.md =\n%s", got)
+	}
+}
+
+func TestDiscussionListCreate(t *testing.T) {
+	h := newHarness(t)
+	h.backend.AddDiscussion(sampleDiscussion())
+	h.write("body.md", "What should go into v2?\n")
+	
+	h.mustRun(0, "discussion", "create", "Roadmap", "--category", "q&a", "--body-file", "body.md")
+	if h.out.String() != "Created discussion #13 in discussions/13.md\n" {
+		t.Errorf("stdout = %q", h.out.String())
+	}
+	want := "---\ntitle: Roadmap\ncategory: Q&A\n---\nWhat should go into v2?\n\n<!-- ghi:thread: read-only, pull replaces it and push ignores it -->\n"
+	if got := h.read("discussions/13.md"); got != want {
+		t.Errorf("discussions/13.md =\n%s\nwant\n%s", got, want)
+	}
+	
+	h.mustRun(int(model.ExitUsage), "discussion", "create", "Roadmap")
+	h.mustRun(int(model.ExitUsage), "discussion", "create", "Roadmap", "--category", "Polls")
+	
+	h.mustRun(0, "discussion", "list", "--category", "Ideas", "-o", "json")
+	var items []model.DiscussionListItem
+	if err := json.Unmarshal(h.out.Bytes(), &items); err != nil || len(items) != 1 || items[0].Number != 12 || !items[0].Answered {
+		t.Errorf("list = %s (%v)", h.out.String(), err)
+	}
+	
+	h.mustRun(0, "discussion", "list")
+	if !strings.HasPrefix(h.out.String(), "#13 [Q&A] Roadmap\n") || !strings.Contains(h.out.String(), "#12 [Ideas] Plugin API (answered)\n") {
+		t.Errorf("list =\n%s", h.out.String())
+	}
+	if !slices.Contains(h.backend.Calls, "CreateDiscussion") {
+		t.Errorf("calls = %v", h.backend.Calls)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nomnel/ghi/internal/filefmt"
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/store"
+)
+
+// Drafts are issue files written offline in issues/new/ and created by
+// 'ghi push --new'. Before an issue is created a journal records the attempt,
+// so if ghi dies after GitHub created the issue but before the draft was
+// renamed, the next run finds that issue instead of creating a second one.
+
+// draftsDir is the directory for drafts, relative to the issues directory.
+const draftsDir = "new"
+
+// journalClockSkew widens the search for an interrupted create, since the
+// journal is stamped with the local clock and GitHub uses its own.
+const journalClockSkew = 10 * time.Minute
+
+func (a *app) runPushNew() error {
+	dir := filepath.Join(a.dir, draftsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return model.NewIOError("failed to read drafts directory", err)
+	}
+	
+	var drafts []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
+			drafts = append(drafts, entry.Name())
+		}
+	}
+	sort.Strings(drafts)
+	
+	bases := store.Open(a.dir)
+	if !a.dryRun {
+		if err := a.cleanJournals(bases); err != nil {
+			return err
+		}
+	}
+	
+	a.listResults()
+	if len(drafts) == 0 {
+		fmt.Fprintf(a.out, "No drafts in %s\n", dir)
+		return nil
+	}
+	
+	var failures []string
+	code := model.ExitIO
+	for _, name := range drafts {
+		draftPath := filepath.Join(dir, name)
+		var n int
+		var target string
+		var mutations []mutation
+		var err error
+		if a.dryRun {
+			mutations, n, err = a.planDraft(bases, name)
+		} else {
+			n, target, err = a.pushDraft(bases, name)
+		}
+		if err != nil {
+			if len(failures) == 0 {
+				var exitErr *model.ExitError
+				if errors.As(err, &exitErr) {
+					code = exitErr.Code
+				}
+			}
+			result := a.failedResult("", "", err)
+			result.Draft = draftPath
+			a.emit(result)
+			fmt.Fprintf(a.out, "%s failed: %v\n", draftPath, err)
+			failures = append(failures, fmt.Sprintf("  %s: %v", draftPath, err))
+			continue
+		}
+		result := a.result(strconv.Itoa(n), target, "created")
+		result.Draft = draftPath
+		if a.dryRun {
+			a.planned(result, mutations)
+			if n != 0 {
+				fmt.Fprintf(a.out, "%s would become issue #%d, created by an earlier attempt\n", draftPath, n)
+			}
+			continue
+		}
+		a.emit(result)
+		fmt.Fprintf(a.out, "Created issue #%d from %s -> %s\n", n, draftPath, target)
+	}
+	
+	if a.dryRun {
+		fmt.Fprintf(a.out, "Would create %d of %d draft(s)\n%s\n", len(drafts)-len(failures), len(drafts), dryRunNote)
+	} else {
+		fmt.Fprintf(a.out, "Created %d of %d draft(s)\n", len(drafts)-len(failures), len(drafts))
+	}
+	
+	if len(failures) > 0 {
+		return &model.ExitError{
+			Code:    code,
+			Message: fmt.Sprintf("failed to create %d draft(s):\n%s", len(failures), strings.Join(failures, "\n")),
+		}
+	}
+	return nil
+}
+
+// readDraft reads a draft as the issue to create. A filled-in issue form is
+// rendered, and isForm set.
+func (a *app) readDraft(draftPath string) (draft model.IssueDraft, raw []byte, isForm bool, err error) {
+	raw, err = os.ReadFile(draftPath)
+	if err != nil {
+		return draft, nil, false, model.NewIOError("failed to read draft", err)
+	}
+	fm, body, err := filefmt.DecodeMarkdown(raw)
+	if err != nil {
+		return draft, nil, false, model.NewIOError("failed to parse draft", err)
+	}
+	*fm = a.cfg.FilterFrontmatter(*fm)
+	
+	draft = model.IssueDraft{
+		Title:     strings.TrimSpace(fm.Title),
+		Body:      string(body),
+		Labels:    fm.Labels,
+		Assignees: fm.Assignees,
+		Milestone: fm.Milestone,
+	}
+	if draft.Title == "" {
+		return draft, nil, false, model.NewIOError("draft has no title", nil)
+	}
+	
+	if draft.Body, isForm, err = renderForm(draft.Body); err != nil {
+		return draft, nil, false, err
+	}
+	return draft, raw, isForm, nil
+}
+
+// pushDraft creates the issue for one draft and moves the draft to its issue
+// file. It returns the issue number and the new path.
+func (a *app) pushDraft(bases *store.Store, name string) (int, string, error) {
+	draftPath := filepath.Join(a.dir, draftsDir, name)
+	key := strings.TrimSuffix(name, ".md")
+	
+	draft, raw, isForm, err := a.readDraft(draftPath)
+	if err != nil {
+		return 0, "", err
+	}
+	
+	journal, err := bases.LoadJournal(key)
+	if err != nil {
+		return 0, "", model.NewIOError("failed to load draft journal", err)
+	}
+	
+	if journal != nil && journal.Number == 0 {
+		// An earlier run got as far as asking GitHub to create the issue.
+		if journal.Number, err = a.findCreatedIssue(journal); err != nil {
+			return 0, "", err
+		}
+		if journal.Number != 0 {
+			if err := bases.SaveJournal(journal); err != nil {
+				return 0, "", model.NewIOError(fmt.Sprintf("Found issue #%d from an earlier attempt but failed to record it", journal.Number), err)
+			}
+		}
+	}
+	
+	if journal == nil || journal.Number == 0 {
+		journal = &store.Journal{
+			Draft:     key,
+			Title:     draft.Title,
+			BodyHash:  store.HashBody(draft.Body),
+			StartedAt: time.Now().UTC(),
+		}
+		if err := bases.SaveJournal(journal); err != nil {
+			return 0, "", model.NewIOError("failed to save draft journal", err)
+		}
+		
+		// A failed create keeps the journal: the request may have reached
+		// GitHub even though no answer came back.
+		n, err := a.client.CreateIssue(draft)
+		if err != nil {
+			return 0, "", backendError(err)
+		}
+		journal.Number = n
+		if err := bases.SaveJournal(journal); err != nil {
+			return 0, "", model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to record it", n), err)
+		}
+	}
+	
+	n := journal.Number
+	issueNumber := strconv.Itoa(n)
+	
+	issue, err := a.client.ViewIssue(issueNumber)
+	if err != nil {
+		return 0, "", model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to fetch details", n), err)
+	}
+	
+	target := a.issuePath(issueNumber, issue.Title)
+	if _, err := os.Stat(target); err == nil {
+		return 0, "", model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but %s already exists", n, target), nil)
+	}
+	
+	if err := bases.Save(store.NewBase(issueNumber, issue.UpdatedAt, issue.Snapshot())); err != nil {
+		return 0, "", model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to save base snapshot", n), err)
+	}
+	
+	// A form draft is replaced by the body GitHub rendered from it.
+	if isForm {
+		content, err := filefmt.UpdateMarkdown(raw, a.cfg.FilterFrontmatter(issue.Frontmatter()), a.cfg.Fields, []byte(issue.Body))
+		if err != nil {
+			return 0, "", model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to encode markdown", n), err)
+		}
+		if err := filefmt.AtomicWriteFile(draftPath, content, 0o644); err != nil {
+			return 0, "", model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to write draft", n), err)
+		}
+	}
+	if err := os.Rename(draftPath, target); err != nil {
+		return 0, "", model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to rename draft", n), err)
+	}
+	a.files.track(issueNumber, target)
+	if err := bases.DeleteJournal(key); err != nil {
+		return 0, "", model.NewIOError("failed to delete draft journal", err)
+	}
+	
+	return n, target, nil
+}
+
+// planDraft is push --new --dry-run for one draft. It returns the create
+// that would be sent, or the number of the issue an interrupted earlier
+// attempt created, which is used instead.
+func (a *app) planDraft(bases *store.Store, name string) ([]mutation, int, error) {
+	draft, _, _, err := a.readDraft(filepath.Join(a.dir, draftsDir, name))
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	journal, err := bases.LoadJournal(strings.TrimSuffix(name, ".md"))
+	if err != nil {
+		return nil, 0, model.NewIOError("failed to load draft journal", err)
+	}
+	n := 0
+	if journal != nil {
+		n = journal.Number
+		if n == 0 {
+			if n, err = a.findCreatedIssue(journal); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	if n != 0 {
+		return nil, n, nil
+	}
+	return []mutation{createMutation(draft)}, 0, nil
+}
+
+// findCreatedIssue looks for the issue an interrupted create left behind: one
+// the user opened since the journal was written with the journal's title and
+// body. It returns 0 if there is none.
+func (a *app) findCreatedIssue(journal *store.Journal) (int, error) {
+	issues, err := a.client.ListCreatedIssues(journal.StartedAt.Add(-journalClockSkew))
+	if err != nil {
+		return 0, backendError(err)
+	}
+	
+	found := 0
+	for _, issue := range issues {
+		if issue.Title == journal.Title && store.HashBody(issue.Body) == journal.BodyHash {
+			if found == 0 || issue.Number < found {
+				found = issue.Number
+			}
+		}
+	}
+	return found, nil
+}
+
+// cleanJournals removes journals whose draft is gone, which happens when ghi
+// stops between renaming a draft and deleting its journal.
+func (a *app) cleanJournals(bases *store.Store) error {
+	keys, err := bases.Journals()
+	if err != nil {
+		return model.NewIOError("failed to read draft journals", err)
+	}
+	
+	for _, key := range keys {
+		if _, err := os.Stat(filepath.Join(a.dir, draftsDir, key+".md")); !os.IsNotExist(err) {
+			continue
+		}
+		if err := bases.DeleteJournal(key); err != nil {
+			return model.NewIOError("failed to delete draft journal", err)
+		}
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/store"
+)
+
+const draftFile = `---
+title: Add dark mode
+labels:
+  - enhancement
+assignees:
+  - octocat
+---
+Please.
+`
+
+func TestPushNew(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(model.IssueData{Title: "existing"})
+	h.write("issues/new/dark-mode.md", draftFile)
+	h.write("issues/new/typo.md", "---\ntitle: Fix typo\n---\n")
+	
+	h.mustRun(0, "push", "--new")
+	
+	got := h.backend.Issue(2)
+	if got == nil || got.Title != "Add dark mode" || got.Body != "Please.\n" || got.Labels[0].Name != "enhancement" || got.Assignees[0].Login != "octocat" {
+		t.Fatalf("issue #2 = %+v", got)
+	}
+	if got := h.backend.Issue(3); got == nil || got.Title != "Fix typo" {
+		t.Fatalf("issue #3 = %+v", got)
+	}
+	if got := h.read("issues/2.md"); got != draftFile {
+		t.Errorf("issues/2.md =\n%s", got)
+	}
+	if _, err := os.Stat("issues/new/dark-mode.md"); !os.IsNotExist(err) {
+		t.Errorf("draft still exists")
+	}
+	if !strings.Contains(h.out.String(), "Created issue #2 from issues/new/dark-mode.md -> issues/2.md") {
+		t.Errorf("stdout = %q", h.out.String())
+	}
+	if journals, _ := store.Open("issues").Journals(); len(journals) > 0 {
+		t.Errorf("journals left behind: %v", journals)
+	}
+	
+	h.mustRun(0, "status")
+	if strings.Contains(h.out.String(), "modified") {
+		t.Errorf("created issues are not clean:\n%s", h.out.String())
+	}
+	
+	h.mustRun(0, "push", "--new")
+	if !strings.Contains(h.out.String(), "No drafts") {
+		t.Errorf("stdout = %q", h.out.String())
+	}
+}
+
+func TestPushNewSkipsInvalidDrafts(t *testing.T) {
+	h := newHarness(t)
+	h.write("issues/new/a.md", "---\nlabels: [bug]\n---\nno title\n")
+	h.write("issues/new/b.md", "---\ntitle: Fine\n---\n")
+	
+	h.mustRun(int(model.ExitIO), "push", "--new")
+	if got := h.backend.Issue(1); got == nil || got.Title != "Fine" {
+		t.Errorf("issue #1 = %+v", got)
+	}
+	if _, err := os.Stat("issues/new/a.md"); err != nil {
+		t.Errorf("invalid draft was moved: %v", err)
+	}
+	
+	h.mustRun(int(model.ExitUsage), "push", "--new", "1")
+}
+
+// A crash after GitHub created the issue but before ghi recorded its number
+// leaves a journal without a number. The retry must adopt that issue.
+func TestPushNewRecoversInterruptedCreate(t *testing.T) {
+	h := newHarness(t)
+	h.write("issues/new/dark-mode.md", draftFile)
+	
+	journal := &store.Journal{Draft: "dark-mode", Title: "Add dark mode", BodyHash: store.HashBody("Please.\n"), StartedAt: time.Now().UTC()}
+	if err := store.Open("issues").SaveJournal(journal); err != nil {
+		t.Fatal(err)
+	}
+	h.backend.CreateIssue(model.IssueDraft{Title: "Add dark mode", Body: "Please.\n"})
+	
+	h.mustRun(0, "push", "--new")
+	if h.backend.Issue(2) != nil {
+		t.Fatalf("draft was created twice")
+	}
+	if got := h.read("issues/1.md"); got != draftFile {
+		t.Errorf("issues/1.md =\n%s", got)
+	}
+}
+
+func TestPushNewRetriesAfterFailure(t *testing.T) {
+	h := newHarness(t)
+	h.write("issues/new/dark-mode.md", draftFile)
+	
+	// The issue is created but fetching it fails, so the draft stays.
+	h.backend.Fail["ViewIssue"] = errors.New("connection reset")
+	h.mustRun(int(model.ExitIO), "push", "--new")
+	if _, err := os.Stat("issues/new/dark-mode.md"); err != nil {
+		t.Fatalf("draft was moved: %v", err)
+	}
+	
+	delete(h.backend.Fail, "ViewIssue")
+	h.backend.Calls = nil
+	h.mustRun(0, "push", "--new")
+	if slices.Contains(h.backend.Calls, "CreateIssue") || h.backend.Issue(2) != nil {
+		t.Fatalf("draft was created twice: %v", h.backend.Calls)
+	}
+	
+	// A create that never reached GitHub is simply retried.
+	h.write("issues/new/typo.md", "---\ntitle: Fix typo\n---\n")
+	h.backend.Fail["CreateIssue"] = errors.New("timeout")
+	h.mustRun(int(model.ExitEnv), "push", "--new")
+	delete(h.backend.Fail, "CreateIssue")
+	h.mustRun(0, "push", "--new")
+	if got := h.backend.Issue(2); got == nil || got.Title != "Fix typo" {
+		t.Errorf("issue #2 = %+v", got)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/diff"
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// dryRunNote ends the text output of a --dry-run command.
+const dryRunNote = "Dry run: nothing was sent or written."
+
+// mutation is one change a command makes on GitHub or on disk. With
+// --dry-run commands report their mutations instead of carrying them out.
+// Op is edit_issue, close_issue, reopen_issue, create_issue, add_comment,
+// edit_comment, write_file, move_file (from Path to To) or delete_file.
+type mutation struct {
+	Op      string             `json:"op"`
+	Issue   int                `json:"issue,omitempty"`
+	Comment int64              `json:"comment,omitempty"`
+	Path    string             `json:"path,omitempty"`
+	To      string             `json:"to,omitempty"`
+	Fields  []diff.FieldChange `json:"fields,omitempty"`
+	Body    []diff.Hunk        `json:"body,omitempty"`
+	Text    string             `json:"text,omitempty"`
+}
+
+// String returns the one-line summary of the mutation.
+func (m mutation) String() string {
+	switch m.Op {
+	case "edit_issue":
+		return fmt.Sprintf("edit issue #%d", m.Issue)
+	case "close_issue":
+		return fmt.Sprintf("close issue #%d", m.Issue)
+	case "reopen_issue":
+		return fmt.Sprintf("reopen issue #%d", m.Issue)
+	case "create_issue":
+		return "create issue"
+	case "add_comment":
+		return fmt.Sprintf("add comment to issue #%d", m.Issue)
+	case "edit_comment":
+		return fmt.Sprintf("edit comment %d on issue #%d", m.Comment, m.Issue)
+	case "write_file":
+		return "write " + m.Path
+	case "move_file":
+		return "move " + m.Path + " -> " + m.To
+	case "delete_file":
+		return "delete " + m.Path
+	}
+	return m.Op
+}
+
+// write prints the summary of the mutation followed by its field changes,
+// body diff and text, indented.
+func (m mutation) write(w io.Writer) {
+	fmt.Fprintln(w, m.String())
+	
+	var details bytes.Buffer
+	for _, c := range m.Fields {
+		fmt.Fprintln(&details, c.String())
+	}
+	diff.WriteUnified(&details, m.Body)
+	if m.Text != "" {
+		details.WriteString(strings.TrimSuffix(m.Text, "\n") + "\n")
+	}
+	
+	for _, line := range strings.SplitAfter(details.String(), "\n") {
+		if line != "" {
+			io.WriteString(w, "    "+line)
+		}
+	}
+}
+
+// planned records and prints the mutations a dry run found for one issue or
+// draft.
+func (a *app) planned(result issueResult, mutations []mutation) {
+	result.DryRun = true
+	result.Mutations = mutations
+	a.emit(result)
+	for _, m := range mutations {
+		m.write(a.out)
+	}
+}
+
+// editMutation describes the edit push sends for an issue. Title and body are
+// always sent; only the changes are shown.
+func editMutation(edit model.IssueEdit, remote *model.IssueData) mutation {
+	m := mutation{Op: "edit_issue", Issue: remote.Number, Body: diff.Hunks(remote.Body, edit.Body, diffContext)}
+	add := func(c *diff.FieldChange) {
+		if c != nil {
+			m.Fields = append(m.Fields, *c)
+		}
+	}
+	
+	current := remote.Frontmatter()
+	if strings.TrimSpace(edit.Title) != "" {
+		add(diff.Scalar("title", current.Title, edit.Title))
+	}
+	if edit.AddLabels != nil || edit.RemoveLabels != nil {
+		add(&diff.FieldChange{Field: "labels", Added: edit.AddLabels, Removed: edit.RemoveLabels})
+	}
+	if edit.AddAssignees != nil || edit.RemoveAssignees != nil {
+		add(&diff.FieldChange{Field: "assignees", Added: edit.AddAssignees, Removed: edit.RemoveAssignees})
+	}
+	if edit.Milestone != "" || edit.RemoveMilestone {
+		add(diff.Scalar("milestone", current.Milestone, edit.Milestone))
+	}
+	if edit.State != "" {
+		add(diff.Scalar("state", current.State, edit.State))
+	}
+	
+	if m.Fields == nil && m.Body == nil {
+		m.Text = "(no changes)"
+	}
+	return m
+}
+
+// createMutation describes the issue create sends for a draft.
+func createMutation(draft model.IssueDraft) mutation {
+	m := mutation{Op: "create_issue", Fields: []diff.FieldChange{{Field: "title", New: draft.Title}}, Text: draft.Body}
+	if draft.Labels != nil {
+		m.Fields = append(m.Fields, diff.FieldChange{Field: "labels", Added: draft.Labels})
+	}
+	if draft.Assignees != nil {
+		m.Fields = append(m.Fields, diff.FieldChange{Field: "assignees", Added: draft.Assignees})
+	}
+	if draft.Milestone != "" {
+		m.Fields = append(m.Fields, diff.FieldChange{Field: "milestone", New: draft.Milestone})
+	}
+	return m
+}
+
+// planPushOne is push --dry-run for one issue.
+func (a *app) planPushOne(issueNumber string) error {
+	p, err := a.preparePush(issueNumber)
+	if err != nil {
+		return err
+	}
+	
+	result := a.result(issueNumber, p.filePath, p.outcome().String())
+	result.Conflicts = p.conflicts
+	a.planned(result, p.mutations())
+	fmt.Fprintln(a.out, dryRunNote)
+	
+	if len(p.conflicts) > 0 {
+		return model.NewConflictError(fmt.Sprintf("Pushing would conflict in %s (%s).", p.filePath, strings.Join(p.conflicts, ", ")))
+	}
+	return nil
+}
+
+// planPush is push --dry-run for one issue of a bulk push.
+func (a *app) planPush(r *pushResult) error {
+	p, err := a.preparePush(r.number)
+	if err != nil {
+		return err
+	}
+	r.outcome, r.conflicts, r.mutations = p.outcome(), p.conflicts, p.mutations()
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// mutatingCalls returns the backend calls that change something on GitHub.
+func mutatingCalls(calls []string) []string {
+	var mutating []string
+	for _, c := range calls {
+		if !strings.HasPrefix(c, "View") && !strings.HasPrefix(c, "List") && c != "Repository" {
+			mutating = append(mutating, c)
+		}
+	}
+	return mutating
+}
+
+func TestPushDryRun(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.mustRun(0, "pull", "1")
+	
+	edited := strings.NewReplacer("title: Fix login", "title: Fix sign-in", "  - bug", "  - ui", "state: open", "state: closed", "line two", "line 2").Replace(sampleFile)
+	h.write("issues/1.md", edited)
+	base := h.read("issues/.ghi/base/1.json")
+	h.backend.Calls = nil
+	
+	h.mustRun(0, "push", "--dry-run", "1")
+	
+	want := `edit issue #1
+    title: "Fix login" -> "Fix sign-in"
+    labels: added ui; removed bug
+    state: "open" -> "closed"
+    @@ -1,3 +1,3 @@
+     line one
+    -line two
+    +line 2
+     line three
+Dry run: nothing was sent or written.
+`
+	if got := h.out.String(); got != want {
+		t.Errorf("push --dry-run printed\n%s\nwant\n%s", got, want)
+	}
+	if calls := mutatingCalls(h.backend.Calls); calls != nil {
+		t.Errorf("dry run called %v", calls)
+	}
+	if h.read("issues/1.md") != edited || h.read("issues/.ghi/base/1.json") != base {
+		t.Error("dry run wrote files")
+	}
+	if got := h.backend.Issue(1); got.Title != "Fix login" || got.State == "CLOSED" {
+		t.Errorf("dry run changed issue #1: %+v", got)
+	}
+	
+	h.mustRun(0, "push", "--dry-run", "1", "--output", "json")
+	var record issueResult
+	if err := json.Unmarshal(h.out.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if !record.DryRun || record.Action != "updated" || len(record.Mutations) != 1 || record.Mutations[0].Op != "edit_issue" || len(record.Mutations[0].Fields) != 3 {
+		t.Errorf("push --dry-run JSON = %+v", record)
+	}
+	
+	h.mustRun(0, "push", "--all", "--dry-run")
+	if got := h.out.String(); !strings.Contains(got, "edit issue #1\n") || !strings.HasSuffix(got, "#1 would be updated\nWould push 1 of 1 issue(s)\n"+dryRunNote+"\n") {
+		t.Errorf("push --all --dry-run printed\n%s", got)
+	}
+	
+	// A remote edit that conflicts is reported; the file is not rewritten.
+	h.backend.Update(1, func(issue *model.IssueData) { issue.Title = "Fix logout" })
+	h.mustRun(4, "push", "--dry-run", "1")
+	if got := h.out.String(); !strings.HasPrefix(got, "write issues/1.md\n") || strings.Contains(got, "edit issue") {
+		t.Errorf("conflicting push --dry-run printed\n%s", got)
+	}
+	if h.read("issues/1.md") != edited {
+		t.Error("conflicting dry run wrote conflict markers")
+	}
+	if calls := mutatingCalls(h.backend.Calls); calls != nil {
+		t.Errorf("dry runs called %v", calls)
+	}
+}
+
+func TestPushCommentsDryRun(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	mine := h.backend.AddRemoteComment(1, "me", "my comment")
+	h.mustRun(0, "pull", "--comments", "1")
+	h.write("issues/1.md", strings.Replace(h.read("issues/1.md"), "my comment", "my edited comment", 1)+"A new comment\n")
+	h.backend.Calls = nil
+	
+	h.mustRun(0, "push", "--dry-run", "1")
+	
+	got := h.out.String()
+	for _, want := range []string{"edit issue #1\n    (no changes)\n", fmt.Sprintf("edit comment %d on issue #1\n    @@ -1,1 +1,1 @@\n    -my comment\n", mine), "add comment to issue #1\n    A new comment\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("push --dry-run printed\n%s\nwant it to contain\n%s", got, want)
+		}
+	}
+	if calls := mutatingCalls(h.backend.Calls); calls != nil {
+		t.Errorf("dry run called %v", calls)
+	}
+	if len(h.backend.Comments(1)) != 1 {
+		t.Errorf("dry run posted a comment")
+	}
+}
+
+func TestDryRunSendsAndWritesNothing(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.backend.Add(model.IssueData{Title: "closed"})
+	h.mustRun(0, "pull", "1", "2")
+	h.backend.Update(2, func(issue *model.IssueData) { issue.State = "CLOSED" })
+	h.write("issues/tmp/remote-1.md", "")
+	h.backend.Calls = nil
+	
+	h.mustRun(0, "close", "--dry-run", "1")
+	if got := h.out.String(); got != "close issue #1\n"+dryRunNote+"\n" {
+		t.Errorf("close --dry-run printed %q", got)
+	}
+	h.mustRun(0, "reopen", "--dry-run", "2")
+	if got := h.out.String(); got != "reopen issue #2\n"+dryRunNote+"\n" {
+		t.Errorf("reopen --dry-run printed %q", got)
+	}
+	
+	h.mustRun(0, "create", "--dry-run", "New feature", "-l", "ui", "-m", "v1.0")
+	want := "create issue\n" +
+		"    title: \"\" -> \"New feature\"\n" +
+		"    labels: added ui\n" +
+		"    milestone: \"\" -> \"v1.0\"\n" +
+		dryRunNote + "\n"
+	if got := h.out.String(); got != want {
+		t.Errorf("create --dry-run printed\n%s\nwant\n%s", got, want)
+	}
+	
+	h.write("issues/new/idea.md", "---\ntitle: Idea\n---\nbody\n")
+	h.mustRun(0, "push", "--new", "--dry-run")
+	if got := h.out.String(); !strings.HasPrefix(got, "create issue\n    title: \"\" -> \"Idea\"\n    body\n") || !strings.HasSuffix(got, "Would create 1 of 1 draft(s)\n"+dryRunNote+"\n") {
+		t.Errorf("push --new --dry-run printed\n%s", got)
+	}
+	
+	h.mustRun(0, "prune", "--dry-run")
+	if got := h.out.String(); got != "delete issues/2.md\ndelete issues/tmp\nWould delete 1 closed issue file(s)\n"+dryRunNote+"\n" {
+		t.Errorf("prune --dry-run printed %q", got)
+	}
+	
+	if calls := mutatingCalls(h.backend.Calls); calls != nil {
+		t.Errorf("dry runs called %v", calls)
+	}
+	if h.backend.Issue(1).State == "CLOSED" || h.backend.Issue(3) != nil {
+		t.Error("dry runs changed the remote issues")
+	}
+	for _, path := range []string{"issues/2.md", "issues/tmp/remote-1.md", "issues/new/idea.md"} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("dry run deleted %s", path)
+		}
+	}
+}
\ No newline at end of file
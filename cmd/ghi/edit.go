@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/merge"
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// runEdit is pull, edit, diff and push in one step. The remote issue is only
+// changed after the diff has been confirmed.
+func (a *app) runEdit(cmd *cobra.Command, args []string) error {
+	issueNumber := args[0]
+	if !model.IsNumeric(issueNumber) {
+		return model.NewUsageError("Usage: ghi edit <issue-number> [--yes]")
+	}
+	
+	editor := editorCommand()
+	if editor == "" {
+		return model.NewEnvError("no editor configured: set $VISUAL or $EDITOR", nil)
+	}
+	
+	// The diff and the prompts stay on the terminal when standard output
+	// carries structured results.
+	if a.results.structured() {
+		a.out = a.errOut
+	}
+	
+	path, err := a.refreshForEdit(issueNumber)
+	if err != nil {
+		return err
+	}
+	
+	before, err := os.ReadFile(path)
+	if err != nil {
+		return model.NewIOError("failed to read file", err)
+	}
+	
+	in := bufio.NewReader(a.in)
+	for {
+		if err := runEditor(editor, path); err != nil {
+			return err
+		}
+		
+		after, err := os.ReadFile(path)
+		if err != nil {
+			return model.NewIOError("failed to read file", err)
+		}
+		if bytes.Equal(before, after) {
+			a.emit(a.result(issueNumber, path, "unchanged"))
+			fmt.Fprintf(a.out, "No changes to %s; nothing pushed.\n", path)
+			return nil
+		}
+		
+		// Keep the edit when it does not parse: offer the editor again, and
+		// otherwise leave the file for a later push.
+		base, err := store.Open(a.dir).Load(issueNumber)
+		if err != nil {
+			return model.NewIOError("failed to load base snapshot", err)
+		}
+		if _, err := a.readLocal(path, base); err != nil {
+			fmt.Fprintf(a.errOut, "%s: %v\n", path, err)
+			if a.confirm(in, "Re-open the editor? [Y/n] ", true) {
+				continue
+			}
+			return model.NewIOError(fmt.Sprintf("%s was not pushed; fix it and run 'ghi push %s'", path, issueNumber), err)
+		}
+		break
+	}
+	
+	changes, err := a.diffIssue(issueNumber, path, diffOptions{})
+	if err != nil {
+		return err
+	}
+	if !changes.differs() {
+		a.emit(a.result(issueNumber, path, "unchanged"))
+		fmt.Fprintf(a.out, "No differences: %s matches remote; nothing pushed.\n", path)
+		return nil
+	}
+	
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes && !a.confirm(in, fmt.Sprintf("Push these changes to #%s? [y/N] ", issueNumber), false) {
+		a.emit(a.result(issueNumber, path, "skipped"))
+		fmt.Fprintf(a.out, "Not pushed; your changes are kept in %s.\n", path)
+		return nil
+	}
+	
+	return a.runPushOne(issueNumber)
+}
+
+// refreshForEdit pulls the issue unless its file has local changes, which
+// are edited as they are. It returns the file's path.
+func (a *app) refreshForEdit(issueNumber string) (string, error) {
+	path := a.issuePath(issueNumber, "")
+	
+	base, err := store.Open(a.dir).Load(issueNumber)
+	if err != nil {
+		return "", model.NewIOError("failed to load base snapshot", err)
+	}
+	if local, err := a.readLocal(path, base); err == nil {
+		if base == nil || merge.Modified(base.Snapshot, local.Snapshot) || merge.CommentsModified(base.Comments, local.Comments) {
+			return path, nil
+		}
+	} else if !os.IsNotExist(err) {
+		// An unreadable file is opened as it is so it can be fixed.
+		return path, nil
+	}
+	
+	issue, err := a.client.ViewIssue(issueNumber)
+	if err != nil {
+		return "", backendError(err)
+	}
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return "", model.NewIOError("failed to create issues directory", err)
+	}
+	if _, _, err := a.pullIssue(issue, false, false); err != nil {
+		return "", err
+	}
+	
+	return a.issuePath(issueNumber, issue.Title), nil
+}
+
+// confirm asks a yes/no question on the terminal. An empty answer or the
+// end of input picks def.
+func (a *app) confirm(in *bufio.Reader, prompt string, def bool) bool {
+	fmt.Fprint(a.out, prompt)
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Fprintln(a.out)
+		return def
+	}
+	
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
\ No newline at end of file
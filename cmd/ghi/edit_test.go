@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func TestEditPushesAfterConfirmation(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	edited := strings.Replace(sampleFile, "line two", "line 2", 1)
+	setEditor(t, edited)
+	
+	// Declining leaves the edit locally and the remote untouched.
+	h.stdin = "n\n"
+	h.mustRun(0, "edit", "1")
+	if h.read("issues/1.md") != edited || h.backend.Issue(1).Body != sampleIssue().Body {
+		t.Fatalf("declined edit was pushed")
+	}
+	if out := h.out.String(); !strings.Contains(out, "-line two\n+line 2") || !strings.Contains(out, "Not pushed") {
+		t.Errorf("stdout = %q", out)
+	}
+	
+	// The locally modified file is edited as it is, not pulled again.
+	h.stdin = "y\n"
+	setEditor(t, strings.Replace(edited, "line three", "line 3", 1))
+	h.mustRun(0, "edit", "1")
+	if got := h.backend.Issue(1).Body; got != "line one\nline 2\nline 3\n" {
+		t.Errorf("remote body = %q", got)
+	}
+}
+
+func TestEditPullsCleanFile(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.mustRun(0, "pull", "1")
+	h.backend.Update(1, func(issue *model.IssueData) { issue.Title = "Fix login page" })
+	
+	// Saving without changes pushes nothing.
+	setEditor(t, "")
+	t.Setenv("EDITOR", "true")
+	h.backend.Calls = nil
+	h.mustRun(0, "edit", "1")
+	if slices.Contains(h.backend.Calls, "EditIssue 1") {
+		t.Errorf("unchanged file was pushed")
+	}
+	if got := h.read("issues/1.md"); !strings.Contains(got, "title: Fix login page\n") {
+		t.Errorf("clean file was not refreshed:\n%s", got)
+	}
+}
+
+func TestEditReopensEditorOnParseError(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	fixed := strings.Replace(sampleFile, "title: Fix login", "title: Fixed login", 1)
+	
+	// The first run of the editor breaks the frontmatter, the second fixes it.
+	os.WriteFile("bad.md", []byte("---\ntitle: [unclosed\n---\nbody\n"), 0o644)
+	os.WriteFile("good.md", []byte(fixed), 0o644)
+	os.WriteFile("editor.sh", []byte("#!/bin/sh\nif [ -f once ]; then cp good.md \"$1\"; else touch once; cp bad.md \"$1\"; fi\n"), 0o755)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "./editor.sh")
+	
+	h.stdin = "\ny\n"
+	h.mustRun(0, "edit", "1")
+	if got := h.backend.Issue(1).Title; got != "Fixed login" {
+		t.Errorf("remote title = %q", got)
+	}
+	
+	// Declining to re-open keeps the broken file and pushes nothing.
+	os.Remove("once")
+	h.stdin = "n\n"
+	h.mustRun(int(model.ExitIO), "edit", "1")
+	if got := h.read("issues/1.md"); !strings.Contains(got, "[unclosed") {
+		t.Errorf("broken edit was lost:\n%s", got)
+	}
+	if got := h.backend.Issue(1).Title; got != "Fixed login" {
+		t.Errorf("remote title = %q", got)
+	}
+}
\ No newline at end of file
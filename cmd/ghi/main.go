@@ -2,404 +2,533 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
-	"strings"
+	"strconv"
 
-	"github.com/nomnel/ghi/internal/filefmt"
-	"github.com/nomnel/ghi/internal/gh"
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/config"
 	"github.com/nomnel/ghi/internal/model"
 	"github.com/spf13/cobra"
 )
 
-const issuesDir = "issues"
-
-var rootCmd = &cobra.Command{
-	Use:   "ghi",
-	Short: "GitHub Issue Sync Tool",
-	Long:  "A simple CLI to pull and push GitHub Issues using the authenticated gh CLI, storing each issue as a markdown file with YAML frontmatter.",
-}
-
-var pullCmd = &cobra.Command{
-	Use:   "pull <issue-number>",
-	Short: "Fetch issue from current repo and write to issues/{n}.md",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runPull,
-}
-
-var pushCmd = &cobra.Command{
-	Use:   "push <issue-number>",
-	Short: "Update issue in current repo from issues/{n}.md",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runPush,
-}
-
-var diffCmd = &cobra.Command{
-	Use:   "diff <issue-number> [--] [EXTRA_GIT_DIFF_ARGS...]",
-	Short: "Compare local issues/{n}.md with remote GitHub Issue",
-	Args:  cobra.MinimumNArgs(1),
-	RunE:  runDiff,
-}
-
-var createCmd = &cobra.Command{
-	Use:   "create <issue-title>",
-	Short: "Create a new GitHub Issue and pull it locally",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runCreate,
-}
-
-var closeCmd = &cobra.Command{
-	Use:   "close <issue-number>",
-	Short: "Close the specified GitHub issue",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runClose,
+// app carries what commands need from the outside world. main wires it to
+// the real backend and terminal; tests use a fake backend and buffers.
+type app struct {
+	client backend.Backend
+	in     io.Reader
+	out    io.Writer
+	errOut io.Writer
+	
+	// connect creates the backend for an "owner/name" repository, or for the
+	// current repository when given "".
+	connect func(repo string) (backend.Backend, error)
+	
+	cfg *config.Config
+	
+	// repo is the repository given by --repo, empty for the current one.
+	repo string
+	// dir holds the repository's issue files: the issues directory, or
+	// <issues>/<owner>/<repo>/ in workspace mode. It is empty while a
+	// workspace-wide command has not picked a repository yet.
+	dir string
+	
+	// results collects what the command did for --output and --format.
+	results *results
+	
+	// verbose reports retries and the remaining rate limit on stderr.
+	verbose bool
+	// dryRun reports what push, close, reopen, create and prune would send
+	// and write instead of doing it.
+	dryRun bool
+	// connected lists the backends the command used.
+	connected []backend.Backend
+	// files indexes the issue files in dir.
+	files *fileIndex
 }
 
-var reopenCmd = &cobra.Command{
-	Use:   "reopen <issue-number>",
-	Short: "Reopen the specified GitHub issue",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runReopen,
-}
-
-var listCmd = &cobra.Command{
-	Use:   "list [-- GH_ISSUE_LIST_OPTIONS...]",
-	Short: "List open GitHub Issues with custom formatting",
-	Args:  cobra.ArbitraryArgs,
-	DisableFlagParsing: true,
-	RunE:  runList,
-}
-
-var pruneCmd = &cobra.Command{
-	Use:   "prune",
-	Short: "Delete local files for closed GitHub issues",
-	Args:  cobra.NoArgs,
-	RunE:  runPrune,
-}
-
-func init() {
-	rootCmd.AddCommand(pullCmd)
-	rootCmd.AddCommand(pushCmd)
-	rootCmd.AddCommand(diffCmd)
-	rootCmd.AddCommand(createCmd)
-	rootCmd.AddCommand(closeCmd)
-	rootCmd.AddCommand(reopenCmd)
-	rootCmd.AddCommand(listCmd)
-	rootCmd.AddCommand(pruneCmd)
-}
-
-func main() {
-	if err := rootCmd.Execute(); err != nil {
-		var exitErr *model.ExitError
-		if e, ok := err.(*model.ExitError); ok {
-			exitErr = e
-		} else {
-			exitErr = &model.ExitError{Code: model.ExitIO, Message: err.Error()}
-		}
-		
-		fmt.Fprintln(os.Stderr, exitErr.Error())
-		os.Exit(int(exitErr.Code))
+func newRootCmd(a *app) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:               "ghi",
+		Short:             "GitHub Issue Sync Tool",
+		Long:              "A simple CLI to pull and push GitHub Issues using the authenticated gh CLI, storing each issue as a markdown file with YAML frontmatter.",
+		PersistentPreRunE: a.setup,
 	}
-}
-
-func runPull(cmd *cobra.Command, args []string) error {
-	issueNumber := args[0]
 	
-	if !model.IsNumeric(issueNumber) {
-		return model.NewUsageError("Usage: ghi pull <issue-number>")
+	pullCmd := &cobra.Command{
+		Use:   "pull [<issue-number>|<from>-<to>...] [--all] [-- LIST_OPTIONS...]",
+		Short: "Fetch issues from current repo and write to issues/{n}.md",
+		Long:  "Fetch issues from current repo and write to issues/{n}.md.\n\nLIST_OPTIONS selects the issues to pull with these gh issue list flags: " + backend.ListFlags + ".",
+		Args:  cobra.ArbitraryArgs,
+		RunE:  a.runPull,
 	}
 	
-	if err := os.MkdirAll(issuesDir, 0o755); err != nil {
-		return model.NewIOError("failed to create issues directory", err)
+	pushCmd := &cobra.Command{
+		Use:   "push [<issue-number>|<from>-<to>...] [--all] [--new] [--dry-run]",
+		Short: "Update issues in current repo from issues/{n}.md",
+		Args:  cobra.ArbitraryArgs,
+		RunE:  a.runPush,
 	}
 	
-	issue, err := gh.ViewIssue(issueNumber)
-	if err != nil {
-		return model.NewEnvError("", err)
+	diffCmd := &cobra.Command{
+		Use:   "diff <issue-number> [--json] [--word] [--git [--] [EXTRA_GIT_DIFF_ARGS...]]",
+		Short: "Show what pushing issues/{n}.md would change on GitHub",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  a.runDiff,
 	}
 	
-	fm := model.Frontmatter{Title: issue.Title}
+	editCmd := &cobra.Command{
+		Use:   "edit <issue-number>",
+		Short: "Open issues/{n}.md in $EDITOR, show the diff and push after confirmation",
+		Args:  cobra.ExactArgs(1),
+		RunE:  a.runEdit,
+	}
 	
-	content, err := filefmt.EncodeMarkdown(fm, []byte(issue.Body))
-	if err != nil {
-		return model.NewIOError("failed to encode markdown", err)
+	createCmd := &cobra.Command{
+		Use:   "create [<issue-title>]",
+		Short: "Create a new GitHub Issue and pull it locally",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  a.runCreate,
 	}
 	
-	filePath := filepath.Join(issuesDir, fmt.Sprintf("%s.md", issueNumber))
+	closeCmd := &cobra.Command{
+		Use:   "close <issue-number> [--dry-run]",
+		Short: "Close the specified GitHub issue",
+		Args:  cobra.ExactArgs(1),
+		RunE:  a.runClose,
+	}
 	
-	if err := filefmt.AtomicWriteFile(filePath, content, 0o644); err != nil {
-		return model.NewIOError("failed to write file", err)
+	reopenCmd := &cobra.Command{
+		Use:   "reopen <issue-number> [--dry-run]",
+		Short: "Reopen the specified GitHub issue",
+		Args:  cobra.ExactArgs(1),
+		RunE:  a.runReopen,
 	}
 	
-	fmt.Printf("Saved to %s\n", filePath)
-	return nil
-}
-
-func runPush(cmd *cobra.Command, args []string) error {
-	issueNumber := args[0]
+	listCmd := &cobra.Command{
+		Use:   "list [-- LIST_OPTIONS...]",
+		Short: "List open GitHub Issues with custom formatting",
+		Long:  "List open GitHub Issues with custom formatting.\n\nLIST_OPTIONS are these gh issue list flags: " + backend.ListFlags + ".",
+		Args:  cobra.ArbitraryArgs,
+		RunE:  a.runList,
+	}
 	
-	if !model.IsNumeric(issueNumber) {
-		return model.NewUsageError("Usage: ghi push <issue-number>")
+	pruneCmd := &cobra.Command{
+		Use:   "prune [--archive] [--force] [--dry-run]",
+		Short: "Delete or archive local files for closed GitHub issues",
+		Args:  cobra.NoArgs,
+		RunE:  a.runPrune,
 	}
 	
-	filePath := filepath.Join(issuesDir, fmt.Sprintf("%s.md", issueNumber))
+	restoreCmd := &cobra.Command{
+		Use:   "restore <issue-number>|<from>-<to>...",
+		Short: "Move files archived by prune --archive back from issues/closed/",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  a.runRestore,
+	}
 	
-	raw, err := os.ReadFile(filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return model.NewIOError(fmt.Sprintf("%s not found. Run 'ghi pull %s' first", filePath, issueNumber), nil)
-		}
-		return model.NewIOError("failed to read file", err)
+	searchCmd := &cobra.Command{
+		Use:   "search <query>...",
+		Short: "Search the local issue files without network access",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  a.runSearch,
 	}
 	
-	fm, body, err := filefmt.DecodeMarkdown(raw)
-	if err != nil {
-		if strings.Contains(err.Error(), "malformed frontmatter") {
-			return model.NewIOError(fmt.Sprintf("Invalid frontmatter in %s", filePath), err)
-		}
-		return model.NewIOError("failed to parse markdown", err)
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show local vs remote state for every file in issues/",
+		Args:  cobra.NoArgs,
+		RunE:  a.runStatus,
 	}
 	
-	tmpFile, err := gh.CreateTempBodyFile(body)
-	if err != nil {
-		return model.NewIOError("failed to create temp file", err)
+	prCmd := &cobra.Command{
+		Use:   "pr",
+		Short: "Sync pull request descriptions with prs/{n}.md",
+	}
+	
+	prPullCmd := &cobra.Command{
+		Use:   "pull <pr-number>...",
+		Short: "Fetch pull requests and write them to prs/{n}.md",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  a.runPRPull,
 	}
-	defer os.Remove(tmpFile)
 	
-	if err := gh.EditIssue(issueNumber, fm.Title, tmpFile); err != nil {
-		return model.NewEnvError("", err)
+	prPushCmd := &cobra.Command{
+		Use:   "push <pr-number>...",
+		Short: "Update the title, body, base, labels, reviewers and draft state of pull requests from prs/{n}.md",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  a.runPRPush,
 	}
 	
-	fmt.Printf("Updated issue #%s from %s\n", issueNumber, filePath)
-	return nil
-}
-
-func runDiff(cmd *cobra.Command, args []string) error {
-	issueNumber := args[0]
+	prDiffCmd := &cobra.Command{
+		Use:   "diff <pr-number> [--json] [--word] [--git [--] [EXTRA_GIT_DIFF_ARGS...]]",
+		Short: "Compare local prs/{n}.md with the remote pull request",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  a.runPRDiff,
+	}
 	
-	if !model.IsNumeric(issueNumber) {
-		return model.NewUsageError("Usage: ghi diff <issue-number> [--] [EXTRA_GIT_DIFF_ARGS...]")
+	prStatusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show local vs remote state for every file in prs/",
+		Args:  cobra.NoArgs,
+		RunE:  a.runPRStatus,
 	}
 	
-	localPath := filepath.Join(issuesDir, fmt.Sprintf("%s.md", issueNumber))
+	discussionCmd := &cobra.Command{
+		Use:   "discussion",
+		Short: "Sync GitHub Discussions with discussions/{n}.md",
+	}
+	
+	discussionPullCmd := &cobra.Command{
+		Use:   "pull <discussion-number>...",
+		Short: "Fetch discussions with their comment threads and write them to discussions/{n}.md",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  a.runDiscussionPull,
+	}
+	
+	discussionPushCmd := &cobra.Command{
+		Use:   "push <discussion-number>...",
+		Short: "Update the title, body and category of discussions from discussions/{n}.md",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  a.runDiscussionPush,
+	}
+	
+	discussionListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the repository's discussions, most recently updated first",
+		Args:  cobra.NoArgs,
+		RunE:  a.runDiscussionList,
+	}
+	
+	discussionCreateCmd := &cobra.Command{
+		Use:   "create <title> --category NAME",
+		Short: "Start a discussion and write it to discussions/{n}.md",
+		Args:  cobra.ExactArgs(1),
+		RunE:  a.runDiscussionCreate,
+	}
+	
+	rootCmd.PersistentFlags().String("backend", "", "GitHub backend: gh (run the gh CLI) or api (call the GitHub API directly)")
+	rootCmd.PersistentFlags().StringP("repo", "R", "", "Operate on OWNER/REPO instead of the current repository")
+	rootCmd.PersistentFlags().Bool("workspace", false, "Keep files in issues/OWNER/REPO/ to mirror several repositories")
+	rootCmd.PersistentFlags().StringP("output", "o", outputText, "Output format: text, json or jsonl")
+	rootCmd.PersistentFlags().String("format", "", "Format the results with a Go template")
+	rootCmd.PersistentFlags().Bool("verbose", false, "Report retried GitHub calls and the remaining rate limit on stderr")
+	pullCmd.Flags().Bool("force", false, "Overwrite local changes instead of merging them")
+	pullCmd.Flags().Bool("all", false, "Pull every issue in the repository")
+	pullCmd.Flags().String("state", "open", "Issue state for --all: open, closed or all")
+	pullCmd.Flags().Bool("comments", false, "Include comments; later pulls keep them in sync")
+	pullCmd.Flags().String("since", "", "Pull every issue updated since a date, time or duration ago (e.g. 2024-05-01, 48h, 7d)")
+	pullCmd.Flags().Bool("full", false, "Ignore the watermark of the last pull --all and fetch every issue")
+	pushCmd.Flags().Bool("all", false, "Push every file modified since it was last pulled")
+	pushCmd.Flags().Bool("new", false, "Create an issue from every draft in issues/new/")
+	for _, cmd := range []*cobra.Command{pushCmd, closeCmd, reopenCmd, createCmd, pruneCmd} {
+		cmd.Flags().Bool("dry-run", false, "Show what would be sent to GitHub and written locally without doing it")
+	}
+	pruneCmd.Flags().Bool("archive", false, "Move the files to issues/closed/ instead of deleting them")
+	pruneCmd.Flags().Bool("force", false, "Also prune files edited since they were last pulled")
+	searchCmd.Flags().IntP("limit", "L", 30, "Maximum number of results (0 for all)")
+	statusCmd.Flags().Bool("json", false, "Output as JSON (same as --output json)")
+	for _, cmd := range []*cobra.Command{diffCmd, prDiffCmd} {
+		cmd.Flags().Bool("json", false, "Output the change set as JSON (same as --output json)")
+		cmd.Flags().BoolP("word", "w", false, "Show a word diff of the body")
+		cmd.Flags().Bool("git", false, "Show the differences with git diff")
+	}
+	prPullCmd.Flags().Bool("force", false, "Overwrite local changes instead of merging them")
+	discussionPullCmd.Flags().Bool("force", false, "Overwrite local changes instead of merging them")
+	discussionListCmd.Flags().StringP("category", "c", "", "Only list discussions in this category")
+	discussionListCmd.Flags().IntP("limit", "L", 30, "Maximum number of discussions")
+	discussionCreateCmd.Flags().StringP("category", "c", "", "Category of the discussion (required)")
+	discussionCreateCmd.Flags().StringP("body-file", "F", "", "Read the body from a file (\"-\" for standard input)")
+	editCmd.Flags().BoolP("yes", "y", false, "Push without asking for confirmation")
+	createCmd.Flags().StringP("body-file", "F", "", "Read the issue body from a file (\"-\" for standard input)")
+	createCmd.Flags().StringSliceP("label", "l", nil, "Add a label (repeatable)")
+	createCmd.Flags().StringSliceP("assignee", "a", nil, "Assign a user (repeatable)")
+	createCmd.Flags().StringP("milestone", "m", "", "Set the milestone by title")
+	createCmd.Flags().StringP("template", "T", "", "Start from an issue template in .github/ISSUE_TEMPLATE/")
+	createCmd.Flags().BoolP("editor", "e", false, "Edit the issue in $EDITOR before creating it")
 	
-	if _, err := os.Stat(localPath); err != nil {
-		if os.IsNotExist(err) {
-			return model.NewIOError(fmt.Sprintf("%s not found. Run 'ghi pull %s' first.", localPath, issueNumber), nil)
-		}
-		return model.NewIOError("failed to check local file", err)
-	}
+	rootCmd.AddCommand(pullCmd)
+	rootCmd.AddCommand(pushCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(createCmd)
+	rootCmd.AddCommand(closeCmd)
+	rootCmd.AddCommand(reopenCmd)
+	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(pruneCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(statusCmd)
+	
+	prCmd.AddCommand(prPullCmd)
+	prCmd.AddCommand(prPushCmd)
+	prCmd.AddCommand(prDiffCmd)
+	prCmd.AddCommand(prStatusCmd)
+	rootCmd.AddCommand(prCmd)
+	
+	discussionCmd.AddCommand(discussionPullCmd)
+	discussionCmd.AddCommand(discussionPushCmd)
+	discussionCmd.AddCommand(discussionListCmd)
+	discussionCmd.AddCommand(discussionCreateCmd)
+	rootCmd.AddCommand(discussionCmd)
+	
+	return rootCmd
+}
+
+// execute runs ghi with the given arguments and returns the exit code.
+func execute(a *app, args []string) int {
+	rootCmd := newRootCmd(a)
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(a.out)
+	rootCmd.SetErr(a.errOut)
 	
-	issue, err := gh.ViewIssue(issueNumber)
-	if err != nil {
-		return model.NewEnvError("", err)
+	err := rootCmd.Execute()
+	if a.verbose {
+		a.reportQuotas()
 	}
 	
-	tmpDir := filepath.Join(issuesDir, "tmp")
-	if err := os.MkdirAll(tmpDir, 0o755); err != nil {
-		return model.NewIOError("failed to create temp directory", err)
+	// Records are printed even when the command failed part way, so scripts
+	// learn which issues were processed.
+	if printErr := a.results.print(); printErr != nil && err == nil {
+		err = model.NewIOError("failed to write output", printErr)
 	}
 	
-	tmpFile, err := os.CreateTemp(tmpDir, fmt.Sprintf("remote-%s-*.md", issueNumber))
 	if err != nil {
-		return model.NewIOError("failed to create temp file", err)
+		var exitErr *model.ExitError
+		if e, ok := err.(*model.ExitError); ok {
+			exitErr = e
+		} else {
+			exitErr = &model.ExitError{Code: model.ExitIO, Message: err.Error()}
+		}
+		
+		// Errors without a message only carry an exit code (e.g. diff found
+		// differences).
+		a.printError(exitErr, "")
+		return int(exitErr.Code)
 	}
-	tmpPath := tmpFile.Name()
-	defer os.Remove(tmpPath)
 	
-	fm := model.Frontmatter{Title: issue.Title}
-	content, err := filefmt.EncodeMarkdown(fm, []byte(issue.Body))
-	if err != nil {
-		tmpFile.Close()
-		return model.NewIOError("failed to encode remote markdown", err)
+	return 0
+}
+
+func main() {
+	os.Exit(execute(&app{in: os.Stdin, out: os.Stdout, errOut: os.Stderr}, os.Args[1:]))
+}
+
+func (a *app) runPull(cmd *cobra.Command, args []string) error {
+	numberArgs, listArgs := args, []string(nil)
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		numberArgs, listArgs = args[:dash], args[dash:]
 	}
 	
-	if _, err := tmpFile.Write(content); err != nil {
-		tmpFile.Close()
-		return model.NewIOError("failed to write temp file", err)
-	}
+	all, _ := cmd.Flags().GetBool("all")
+	force, _ := cmd.Flags().GetBool("force")
+	comments, _ := cmd.Flags().GetBool("comments")
+	// --since is an incremental --all.
+	all = all || cmd.Flags().Changed("since")
 	
-	if err := tmpFile.Close(); err != nil {
-		return model.NewIOError("failed to close temp file", err)
+	if len(numberArgs) == 1 && !all && listArgs == nil && model.IsNumeric(numberArgs[0]) {
+		return a.runPullOne(numberArgs[0], force, comments)
 	}
 	
-	extraArgs := args[1:]
-	dashIndex := -1
-	for i, arg := range extraArgs {
-		if arg == "--" {
-			dashIndex = i
-			break
-		}
+	return a.eachRepo(func(r *app) error {
+		return r.runBulkPull(cmd, numberArgs, listArgs, all, force, comments)
+	})
+}
+
+func (a *app) runPullOne(issueNumber string, force bool, comments bool) error {
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return model.NewIOError("failed to create issues directory", err)
 	}
 	
-	if dashIndex >= 0 {
-		extraArgs = extraArgs[dashIndex+1:]
+	issue, err := a.client.ViewIssue(issueNumber)
+	if err != nil {
+		return backendError(err)
 	}
 	
-	exitCode, err := gh.RunGitDiff(tmpPath, localPath, extraArgs)
+	outcome, conflicts, err := a.pullIssue(issue, force, comments)
 	if err != nil {
-		return model.NewEnvError("", err)
+		return err
 	}
 	
-	switch exitCode {
-	case 0:
-		fmt.Printf("No differences: %s matches remote.\n", localPath)
-		return nil
-	case 1:
-		os.Exit(1)
-		return nil
+	filePath := a.issuePath(issueNumber, issue.Title)
+	
+	result := a.result(issueNumber, filePath, outcome.String())
+	result.Conflicts = conflicts
+	a.emit(result)
+	
+	switch outcome {
+	case pullConflict:
+		return conflictError(filePath, issueNumber, conflicts)
+	case pullMerged:
+		fmt.Fprintf(a.out, "Merged remote changes into %s\n", filePath)
+	case pullKept:
+		fmt.Fprintf(a.out, "Kept local changes in %s (remote unchanged)\n", filePath)
 	default:
-		return model.NewEnvError(fmt.Sprintf("git diff failed with exit code %d", exitCode), nil)
+		fmt.Fprintf(a.out, "Saved to %s\n", filePath)
 	}
+	return nil
 }
 
-func runCreate(cmd *cobra.Command, args []string) error {
-	title := strings.TrimSpace(args[0])
-	
-	if title == "" {
-		return model.NewUsageError("Usage: ghi create <issue-title>")
-	}
+func (a *app) runPush(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
 	
-	issueNumber, err := gh.CreateIssue(title)
-	if err != nil {
-		return model.NewEnvError("", err)
+	if createNew, _ := cmd.Flags().GetBool("new"); createNew {
+		if all || len(args) > 0 {
+			return model.NewUsageError("--new cannot be combined with issue numbers or --all")
+		}
+		return a.eachRepo(func(r *app) error { return r.runPushNew() })
 	}
 	
-	if err := os.MkdirAll(issuesDir, 0o755); err != nil {
-		return model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to create local directory", issueNumber), err)
+	if len(args) == 1 && !all && model.IsNumeric(args[0]) {
+		return a.runPushOne(args[0])
 	}
 	
-	issue, err := gh.ViewIssue(fmt.Sprintf("%d", issueNumber))
-	if err != nil {
-		return model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to fetch details", issueNumber), err)
+	return a.eachRepo(func(r *app) error { return r.runBulkPush(args, all) })
+}
+
+func (a *app) runPushOne(issueNumber string) error {
+	if a.dryRun {
+		return a.planPushOne(issueNumber)
 	}
 	
-	fm := model.Frontmatter{Title: issue.Title}
+	filePath := a.issuePath(issueNumber, "")
 	
-	content, err := filefmt.EncodeMarkdown(fm, []byte(issue.Body))
+	outcome, conflicts, err := a.pushIssue(issueNumber)
 	if err != nil {
-		return model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to encode markdown", issueNumber), err)
+		return err
 	}
 	
-	filePath := filepath.Join(issuesDir, fmt.Sprintf("%d.md", issueNumber))
+	result := a.result(issueNumber, filePath, outcome.String())
+	result.Conflicts = conflicts
+	a.emit(result)
 	
-	if err := filefmt.AtomicWriteFile(filePath, content, 0o644); err != nil {
-		return model.NewIOError(fmt.Sprintf("Issue #%d created on GitHub but failed to write local file", issueNumber), err)
+	if outcome == pushConflict {
+		return conflictError(filePath, issueNumber, conflicts)
 	}
-	
-	absPath, err := filepath.Abs(filePath)
-	if err != nil {
-		return model.NewIOError(fmt.Sprintf("Issue #%d created and saved locally but failed to resolve absolute path", issueNumber), err)
+	if outcome == pushMerged {
+		fmt.Fprintf(a.out, "Merged remote changes into %s\n", filePath)
 	}
 	
-	fmt.Println(absPath)
+	fmt.Fprintf(a.out, "Updated issue #%s from %s\n", issueNumber, filePath)
 	return nil
 }
 
-func runClose(cmd *cobra.Command, args []string) error {
+func (a *app) runDiff(cmd *cobra.Command, args []string) error {
 	issueNumber := args[0]
 	
 	if !model.IsNumeric(issueNumber) {
-		return model.NewUsageError("Usage: ghi close <issue-number>")
+		return model.NewUsageError("Usage: ghi diff <issue-number> [--json] [--word] [--git [--] [EXTRA_GIT_DIFF_ARGS...]]")
+	}
+	
+	extraArgs := args[1:]
+	dashIndex := -1
+	for i, arg := range extraArgs {
+		if arg == "--" {
+			dashIndex = i
+			break
+		}
+	}
+	
+	if dashIndex >= 0 {
+		extraArgs = extraArgs[dashIndex+1:]
 	}
 	
-	if err := gh.CloseIssue(issueNumber); err != nil {
-		return model.NewEnvError("", err)
+	localPath := a.issuePath(issueNumber, "")
+	changes, err := a.diffIssue(issueNumber, localPath, diffFlags(cmd, extraArgs))
+	if err != nil {
+		return err
+	}
+	a.emit(changes)
+	if changes.differs() {
+		// Differences are a result, not a misuse of the command.
+		cmd.SilenceUsage = true
+		return &model.ExitError{Code: 1}
 	}
 	
+	fmt.Fprintf(a.out, "No differences: %s matches remote.\n", localPath)
 	return nil
 }
 
-func runReopen(cmd *cobra.Command, args []string) error {
+func (a *app) runClose(cmd *cobra.Command, args []string) error {
 	issueNumber := args[0]
 	
 	if !model.IsNumeric(issueNumber) {
-		return model.NewUsageError("Usage: ghi reopen <issue-number>")
+		return model.NewUsageError("Usage: ghi close <issue-number> [--dry-run]")
 	}
 	
-	if err := gh.ReopenIssue(issueNumber); err != nil {
-		return model.NewEnvError("", err)
+	if a.dryRun {
+		n, _ := strconv.Atoi(issueNumber)
+		a.planned(a.result(issueNumber, "", "closed"), []mutation{{Op: "close_issue", Issue: n}})
+		fmt.Fprintln(a.out, dryRunNote)
+		return nil
+	}
+	
+	if err := a.client.CloseIssue(issueNumber); err != nil {
+		return backendError(err)
 	}
 	
+	a.emit(a.result(issueNumber, "", "closed"))
+	fmt.Fprintf(a.out, "Closed issue #%s.\n", issueNumber)
 	return nil
 }
 
-func runList(cmd *cobra.Command, args []string) error {
-	// Find the "--" separator if present
-	extraArgs := []string{}
-	dashIndex := -1
-	for i, arg := range args {
-		if arg == "--" {
-			dashIndex = i
-			break
-		}
-	}
+func (a *app) runReopen(cmd *cobra.Command, args []string) error {
+	issueNumber := args[0]
 	
-	// Everything after "--" is passed to gh
-	if dashIndex >= 0 {
-		extraArgs = args[dashIndex+1:]
+	if !model.IsNumeric(issueNumber) {
+		return model.NewUsageError("Usage: ghi reopen <issue-number> [--dry-run]")
 	}
 	
-	issues, err := gh.ListIssues(extraArgs)
-	if err != nil {
-		// Check if it's an invalid option error from gh
-		if strings.Contains(err.Error(), "unknown flag") || strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "unrecognized") {
-			return model.NewUsageError(err.Error())
-		}
-		return model.NewEnvError("", err)
+	if a.dryRun {
+		n, _ := strconv.Atoi(issueNumber)
+		a.planned(a.result(issueNumber, "", "reopened"), []mutation{{Op: "reopen_issue", Issue: n}})
+		fmt.Fprintln(a.out, dryRunNote)
+		return nil
 	}
 	
-	// Format and output issues
-	for i, issue := range issues {
-		fmt.Printf("#%d %s\n", issue.Number, issue.Title)
-		fmt.Println(issue.URL)
-		// Add blank line between issues, but not after the last one
-		if i < len(issues)-1 {
-			fmt.Println()
-		}
+	if err := a.client.ReopenIssue(issueNumber); err != nil {
+		return backendError(err)
 	}
 	
+	a.emit(a.result(issueNumber, "", "reopened"))
+	fmt.Fprintf(a.out, "Reopened issue #%s.\n", issueNumber)
 	return nil
 }
 
-func runPrune(cmd *cobra.Command, args []string) error {
-	// Check if issues directory exists
-	if _, err := os.Stat(issuesDir); os.IsNotExist(err) {
-		return model.NewIOError("issues directory does not exist", nil)
+func (a *app) runList(cmd *cobra.Command, args []string) error {
+	// Everything after "--" is a gh issue list option
+	extraArgs := []string{}
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		extraArgs = args[dash:]
+	} else if len(args) > 0 {
+		return model.NewUsageError("Usage: ghi list [-- LIST_OPTIONS...]")
 	}
 	
-	// Get list of closed issues from GitHub
-	closedIssues, err := gh.ListClosedIssues()
+	opts, err := backend.ParseListArgs(extraArgs)
 	if err != nil {
-		return model.NewEnvError("failed to list closed issues", err)
+		return model.NewUsageError(err.Error())
 	}
+	a.cfg.ApplyListDefaults(&opts)
 	
-	// If no closed issues, exit silently with success
-	if len(closedIssues) == 0 {
-		return nil
+	issues, err := a.client.ListIssues(opts)
+	if err != nil {
+		return backendError(err)
 	}
 	
-	// Delete files for each closed issue
-	for _, issue := range closedIssues {
-		filePath := filepath.Join(issuesDir, fmt.Sprintf("%d.md", issue.Number))
-		
-		// Check if file exists before attempting deletion
-		if _, err := os.Stat(filePath); err == nil {
-			if err := os.Remove(filePath); err != nil {
-				return model.NewIOError(fmt.Sprintf("failed to delete %s", filePath), err)
-			}
-		}
-		// If file doesn't exist, continue silently
+	a.listResults()
+	for _, issue := range issues {
+		a.emit(issue)
 	}
 	
-	// Delete tmp directory if it exists
-	tmpDir := filepath.Join(issuesDir, "tmp")
-	if _, err := os.Stat(tmpDir); err == nil {
-		if err := os.RemoveAll(tmpDir); err != nil {
-			return model.NewIOError("failed to delete tmp directory", err)
+	// Format and output issues
+	for i, issue := range issues {
+		fmt.Fprintf(a.out, "#%d %s\n", issue.Number, issue.Title)
+		fmt.Fprintln(a.out, issue.URL)
+		// Add blank line between issues, but not after the last one
+		if i < len(issues)-1 {
+			fmt.Fprintln(a.out)
 		}
 	}
 	
-	// Silent exit on success
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,856 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/backend/fake"
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// harness runs ghi commands in a fresh working directory against fake
+// backends. backend is the current repository, owner/repo.
+type harness struct {
+	t        *testing.T
+	backend  *fake.Backend
+	backends map[string]*fake.Backend
+	stdin    string
+	out      bytes.Buffer
+	errOut   bytes.Buffer
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Chdir(t.TempDir())
+	b := fake.New()
+	return &harness{t: t, backend: b, backends: map[string]*fake.Backend{b.Repo: b}}
+}
+
+// remote returns the fake backend of another repository, creating it on
+// first use.
+func (h *harness) remote(repo string) *fake.Backend {
+	if b, ok := h.backends[repo]; ok {
+		return b
+	}
+	b := fake.New()
+	b.Repo = repo
+	h.backends[repo] = b
+	return b
+}
+
+// run executes ghi with args and returns the exit code. Output from previous
+// runs is discarded.
+func (h *harness) run(args ...string) int {
+	h.out.Reset()
+	h.errOut.Reset()
+	connect := func(repo string) (backend.Backend, error) {
+		if repo == "" {
+			return h.backend, nil
+		}
+		return h.remote(repo), nil
+	}
+	return execute(&app{connect: connect, in: strings.NewReader(h.stdin), out: &h.out, errOut: &h.errOut}, args)
+}
+
+// mustRun executes ghi and fails the test unless it exits with want.
+func (h *harness) mustRun(want int, args ...string) {
+	h.t.Helper()
+	if code := h.run(args...); code != want {
+		h.t.Fatalf("ghi %s: exit %d, want %d\nstdout: %s\nstderr: %s", strings.Join(args, " "), code, want, h.out.String(), h.errOut.String())
+	}
+}
+
+func (h *harness) read(path string) string {
+	h.t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return string(data)
+}
+
+func (h *harness) write(path, content string) {
+	h.t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		h.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+func sampleIssue() model.IssueData {
+	return model.IssueData{
+		Number:    1,
+		Title:     "Fix login",
+		Body:      "line one\nline two\nline three\n",
+		Labels:    []model.Label{{Name: "bug"}},
+		Assignees: []model.User{{Login: "octocat"}},
+		Milestone: &model.Milestone{Title: "v1.0"},
+	}
+}
+
+const sampleFile = `---
+title: Fix login
+labels:
+  - bug
+assignees:
+  - octocat
+milestone: v1.0
+state: open
+---
+line one
+line two
+line three
+`
+
+func TestPullWritesIssueFile(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	
+	h.mustRun(0, "pull", "1")
+	
+	if got := h.read("issues/1.md"); got != sampleFile {
+		t.Errorf("issues/1.md =\n%s\nwant\n%s", got, sampleFile)
+	}
+	if _, err := os.Stat("issues/.ghi/base/1.json"); err != nil {
+		t.Errorf("base snapshot not saved: %v", err)
+	}
+	if got := h.out.String(); got != "Saved to issues/1.md\n" {
+		t.Errorf("stdout = %q", got)
+	}
+}
+
+func TestPullPushRoundTripIsIdempotent(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	want := *h.backend.Issue(1)
+	
+	h.mustRun(0, "pull", "1")
+	h.mustRun(0, "push", "1")
+	
+	got := h.backend.Issue(1)
+	if got.Title != want.Title || got.Body != want.Body || got.State != want.State {
+		t.Errorf("push changed the issue: got %+v, want %+v", got, want)
+	}
+	if !slices.Equal(got.Labels, want.Labels) || !slices.Equal(got.Assignees, want.Assignees) {
+		t.Errorf("push changed metadata: got %+v, want %+v", got, want)
+	}
+	
+	h.mustRun(0, "pull", "1")
+	if got := h.read("issues/1.md"); got != sampleFile {
+		t.Errorf("second pull changed the file:\n%s", got)
+	}
+	
+	h.mustRun(0, "status")
+	if !strings.Contains(h.out.String(), "clean") {
+		t.Errorf("status after round trip = %q, want clean", h.out.String())
+	}
+}
+
+func TestPushUpdatesMetadata(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.mustRun(0, "pull", "1")
+	
+	h.write("issues/1.md", `---
+title: Fix login redirect
+labels:
+  - enhancement
+assignees: []
+milestone: v2.0
+state: closed
+---
+new body
+`)
+	h.mustRun(0, "push", "1")
+	
+	got := h.backend.Issue(1)
+	fm := got.Frontmatter()
+	if fm.Title != "Fix login redirect" || got.Body != "new body\n" || fm.State != "closed" || fm.Milestone != "v2.0" {
+		t.Errorf("remote = %+v", fm)
+	}
+	if !slices.Equal(fm.Labels, []string{"enhancement"}) || len(fm.Assignees) != 0 {
+		t.Errorf("labels = %v, assignees = %v", fm.Labels, fm.Assignees)
+	}
+	
+	// An empty milestone clears it; a null list leaves the labels alone.
+	h.write("issues/1.md", "---\ntitle: Fix login redirect\nlabels:\nmilestone:\n---\nnew body\n")
+	h.mustRun(0, "push", "1")
+	
+	fm = h.backend.Issue(1).Frontmatter()
+	if fm.Milestone != "" || !slices.Equal(fm.Labels, []string{"enhancement"}) {
+		t.Errorf("after clearing the milestone remote = %+v", fm)
+	}
+	if got := h.read("issues/1.md"); !strings.Contains(got, "milestone:") {
+		t.Errorf("push dropped the empty milestone key:\n%s", got)
+	}
+	h.mustRun(0, "status")
+	if got := h.out.String(); strings.Contains(got, "modified") || !strings.Contains(got, "1.md") {
+		t.Errorf("status after push printed\n%s", got)
+	}
+}
+
+func TestPushMergesRemoteChanges(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.mustRun(0, "pull", "1")
+	
+	h.write("issues/1.md", strings.Replace(sampleFile, "line one", "local one", 1))
+	h.backend.Update(1, func(issue *model.IssueData) {
+		issue.Body = "line one\nline two\nremote three\n"
+	})
+	
+	h.mustRun(0, "push", "1")
+	
+	if got, want := h.backend.Issue(1).Body, "local one\nline two\nremote three\n"; got != want {
+		t.Errorf("remote body = %q, want %q", got, want)
+	}
+	if !strings.Contains(h.out.String(), "Merged remote changes") {
+		t.Errorf("stdout = %q", h.out.String())
+	}
+}
+
+func TestConflictingEditsExitWithConflict(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.mustRun(0, "pull", "1")
+	
+	h.write("issues/1.md", strings.Replace(sampleFile, "line two", "local two", 1))
+	h.backend.Update(1, func(issue *model.IssueData) {
+		issue.Body = "line one\nremote two\nline three\n"
+	})
+	
+	h.mustRun(int(model.ExitConflict), "pull", "1")
+	if got := h.read("issues/1.md"); !strings.Contains(got, "<<<<<<< local\nlocal two\n=======\nremote two\n>>>>>>> remote\n") {
+		t.Errorf("file has no conflict markers:\n%s", got)
+	}
+	
+	// Pushing is refused until the markers are resolved.
+	h.mustRun(int(model.ExitConflict), "push", "1")
+	if got := h.backend.Issue(1).Body; got != "line one\nremote two\nline three\n" {
+		t.Errorf("conflicted file was pushed: %q", got)
+	}
+}
+
+func TestConflictingTitlesAreMarked(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.mustRun(0, "pull", "1")
+	
+	h.write("issues/1.md", strings.Replace(sampleFile, "title: Fix login", "title: Fix sign-in", 1))
+	h.backend.Update(1, func(issue *model.IssueData) { issue.Title = "Fix logout" })
+	
+	h.mustRun(int(model.ExitConflict), "push", "1")
+	if got := h.read("issues/1.md"); !strings.Contains(got, "title: <<<<<<< local Fix sign-in ||||||| base Fix login ======= Fix logout >>>>>>> remote\n") {
+		t.Errorf("file has no title conflict markers:\n%s", got)
+	}
+	
+	// The teammate's title is not overwritten until the field is resolved.
+	h.mustRun(int(model.ExitConflict), "push", "1")
+	if got := h.backend.Issue(1).Title; got != "Fix logout" {
+		t.Errorf("conflicted title was pushed: %q", got)
+	}
+	
+	h.write("issues/1.md", strings.Replace(sampleFile, "title: Fix login", "title: Fix sign-in", 1))
+	h.mustRun(0, "push", "1")
+	if got := h.backend.Issue(1).Title; got != "Fix sign-in" {
+		t.Errorf("resolved title = %q", got)
+	}
+}
+
+func TestPullKeepsLocalChanges(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.mustRun(0, "pull", "1")
+	
+	edited := strings.Replace(sampleFile, "line one", "local one", 1)
+	h.write("issues/1.md", edited)
+	
+	h.mustRun(0, "pull", "1")
+	if got := h.read("issues/1.md"); got != edited {
+		t.Errorf("pull discarded local changes:\n%s", got)
+	}
+	
+	h.mustRun(0, "pull", "--force", "1")
+	if got := h.read("issues/1.md"); got != sampleFile {
+		t.Errorf("pull --force kept local changes:\n%s", got)
+	}
+}
+
+func TestPullKeepsCustomFrontmatter(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.mustRun(0, "pull", "1")
+	
+	custom := strings.Replace(sampleFile, "---\ntitle:", "---\npriority: high # mine\ntitle:", 1)
+	custom = strings.Replace(custom, "state: open\n", "state: open\nnotes: ask @octocat\n", 1)
+	h.write("issues/1.md", custom)
+	
+	h.mustRun(0, "diff", "1")
+	
+	h.backend.Update(1, func(issue *model.IssueData) { issue.Title = "Fix login page" })
+	h.mustRun(0, "pull", "1")
+	want := strings.Replace(custom, "title: Fix login\n", "title: Fix login page\n", 1)
+	if got := h.read("issues/1.md"); got != want {
+		t.Errorf("issues/1.md =\n%s\nwant\n%s", got, want)
+	}
+	
+	h.mustRun(0, "status")
+	if !strings.Contains(h.out.String(), "clean") {
+		t.Errorf("custom keys count as local changes:\n%s", h.out.String())
+	}
+}
+
+func TestBulkPull(t *testing.T) {
+	h := newHarness(t)
+	for _, title := range []string{"one", "two", "three"} {
+		h.backend.Add(model.IssueData{Title: title})
+	}
+	h.backend.Update(3, func(issue *model.IssueData) { issue.State = "CLOSED" })
+	
+	h.mustRun(0, "pull", "--all")
+	for _, path := range []string{"issues/1.md", "issues/2.md"} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s not pulled: %v", path, err)
+		}
+	}
+	if _, err := os.Stat("issues/3.md"); err == nil {
+		t.Errorf("closed issue pulled without --state")
+	}
+	
+	// Missing issues are reported but do not stop the others.
+	h.mustRun(int(model.ExitEnv), "pull", "1-3", "9")
+	if out := h.out.String(); !strings.Contains(out, "#9") || !strings.Contains(out, "#3") {
+		t.Errorf("summary = %q", out)
+	}
+	if _, err := os.Stat("issues/3.md"); err != nil {
+		t.Errorf("issues/3.md not pulled: %v", err)
+	}
+}
+
+func TestIncrementalPull(t *testing.T) {
+	h := newHarness(t)
+	for _, title := range []string{"one", "two", "three", "four"} {
+		h.backend.Add(model.IssueData{Title: title, Body: "body\n"})
+	}
+	h.mustRun(0, "pull", "--all")
+	if _, err := os.Stat("issues/.ghi/watermark.json"); err != nil {
+		t.Fatalf("watermark not saved: %v", err)
+	}
+	
+	h.backend.Update(1, func(issue *model.IssueData) { issue.Title = "one renamed" })
+	h.backend.Update(2, func(issue *model.IssueData) { issue.Title = "two renamed" })
+	edited := strings.Replace(h.read("issues/2.md"), "body", "local edit", 1)
+	h.write("issues/2.md", edited)
+	
+	// summary drops the column padding of the pull --all summary.
+	summary := func() string { return strings.Join(strings.Fields(h.out.String()), " ") }
+	
+	h.mustRun(0, "pull", "--all")
+	if out := summary(); !strings.Contains(out, "updated 1 #1") || !strings.Contains(out, "skipped 1 #2") || strings.Contains(out, "#3") {
+		t.Errorf("summary = %q", out)
+	}
+	if !strings.Contains(h.errOut.String(), "Skipped issues/2.md: modified locally") {
+		t.Errorf("stderr = %q", h.errOut.String())
+	}
+	if got := h.read("issues/2.md"); got != edited {
+		t.Errorf("locally modified file was changed:\n%s", got)
+	}
+	
+	// The skipped issue is fetched again until it can be pulled.
+	h.mustRun(0, "pull", "--all")
+	if out := summary(); !strings.Contains(out, "skipped 1 #2") {
+		t.Errorf("summary = %q", out)
+	}
+	h.mustRun(0, "pull", "--all", "--force")
+	if got := h.read("issues/2.md"); !strings.Contains(got, "title: two renamed") {
+		t.Errorf("--force did not overwrite:\n%s", got)
+	}
+	h.mustRun(0, "pull", "--all")
+	if out := summary(); strings.Contains(out, "#1") || strings.Contains(out, "#3") {
+		t.Errorf("unchanged issues fetched again: %q", out)
+	}
+	
+	h.mustRun(0, "pull", "--all", "--full")
+	if out := summary(); !strings.Contains(out, "unchanged 4") {
+		t.Errorf("--full summary = %q", out)
+	}
+	
+	h.mustRun(0, "pull", "--since", "1h")
+	h.mustRun(int(model.ExitUsage), "pull", "--since", "yesterday")
+	h.mustRun(int(model.ExitUsage), "pull", "1", "2", "--full")
+}
+
+func TestParseIssueArgs(t *testing.T) {
+	got, err := parseIssueArgs([]string{"3", "1-4", "2"})
+	if err != nil || !slices.Equal(got, []string{"3", "1", "2", "4"}) {
+		t.Errorf("parseIssueArgs = %v, %v", got, err)
+	}
+	if got, err := parseIssueArgs([]string{"1-1000"}); err != nil || len(got) != 1000 {
+		t.Errorf("parseIssueArgs(1-1000) = %d numbers, %v", len(got), err)
+	}
+	for _, arg := range []string{"99999999999999999999", "1-99999999999999999999", "1-1001", "5-3", "x"} {
+		if _, err := parseIssueArgs([]string{arg}); err == nil {
+			t.Errorf("parseIssueArgs(%s) succeeded", arg)
+		}
+	}
+	
+	h := newHarness(t)
+	h.mustRun(int(model.ExitUsage), "pull", "1-99999999999999999999")
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"2024-05-01T08:00:00Z": time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		"2024-05-01":           time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local),
+		"36h":                  now.Add(-36 * time.Hour),
+		"7d":                   now.AddDate(0, 0, -7),
+	}
+	for value, want := range tests {
+		got, err := parseSince(value, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if _, err := parseSince("-1d", now); err == nil {
+		t.Error("negative duration accepted")
+	}
+}
+
+func TestBulkPushContinuesAfterFailure(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(model.IssueData{Title: "one", Body: "a\n"})
+	h.backend.Add(model.IssueData{Title: "two", Body: "b\n"})
+	h.mustRun(0, "pull", "1", "2")
+	
+	h.write("issues/1.md", strings.Replace(h.read("issues/1.md"), "a\n", "a2\n", 1))
+	h.write("issues/2.md", strings.Replace(h.read("issues/2.md"), "b\n", "b2\n", 1))
+	
+	h.mustRun(0, "push", "--all")
+	if h.backend.Issue(1).Body != "a2\n" || h.backend.Issue(2).Body != "b2\n" {
+		t.Errorf("push --all did not push both issues")
+	}
+	
+	h.write("issues/1.md", strings.Replace(h.read("issues/1.md"), "a2\n", "a3\n", 1))
+	h.backend.Fail["EditIssue"] = &backend.Error{Kind: backend.KindForbidden, Message: "forbidden"}
+	h.backend.Calls = nil
+	h.mustRun(int(model.ExitEnv), "push", "1", "2")
+	if !slices.Contains(h.backend.Calls, "EditIssue 1") || !slices.Contains(h.backend.Calls, "EditIssue 2") {
+		t.Errorf("push stopped after the first failure: %v", h.backend.Calls)
+	}
+	if !strings.Contains(h.errOut.String(), "forbidden") {
+		t.Errorf("stderr = %q", h.errOut.String())
+	}
+}
+
+func TestBulkPushLeavesRateLimitsToBackend(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(model.IssueData{Title: "one", Body: "a\n"})
+	h.mustRun(0, "pull", "1")
+	h.write("issues/1.md", strings.Replace(h.read("issues/1.md"), "a\n", "b\n", 1))
+	
+	// The backend's retrier has already waited and retried a rate limit it
+	// returns, so bulk push reports it instead of retrying again.
+	h.backend.Fail["EditIssue"] = &backend.Error{Kind: backend.KindRateLimited, Message: "rate limited"}
+	h.mustRun(int(model.ExitEnv), "push", "--all")
+	
+	var edits int
+	for _, call := range h.backend.Calls {
+		if call == "EditIssue 1" {
+			edits++
+		}
+	}
+	if edits != 1 {
+		t.Errorf("EditIssue called %d times, want 1", edits)
+	}
+	if !strings.Contains(h.out.String(), "#1 failed: rate limited") {
+		t.Errorf("stdout = %q", h.out.String())
+	}
+}
+
+func TestStatus(t *testing.T) {
+	h := newHarness(t)
+	for _, title := range []string{"clean", "local", "remote", "closed", "missing"} {
+		h.backend.Add(model.IssueData{Title: title, Body: "body\n"})
+	}
+	h.mustRun(0, "pull", "1-5")
+	
+	h.write("issues/2.md", strings.Replace(h.read("issues/2.md"), "body", "edited", 1))
+	h.backend.Update(3, func(issue *model.IssueData) { issue.Body = "changed\n" })
+	h.backend.Update(4, func(issue *model.IssueData) { issue.State = "CLOSED" })
+	h.backend.Delete(5)
+	h.write("issues/6.md", "---\ntitle: [unterminated\n---\n")
+	
+	h.mustRun(0, "status", "--json")
+	
+	var entries []struct {
+		Number int    `json:"number"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(h.out.Bytes(), &entries); err != nil {
+		t.Fatalf("invalid JSON %q: %v", h.out.String(), err)
+	}
+	got := map[int]string{}
+	for _, e := range entries {
+		got[e.Number] = e.Status
+	}
+	want := map[int]string{
+		1: "clean",
+		2: "locally_modified",
+		3: "remotely_modified",
+		4: "closed_remotely",
+		5: "missing_remotely",
+		6: "invalid",
+	}
+	for n, status := range want {
+		if got[n] != status {
+			t.Errorf("#%d status = %q, want %q", n, got[n], status)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(model.IssueData{Title: "open"})
+	h.backend.Add(model.IssueData{Title: "closed"})
+	h.mustRun(0, "pull", "1", "2")
+	h.backend.Update(2, func(issue *model.IssueData) { issue.State = "CLOSED" })
+	h.write("issues/tmp/remote-1.md", "")
+	
+	h.mustRun(0, "prune")
+	
+	if _, err := os.Stat("issues/1.md"); err != nil {
+		t.Errorf("open issue pruned: %v", err)
+	}
+	if _, err := os.Stat("issues/2.md"); err == nil {
+		t.Errorf("closed issue not pruned")
+	}
+	if _, err := os.Stat("issues/tmp"); err == nil {
+		t.Errorf("issues/tmp not removed")
+	}
+	if got := h.out.String(); got != "Deleted issues/2.md\nDeleted issues/tmp\nDeleted 1 closed issue file(s)\n" {
+		t.Errorf("prune printed %q", got)
+	}
+}
+
+func TestPruneFindsEveryClosedIssue(t *testing.T) {
+	h := newHarness(t)
+	for range 40 {
+		h.backend.Add(model.IssueData{Title: "old", State: "CLOSED"})
+	}
+	h.backend.Add(model.IssueData{Title: "unknown", State: "CLOSED"})
+	h.mustRun(0, "pull", "1-40")
+	h.write("issues/41.md", "---\ntitle: unknown\n---\nwritten by hand\n")
+	
+	// More closed issues than one page of a listing are all found; a file
+	// with no base might hold edits and is kept.
+	h.mustRun(0, "prune")
+	if got := h.out.String(); !strings.HasSuffix(got, "Deleted 40 closed issue file(s); skipped 1 with no record of the last pull (use --force to prune them)\n") {
+		t.Errorf("prune printed\n%s", got)
+	}
+	if !strings.Contains(h.errOut.String(), "Skipping issues/41.md: no record of the last pull") {
+		t.Errorf("stderr = %q", h.errOut.String())
+	}
+	if _, err := os.Stat("issues/41.md"); err != nil {
+		t.Errorf("file without a base pruned: %v", err)
+	}
+	
+	h.mustRun(0, "prune", "--force")
+	if _, err := os.Stat("issues/41.md"); err == nil {
+		t.Error("prune --force kept the file without a base")
+	}
+}
+
+func TestRestoreRanges(t *testing.T) {
+	h := newHarness(t)
+	for _, title := range []string{"one", "two", "three"} {
+		h.backend.Add(model.IssueData{Title: title})
+	}
+	h.mustRun(0, "pull", "1-3")
+	h.backend.Update(1, func(issue *model.IssueData) { issue.State = "CLOSED" })
+	h.backend.Update(3, func(issue *model.IssueData) { issue.State = "CLOSED" })
+	h.mustRun(0, "prune", "--archive")
+	
+	// A number named on its own must be archived; nothing moves if it is not.
+	h.mustRun(3, "restore", "1", "2")
+	if _, err := os.Stat("issues/closed/1.md"); err != nil {
+		t.Error("failed restore moved issues/closed/1.md")
+	}
+	
+	h.mustRun(0, "restore", "1-4")
+	if got := h.out.String(); got != "Restored issues/closed/1.md -> issues/1.md\nRestored issues/closed/3.md -> issues/3.md\n" {
+		t.Errorf("restore printed %q", got)
+	}
+	if got := h.errOut.String(); got != "Not archived in issues/closed: #2 #4\n" {
+		t.Errorf("restore reported %q", got)
+	}
+	for _, path := range []string{"issues/1.md", "issues/2.md", "issues/3.md"} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s missing after restore", path)
+		}
+	}
+}
+
+func TestPruneKeepsLocalEditsAndArchives(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(model.IssueData{Title: "edited"})
+	h.backend.Add(model.IssueData{Title: "clean"})
+	h.mustRun(0, "pull", "1", "2")
+	h.backend.Update(1, func(issue *model.IssueData) { issue.State = "CLOSED" })
+	h.backend.Update(2, func(issue *model.IssueData) { issue.State = "CLOSED" })
+	h.write("issues/1.md", h.read("issues/1.md")+"unpushed notes\n")
+	
+	h.mustRun(0, "prune", "--archive")
+	want := "Skipped issues/1.md: edited since the last pull\n" +
+		"Archived issues/2.md -> issues/closed/2.md\n" +
+		"Archived 1 closed issue file(s); skipped 1 with local edits (use --force to prune them)\n"
+	if got := h.out.String(); got != want {
+		t.Errorf("prune --archive printed\n%s\nwant\n%s", got, want)
+	}
+	if !strings.HasSuffix(h.read("issues/1.md"), "unpushed notes\n") {
+		t.Error("edited file pruned")
+	}
+	
+	// Archived files are out of the way until restored, and still in sync.
+	h.mustRun(0, "status")
+	if strings.Contains(h.out.String(), "issues/2.md") {
+		t.Errorf("status lists the archived file:\n%s", h.out.String())
+	}
+	h.mustRun(0, "restore", "2")
+	if got := h.out.String(); got != "Restored issues/closed/2.md -> issues/2.md\n" {
+		t.Errorf("restore printed %q", got)
+	}
+	h.mustRun(3, "restore", "2")
+	h.mustRun(0, "status")
+	if !strings.Contains(h.out.String(), "clean") {
+		t.Errorf("restored file is not clean:\n%s", h.out.String())
+	}
+	
+	h.mustRun(0, "prune", "--force")
+	for _, path := range []string{"issues/1.md", "issues/2.md"} {
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("prune --force kept %s", path)
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(model.IssueData{Title: "existing"})
+	
+	h.mustRun(0, "create", "New feature")
+	
+	if got := h.backend.Issue(2); got == nil || got.Title != "New feature" {
+		t.Fatalf("issue #2 = %+v", got)
+	}
+	abs, _ := filepath.Abs("issues/2.md")
+	if got := strings.TrimSpace(h.out.String()); got != abs {
+		t.Errorf("stdout = %q, want %q", got, abs)
+	}
+	if got := h.read("issues/2.md"); !strings.Contains(got, "title: New feature\n") {
+		t.Errorf("issues/2.md =\n%s", got)
+	}
+}
+
+func TestCloseAndReopen(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	
+	h.mustRun(0, "close", "1")
+	if got := h.backend.Issue(1).State; got != "CLOSED" {
+		t.Errorf("state after close = %q", got)
+	}
+	if got := h.out.String(); got != "Closed issue #1.\n" {
+		t.Errorf("stdout = %q", got)
+	}
+	
+	h.mustRun(0, "reopen", "1")
+	if got := h.backend.Issue(1).State; got != "OPEN" {
+		t.Errorf("state after reopen = %q", got)
+	}
+}
+
+func TestList(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(model.IssueData{Title: "one", Labels: []model.Label{{Name: "bug"}}})
+	h.backend.Add(model.IssueData{Title: "two"})
+	
+	h.mustRun(0, "list", "--", "--label", "bug")
+	if got, want := h.out.String(), "#1 one\nhttps://github.com/owner/repo/issues/1\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+	
+	// gh flags outside the supported set are rejected, not passed through.
+	h.mustRun(1, "list", "--", "--app", "dependabot")
+}
+
+func TestVerboseReportsQuota(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Quota = []backend.Quota{{Resource: "core", Limit: 5000, Remaining: 4990, Reset: time.Now()}}
+	
+	h.mustRun(0, "list")
+	if h.errOut.Len() != 0 {
+		t.Errorf("quota reported without --verbose: %q", h.errOut.String())
+	}
+	
+	h.mustRun(0, "list", "--verbose")
+	if !strings.HasPrefix(h.errOut.String(), "Rate limit (core): 4990 of 5000 remaining, resets at ") {
+		t.Errorf("stderr = %q", h.errOut.String())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.mustRun(0, "pull", "1")
+	
+	h.mustRun(0, "diff", "1")
+	if !strings.Contains(h.out.String(), "No differences") {
+		t.Errorf("stdout = %q", h.out.String())
+	}
+	
+	edited := strings.NewReplacer(
+		"title: Fix login", "title: Fix login redirect",
+		"  - bug", "  - bug\n  - ui",
+		"state: open", "state: closed",
+		"line two", "line 2",
+	).Replace(sampleFile)
+	h.write("issues/1.md", edited)
+	
+	h.mustRun(1, "diff", "1")
+	want := `--- remote #1
++++ issues/1.md
+title: "Fix login" -> "Fix login redirect"
+labels: added ui
+state: "open" -> "closed"
+@@ -1,3 +1,3 @@
+ line one
+-line two
++line 2
+ line three
+`
+	if h.out.String() != want {
+		t.Errorf("stdout =\n%s\nwant\n%s", h.out.String(), want)
+	}
+	
+	h.mustRun(1, "diff", "1", "--word")
+	if !strings.Contains(h.out.String(), "line [-two-]{+2+}\n") {
+		t.Errorf("word diff = %q", h.out.String())
+	}
+	
+	h.mustRun(1, "diff", "1", "--json")
+	var changes changeSet
+	if err := json.Unmarshal(h.out.Bytes(), &changes); err != nil {
+		t.Fatal(err)
+	}
+	if changes.Action != "differs" || len(changes.Fields) != 3 || changes.Fields[1].Field != "labels" || changes.Fields[1].Added[0] != "ui" || len(changes.Body) != 1 {
+		t.Errorf("change set = %s", h.out.String())
+	}
+	
+	if _, err := exec.LookPath("git"); err == nil {
+		h.mustRun(1, "diff", "1", "--", "--no-color")
+		if !strings.Contains(h.out.String(), "+line 2") || !strings.Contains(h.out.String(), "+title: Fix login redirect") {
+			t.Errorf("git diff = %q", h.out.String())
+		}
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(h *harness)
+		args  []string
+		want  model.ErrorType
+	}{
+		{
+			name: "success",
+			args: []string{"close", "1"},
+			want: 0,
+		},
+		{
+			name: "non-numeric issue number",
+			args: []string{"close", "abc"},
+			want: model.ExitUsage,
+		},
+		{
+			name: "unknown list option",
+			args: []string{"list", "--", "--bogus"},
+			want: model.ExitUsage,
+		},
+		{
+			name: "issue not found",
+			args: []string{"pull", "9"},
+			want: model.ExitEnv,
+		},
+		{
+			name: "backend unavailable",
+			setup: func(h *harness) {
+				h.backend.Fail["CloseIssue"] = errors.New("gh: command not found")
+			},
+			args: []string{"close", "1"},
+			want: model.ExitEnv,
+		},
+		{
+			name: "missing local file",
+			args: []string{"push", "1"},
+			want: model.ExitIO,
+		},
+		{
+			name: "malformed frontmatter",
+			setup: func(h *harness) {
+				h.write("issues/1.md", "---\ntitle: [unterminated\n---\n")
+			},
+			args: []string{"push", "1"},
+			want: model.ExitIO,
+		},
+		{
+			name: "invalid state",
+			setup: func(h *harness) {
+				h.write("issues/1.md", "---\ntitle: x\nstate: merged\n---\n")
+			},
+			args: []string{"push", "1"},
+			want: model.ExitIO,
+		},
+		{
+			name: "prune without issues directory",
+			args: []string{"prune"},
+			want: model.ExitIO,
+		},
+		{
+			name: "unresolved conflict markers",
+			setup: func(h *harness) {
+				h.write("issues/1.md", "---\ntitle: x\n---\n<<<<<<< local\na\n=======\nb\n>>>>>>> remote\n")
+			},
+			args: []string{"push", "1"},
+			want: model.ExitConflict,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			h.backend.Add(sampleIssue())
+			if tt.setup != nil {
+				tt.setup(h)
+			}
+			
+			code := h.run(tt.args...)
+			if code != int(tt.want) {
+				t.Errorf("exit %d, want %d\nstderr: %s", code, tt.want, h.errOut.String())
+			}
+			if code != 0 && h.errOut.Len() == 0 {
+				t.Errorf("no error message on stderr")
+			}
+		})
+	}
+}
\ No newline at end of file
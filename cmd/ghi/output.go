@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// Formats accepted by --output.
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+)
+
+// results collects the records of what a command did. Commands print their
+// human-readable text to a.out as usual; with --output json or jsonl, or a
+// --format template, that text is discarded and the records are printed
+// instead once the command has finished.
+type results struct {
+	mu      sync.Mutex
+	format  string
+	tmpl    *template.Template
+	out     io.Writer
+	list    bool
+	records []any
+}
+
+// issueResult records one issue or draft a command acted on. Action is what
+// happened to it: created, updated, unchanged, merged, kept, conflict,
+// closed, reopened, deleted, archived, restored, differs, saved, skipped,
+// not_found or failed.
+type issueResult struct {
+	Repo      string     `json:"repo,omitempty"`
+	Number    int        `json:"number,omitempty"`
+	Path      string     `json:"path,omitempty"`
+	Draft     string     `json:"draft,omitempty"`
+	Action    string     `json:"action"`
+	Conflicts []string   `json:"conflicts,omitempty"`
+	Error     *errorInfo `json:"error,omitempty"`
+	// DryRun marks the records of --dry-run: Action is what would happen
+	// and Mutations what would be sent or written.
+	DryRun    bool       `json:"dryRun,omitempty"`
+	Mutations []mutation `json:"mutations,omitempty"`
+}
+
+// errorInfo is an error as printed in structured output; Code is the exit
+// code it maps to.
+type errorInfo struct {
+	Code    model.ErrorType `json:"code"`
+	Message string          `json:"message"`
+	Repo    string          `json:"repo,omitempty"`
+}
+
+func newErrorInfo(err error) *errorInfo {
+	exitErr := &model.ExitError{Code: model.ExitIO, Err: err}
+	errors.As(err, &exitErr)
+	return &errorInfo{Code: exitErr.Code, Message: exitErr.Error()}
+}
+
+// setupOutput reads --output and --format. status --json is kept as a
+// shorthand for --output json.
+func (a *app) setupOutput(cmd *cobra.Command) error {
+	format, _ := cmd.Flags().GetString("output")
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		format = outputJSON
+	}
+	
+	r := &results{format: format}
+	switch format {
+	case outputText, outputJSON, outputJSONL:
+	default:
+		return model.NewUsageError(fmt.Sprintf("unknown output format %q: use text, json or jsonl", format))
+	}
+	
+	if text, _ := cmd.Flags().GetString("format"); text != "" {
+		tmpl, err := template.New("format").Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return model.NewUsageError(fmt.Sprintf("invalid --format template: %v", err))
+		}
+		r.tmpl = tmpl
+	}
+	
+	if r.structured() {
+		r.out = a.out
+		a.out = io.Discard
+		// Errors are printed as JSON by execute, without cobra's usage text.
+		cmd.Root().SilenceErrors = true
+		cmd.Root().SilenceUsage = true
+	}
+	a.results = r
+	return nil
+}
+
+func (r *results) structured() bool {
+	return r != nil && (r.format != outputText || r.tmpl != nil)
+}
+
+// listResults marks the command as one that reports any number of records,
+// so JSON output is an array even when it holds one record or none.
+func (a *app) listResults() {
+	if a.results != nil {
+		a.results.list = true
+	}
+}
+
+// emit records the result of a command. It is safe for concurrent use.
+func (a *app) emit(record any) {
+	if a.results == nil {
+		return
+	}
+	a.results.mu.Lock()
+	defer a.results.mu.Unlock()
+	a.results.records = append(a.results.records, record)
+}
+
+// result returns the record of an issue of the current repository.
+func (a *app) result(issueNumber string, path string, action string) issueResult {
+	n, _ := strconv.Atoi(issueNumber)
+	return issueResult{Repo: a.repo, Number: n, Path: path, Action: action}
+}
+
+// failedResult returns the record of an issue that could not be processed.
+func (a *app) failedResult(issueNumber string, path string, err error) issueResult {
+	r := a.result(issueNumber, path, "failed")
+	r.Error = newErrorInfo(err)
+	return r
+}
+
+// print writes the collected records in the selected format. Text
+// output has already been printed by the command.
+func (r *results) print() error {
+	if !r.structured() {
+		return nil
+	}
+	
+	value := any(r.records)
+	switch {
+	case r.list && r.records == nil:
+		value = []any{}
+	case !r.list && len(r.records) == 0:
+		return nil
+	case !r.list:
+		value = r.records[0]
+	}
+	
+	switch {
+	case r.tmpl != nil:
+		// Templates see the same field names as the JSON output.
+		data, err := toGeneric(value)
+		if err != nil {
+			return err
+		}
+		return r.tmpl.Execute(r.out, data)
+	case r.format == outputJSONL:
+		enc := json.NewEncoder(r.out)
+		for _, record := range r.records {
+			if err := enc.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	
+	enc := json.NewEncoder(r.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
+
+// printError reports a failed command, or a failed repository of a
+// workspace-wide command, on standard error. Structured output prints it as
+// a JSON object so scripts can read the exit code and message.
+func (a *app) printError(exitErr *model.ExitError, repo string) {
+	msg := exitErr.Error()
+	if msg == "" {
+		return
+	}
+	
+	if !a.results.structured() {
+		if repo != "" {
+			msg = repo + ": " + msg
+		}
+		fmt.Fprintln(a.errOut, msg)
+		return
+	}
+	
+	enc := json.NewEncoder(a.errOut)
+	if a.results.format == outputJSON {
+		enc.SetIndent("", "  ")
+	}
+	enc.Encode(map[string]*errorInfo{"error": {Code: exitErr.Code, Message: msg, Repo: repo}})
+}
+
+// toGeneric converts v to the maps and slices its JSON encoding decodes to.
+func toGeneric(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	err = json.Unmarshal(data, &generic)
+	return generic, err
+}
+
+// templateFuncs are available to --format templates in addition to the
+// text/template built-ins.
+var templateFuncs = template.FuncMap{
+	// join joins a list with sep: {{join ", " (pluck "name" .labels)}}.
+	"join": func(sep string, list []any) string {
+		parts := make([]string, len(list))
+		for i, v := range list {
+			parts[i] = fmt.Sprint(v)
+		}
+		return strings.Join(parts, sep)
+	},
+	// pluck picks one field from a list of objects.
+	"pluck": func(field string, list []any) []any {
+		values := make([]any, 0, len(list))
+		for _, v := range list {
+			if m, ok := v.(map[string]any); ok {
+				values = append(values, m[field])
+			}
+		}
+		return values
+	},
+	"json": func(v any) (string, error) {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	},
+}
\ No newline at end of file
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func TestListJSON(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	
+	h.mustRun(0, "list", "--output", "json")
+	
+	var items []model.IssueListItem
+	if err := json.Unmarshal(h.out.Bytes(), &items); err != nil {
+		t.Fatalf("invalid JSON %q: %v", h.out.String(), err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	item := items[0]
+	if item.Number != 1 || item.Title != "Fix login" || item.State != "OPEN" || item.URL == "" {
+		t.Errorf("item = %+v", item)
+	}
+	if len(item.Labels) != 1 || item.Labels[0].Name != "bug" || len(item.Assignees) != 1 || item.Assignees[0].Login != "octocat" {
+		t.Errorf("labels %v, assignees %v", item.Labels, item.Assignees)
+	}
+	if !strings.Contains(h.out.String(), `"updatedAt"`) || !strings.Contains(h.out.String(), `"author"`) {
+		t.Errorf("missing fields in %s", h.out.String())
+	}
+	
+	// An empty result is still an array.
+	h.backend.Update(1, func(issue *model.IssueData) { issue.State = "CLOSED" })
+	h.mustRun(0, "list", "-o", "json")
+	if got := strings.TrimSpace(h.out.String()); got != "[]" {
+		t.Errorf("empty list = %q, want []", got)
+	}
+}
+
+func TestFormatTemplate(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	second := sampleIssue()
+	second.Number = 2
+	second.Title = "Add logout"
+	second.Labels = []model.Label{{Name: "enhancement"}, {Name: "ui"}}
+	h.backend.Add(second)
+	
+	h.mustRun(0, "list", "--format", `{{range .}}{{.number}} {{.title}} [{{join "," (pluck "name" .labels)}}]{{"\n"}}{{end}}`)
+	
+	want := "2 Add logout [enhancement,ui]\n1 Fix login [bug]\n"
+	if h.out.String() != want {
+		t.Errorf("output = %q, want %q", h.out.String(), want)
+	}
+	
+	h.mustRun(int(model.ExitUsage), "list", "--format", "{{.number")
+}
+
+func TestPullJSONL(t *testing.T) {
+	h := newHarness(t)
+	for n := 1; n <= 2; n++ {
+		issue := sampleIssue()
+		issue.Number = n
+		h.backend.Add(issue)
+	}
+	
+	h.mustRun(int(model.ExitEnv), "pull", "1", "2", "9", "-o", "jsonl")
+	
+	lines := strings.Split(strings.TrimSpace(h.out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), h.out.String())
+	}
+	var results []issueResult
+	for _, line := range lines {
+		var r issueResult
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		results = append(results, r)
+	}
+	if results[0].Number != 1 || results[0].Action != "created" || results[0].Path != "issues/1.md" {
+		t.Errorf("first result = %+v", results[0])
+	}
+	if results[2].Number != 9 || results[2].Action != "not_found" {
+		t.Errorf("last result = %+v", results[2])
+	}
+	
+	var errOut struct {
+		Error errorInfo `json:"error"`
+	}
+	if err := json.Unmarshal(h.errOut.Bytes(), &errOut); err != nil {
+		t.Fatalf("invalid error JSON %q: %v", h.errOut.String(), err)
+	}
+	if errOut.Error.Code != model.ExitEnv || !strings.Contains(errOut.Error.Message, "not found") {
+		t.Errorf("error = %+v", errOut.Error)
+	}
+}
+
+func TestSingleResultJSON(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	
+	h.mustRun(0, "close", "1", "-o", "json")
+	
+	var r issueResult
+	if err := json.Unmarshal(h.out.Bytes(), &r); err != nil {
+		t.Fatalf("invalid JSON %q: %v", h.out.String(), err)
+	}
+	if r.Number != 1 || r.Action != "closed" {
+		t.Errorf("result = %+v", r)
+	}
+	
+	h.mustRun(0, "pull", "1", "-o", "json")
+	h.write("issues/1.md", strings.Replace(h.read("issues/1.md"), "line one", "edited", 1))
+	h.mustRun(0, "push", "1", "--format", "{{.action}} #{{.number}}")
+	if h.out.String() != "updated #1" {
+		t.Errorf("push output = %q", h.out.String())
+	}
+}
+
+func TestErrorJSON(t *testing.T) {
+	h := newHarness(t)
+	
+	h.mustRun(int(model.ExitEnv), "pull", "7", "-o", "json")
+	
+	if h.out.Len() != 0 {
+		t.Errorf("stdout = %q, want nothing", h.out.String())
+	}
+	var errOut struct {
+		Error errorInfo `json:"error"`
+	}
+	if err := json.Unmarshal(h.errOut.Bytes(), &errOut); err != nil {
+		t.Fatalf("invalid error JSON %q: %v", h.errOut.String(), err)
+	}
+	if errOut.Error.Code != model.ExitEnv || errOut.Error.Message == "" {
+		t.Errorf("error = %+v", errOut.Error)
+	}
+	
+	h.mustRun(int(model.ExitUsage), "list", "-o", "yaml")
+}
\ No newline at end of file
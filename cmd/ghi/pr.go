@@ -0,0 +1,479 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/filefmt"
+	"github.com/nomnel/ghi/internal/merge"
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// Pull request descriptions live in prs/<n>.md next to the issues directory,
+// with their own base snapshots in prs/.ghi/. They sync like issue files,
+// without comments and without the filename template.
+
+var prFileRegex = regexp.MustCompile(`^([0-9]+)\.md$`)
+
+// siblingDir returns a directory next to the issues directory, like prs/,
+// with the same per-repository layout in workspace mode.
+func (a *app) siblingDir(name string) string {
+	root := filepath.Join(filepath.Dir(a.cfg.IssuesDir), name)
+	if rel, err := filepath.Rel(a.cfg.IssuesDir, a.dir); err == nil && rel != "." {
+		return filepath.Join(root, rel)
+	}
+	return root
+}
+
+func (a *app) prDir() string {
+	return a.siblingDir("prs")
+}
+
+func (a *app) prPath(prNumber string) string {
+	return filepath.Join(a.prDir(), prNumber+".md")
+}
+
+// numberArgs validates the numbers given to a pr or discussion command.
+func numberArgs(args []string, usage string) error {
+	for _, arg := range args {
+		if !model.IsNumeric(arg) {
+			return model.NewUsageError(usage)
+		}
+	}
+	return nil
+}
+
+func (a *app) runPRPull(cmd *cobra.Command, args []string) error {
+	if err := numberArgs(args, "Usage: ghi pr pull <pr-number>..."); err != nil {
+		return err
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	
+	if err := os.MkdirAll(a.prDir(), 0o755); err != nil {
+		return model.NewIOError("failed to create pull requests directory", err)
+	}
+	if len(args) > 1 {
+		a.listResults()
+	}
+	
+	// Conflicts are reported after the remaining pull requests are pulled.
+	var conflict error
+	for _, prNumber := range args {
+		pr, err := a.client.ViewPR(prNumber)
+		if err != nil {
+			return backendError(err)
+		}
+		
+		outcome, conflicts, err := a.pullPR(pr, force)
+		if err != nil {
+			return err
+		}
+		
+		filePath := a.prPath(prNumber)
+		result := a.result(prNumber, filePath, outcome.String())
+		result.Conflicts = conflicts
+		a.emit(result)
+		
+		switch outcome {
+		case pullConflict:
+			if conflict == nil {
+				conflict = model.NewConflictError(fmt.Sprintf("Conflicts in %s (%s). Resolve them and run 'ghi pr push %s'.", filePath, strings.Join(conflicts, ", "), prNumber))
+			}
+			fmt.Fprintf(a.out, "Conflicts in %s (%s)\n", filePath, strings.Join(conflicts, ", "))
+		case pullMerged:
+			fmt.Fprintf(a.out, "Merged remote changes into %s\n", filePath)
+		case pullKept:
+			fmt.Fprintf(a.out, "Kept local changes in %s (remote unchanged)\n", filePath)
+		default:
+			fmt.Fprintf(a.out, "Saved to %s\n", filePath)
+		}
+	}
+	return conflict
+}
+
+// pullPR writes a fetched pull request to its file and records it as the new
+// base, merging local edits made since the last pull unless force is set.
+func (a *app) pullPR(pr *model.PRData, force bool) (pullOutcome, []string, error) {
+	prNumber := strconv.Itoa(pr.Number)
+	filePath := a.prPath(prNumber)
+	
+	bases := store.Open(a.prDir())
+	base, err := bases.LoadPR(prNumber)
+	if err != nil {
+		return 0, nil, model.NewIOError("failed to load base snapshot", err)
+	}
+	
+	existing, err := os.ReadFile(filePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, nil, model.NewIOError(fmt.Sprintf("failed to read %s", filePath), err)
+	}
+	
+	snap := pr.Snapshot()
+	outcome := pullUpdated
+	var conflicts []string
+	
+	switch {
+	case existing == nil:
+		outcome = pullCreated
+	case !force && base != nil:
+		local, err := readLocalPR(filePath)
+		if err != nil {
+			return 0, nil, model.NewIOError(fmt.Sprintf("failed to read %s (use --force to overwrite)", filePath), err)
+		}
+		if merge.PRModified(base.PRSnapshot, *local) {
+			if pr.UpdatedAt == base.UpdatedAt {
+				snap, outcome = *local, pullKept
+			} else {
+				res := merge.PR(base.PRSnapshot, *local, snap)
+				snap, conflicts, outcome = res.Snapshot, res.Conflicts, pullMerged
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		outcome = pullConflict
+	}
+	
+	content, err := encodeLocalPR(snap, existing)
+	if err != nil {
+		return 0, nil, err
+	}
+	if bytes.Equal(existing, content) {
+		if outcome == pullUpdated {
+			outcome = pullUnchanged
+		}
+	} else if err := filefmt.AtomicWriteFile(filePath, content, 0o644); err != nil {
+		return 0, nil, model.NewIOError("failed to write file", err)
+	}
+	
+	if err := bases.SavePR(&store.PRBase{Number: prNumber, UpdatedAt: pr.UpdatedAt, PRSnapshot: pr.Snapshot()}); err != nil {
+		return 0, nil, model.NewIOError("failed to save base snapshot", err)
+	}
+	
+	return outcome, conflicts, nil
+}
+
+func (a *app) runPRPush(cmd *cobra.Command, args []string) error {
+	if err := numberArgs(args, "Usage: ghi pr push <pr-number>..."); err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		a.listResults()
+	}
+	
+	for _, prNumber := range args {
+		filePath := a.prPath(prNumber)
+		outcome, conflicts, err := a.pushPR(prNumber)
+		if err != nil {
+			return err
+		}
+		
+		result := a.result(prNumber, filePath, outcome.String())
+		result.Conflicts = conflicts
+		a.emit(result)
+		
+		if outcome == pushConflict {
+			return model.NewConflictError(fmt.Sprintf("Conflicts in %s (%s). Resolve them and run 'ghi pr push %s'.", filePath, strings.Join(conflicts, ", "), prNumber))
+		}
+		if outcome == pushMerged {
+			fmt.Fprintf(a.out, "Merged remote changes into %s\n", filePath)
+		}
+		fmt.Fprintf(a.out, "Updated pull request #%s from %s\n", prNumber, filePath)
+	}
+	return nil
+}
+
+// pushPR updates the remote pull request from its file: title, body, base
+// branch, labels, reviewers and draft state. Remote changes made since the
+// last pull are merged into the file first, as pushIssue does.
+func (a *app) pushPR(prNumber string) (pushOutcome, []string, error) {
+	filePath := a.prPath(prNumber)
+	
+	local, err := readLocalPR(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil, model.NewIOError(fmt.Sprintf("%s not found. Run 'ghi pr pull %s' first", filePath, prNumber), nil)
+		}
+		if errors.Is(err, model.ErrMalformedFrontmatter) {
+			return 0, nil, model.NewIOError(fmt.Sprintf("Invalid frontmatter in %s", filePath), err)
+		}
+		return 0, nil, model.NewIOError("failed to read file", err)
+	}
+	
+	if merge.HasConflictMarkers(local.Body) {
+		return 0, nil, model.NewConflictError(fmt.Sprintf("%s has unresolved conflict markers. Resolve them and run 'ghi pr push %s' again.", filePath, prNumber))
+	}
+	
+	remote, err := a.client.ViewPR(prNumber)
+	if err != nil {
+		return 0, nil, backendError(err)
+	}
+	if head := local.Frontmatter.Head; head != "" && head != remote.HeadRefName {
+		return 0, nil, model.NewIOError(fmt.Sprintf("Invalid frontmatter in %s: the head branch of a pull request cannot be changed (it is %q)", filePath, remote.HeadRefName), nil)
+	}
+	
+	bases := store.Open(a.prDir())
+	base, err := bases.LoadPR(prNumber)
+	if err != nil {
+		return 0, nil, model.NewIOError("failed to load base snapshot", err)
+	}
+	
+	outcome := pushUpdated
+	if base != nil && remote.UpdatedAt != base.UpdatedAt {
+		res := merge.PR(base.PRSnapshot, *local, remote.Snapshot())
+		if len(res.Conflicts) > 0 || !reflect.DeepEqual(res.Snapshot, *local) {
+			if err := writeLocalPR(filePath, res.Snapshot); err != nil {
+				return 0, nil, err
+			}
+		}
+		if len(res.Conflicts) > 0 {
+			if err := bases.SavePR(&store.PRBase{Number: prNumber, UpdatedAt: remote.UpdatedAt, PRSnapshot: remote.Snapshot()}); err != nil {
+				return 0, nil, model.NewIOError("failed to save base snapshot", err)
+			}
+			return pushConflict, res.Conflicts, nil
+		}
+		if !reflect.DeepEqual(res.Snapshot, *local) {
+			outcome = pushMerged
+		}
+		local = &res.Snapshot
+	}
+	
+	if err := a.client.EditPR(prNumber, model.NewPREdit(local.Frontmatter, local.Body, remote)); err != nil {
+		return 0, nil, backendError(err)
+	}
+	
+	// As for issues, the remote updatedAt is only trusted if nobody else
+	// edited the pull request in the meantime.
+	newBase := &store.PRBase{Number: prNumber, PRSnapshot: *local}
+	if after, err := a.client.ViewPR(prNumber); err == nil && !merge.PRModified(after.Snapshot(), *local) {
+		newBase = &store.PRBase{Number: prNumber, UpdatedAt: after.UpdatedAt, PRSnapshot: after.Snapshot()}
+	}
+	if err := bases.SavePR(newBase); err != nil {
+		return 0, nil, model.NewIOError("failed to save base snapshot", err)
+	}
+	
+	return outcome, nil, nil
+}
+
+func (a *app) runPRDiff(cmd *cobra.Command, args []string) error {
+	prNumber := args[0]
+	if !model.IsNumeric(prNumber) {
+		return model.NewUsageError("Usage: ghi pr diff <pr-number> [--json] [--word] [--git [--] [EXTRA_GIT_DIFF_ARGS...]]")
+	}
+	extraArgs := args[1:]
+	if i := slices.Index(extraArgs, "--"); i >= 0 {
+		extraArgs = extraArgs[i+1:]
+	}
+	opts := diffFlags(cmd, extraArgs)
+	
+	localPath := a.prPath(prNumber)
+	localContent, err := os.ReadFile(localPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return model.NewIOError(fmt.Sprintf("%s not found. Run 'ghi pr pull %s' first.", localPath, prNumber), nil)
+		}
+		return model.NewIOError("failed to check local file", err)
+	}
+	
+	pr, err := a.client.ViewPR(prNumber)
+	if err != nil {
+		return backendError(err)
+	}
+	
+	var changes *changeSet
+	if opts.git {
+		remote, err := filefmt.UpdatePRMarkdown(localContent, pr.Frontmatter(), []byte(pr.Body))
+		if err != nil {
+			return model.NewIOError("failed to encode remote markdown", err)
+		}
+		if changes, err = a.gitDiff(filepath.Join(a.prDir(), "tmp"), prNumber, remote, localPath, opts.gitArgs); err != nil {
+			return err
+		}
+	} else {
+		local, err := readLocalPR(localPath)
+		if err != nil {
+			return model.NewIOError(fmt.Sprintf("Invalid frontmatter in %s", localPath), err)
+		}
+		changes = a.newChangeSet(prNumber, localPath, prChanges(pr.Frontmatter(), local.Frontmatter), pr.Body, local.Body)
+		a.printChanges(changes, "remote pull request #"+prNumber, opts.words)
+	}
+	
+	a.emit(changes)
+	if changes.differs() {
+		// Differences are a result, not a misuse of the command.
+		cmd.SilenceUsage = true
+		return &model.ExitError{Code: 1}
+	}
+	
+	fmt.Fprintf(a.out, "No differences: %s matches remote.\n", localPath)
+	return nil
+}
+
+func (a *app) runPRStatus(cmd *cobra.Command, args []string) error {
+	entries, err := a.prStatusEntries()
+	if err != nil {
+		return err
+	}
+	
+	a.listResults()
+	for _, e := range entries {
+		a.emit(e)
+	}
+	
+	if len(entries) == 0 {
+		fmt.Fprintf(a.out, "No pull request files in %s\n", a.prDir())
+		return nil
+	}
+	
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		detail := e.Title
+		if e.Error != "" {
+			detail = e.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", e.Path, e.Status, detail)
+	}
+	return w.Flush()
+}
+
+// prStatusEntries classifies every pull request file against its base and
+// the remote.
+func (a *app) prStatusEntries() ([]statusEntry, error) {
+	numbers, err := a.localPRNumbers()
+	if err != nil {
+		return nil, model.NewIOError("failed to read pull requests directory", err)
+	}
+	
+	bases := store.Open(a.prDir())
+	entries := []statusEntry{}
+	for _, prNumber := range numbers {
+		entry := statusEntry{Repo: a.repo, Path: a.prPath(prNumber)}
+		entry.Number, _ = strconv.Atoi(prNumber)
+		
+		local, err := readLocalPR(entry.Path)
+		if err != nil {
+			entry.Status = statusInvalid
+			entry.Error = err.Error()
+			entries = append(entries, entry)
+			continue
+		}
+		entry.Title = local.Frontmatter.Title
+		
+		base, err := bases.LoadPR(prNumber)
+		if err != nil {
+			return nil, model.NewIOError("failed to load base snapshot", err)
+		}
+		remote, err := a.client.ViewPR(prNumber)
+		if err != nil && !backend.IsKind(err, backend.KindNotFound) {
+			return nil, backendError(err)
+		}
+		
+		entry.Status = classifyPR(local, base, remote)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// classifyPR decides the status of one pull request file, like classify.
+func classifyPR(local *model.PRSnapshot, base *store.PRBase, remote *model.PRData) fileStatus {
+	switch {
+	case remote == nil:
+		return statusMissingRemotely
+	case remote.State == "MERGED":
+		return statusMergedRemotely
+	case remote.State == "CLOSED":
+		return statusClosedRemotely
+	}
+	
+	if base == nil {
+		if merge.PRModified(remote.Snapshot(), *local) {
+			return statusLocalModified
+		}
+		return statusClean
+	}
+	
+	localChanged := merge.PRModified(base.PRSnapshot, *local)
+	remoteChanged := remote.UpdatedAt != base.UpdatedAt && !reflect.DeepEqual(base.PRSnapshot, remote.Snapshot())
+	
+	switch {
+	case localChanged && remoteChanged:
+		return statusBothModified
+	case localChanged:
+		return statusLocalModified
+	case remoteChanged:
+		return statusRemoteModified
+	}
+	return statusClean
+}
+
+// localPRNumbers returns the numbers of all pull request files, in ascending
+// numeric order.
+func (a *app) localPRNumbers() ([]string, error) {
+	entries, err := os.ReadDir(a.prDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var numbers []string
+	for _, entry := range entries {
+		if m := prFileRegex.FindStringSubmatch(entry.Name()); m != nil && !entry.IsDir() {
+			numbers = append(numbers, m[1])
+		}
+	}
+	slices.SortFunc(numbers, func(x, y string) int {
+		a, _ := strconv.Atoi(x)
+		b, _ := strconv.Atoi(y)
+		return a - b
+	})
+	return numbers, nil
+}
+
+func readLocalPR(path string) (*model.PRSnapshot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	
+	fm, body, err := filefmt.DecodePRMarkdown(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PRSnapshot{Frontmatter: *fm, Body: string(body)}, nil
+}
+
+// encodeLocalPR encodes a pull request file on top of its previous content,
+// so keys ghi does not manage survive.
+func encodeLocalPR(snap model.PRSnapshot, prev []byte) ([]byte, error) {
+	content, err := filefmt.UpdatePRMarkdown(prev, snap.Frontmatter, []byte(snap.Body))
+	if err != nil {
+		return nil, model.NewIOError("failed to encode markdown", err)
+	}
+	return content, nil
+}
+
+func writeLocalPR(path string, snap model.PRSnapshot) error {
+	prev, _ := os.ReadFile(path)
+	content, err := encodeLocalPR(snap, prev)
+	if err != nil {
+		return err
+	}
+	if err := filefmt.AtomicWriteFile(path, content, 0o644); err != nil {
+		return model.NewIOError("failed to write file", err)
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func samplePR() model.PRData {
+	return model.PRData{
+		Number:         7,
+		Title:          "Add dark mode",
+		Body:           "Adds a dark theme.\n",
+		IsDraft:        true,
+		BaseRefName:    "main",
+		HeadRefName:    "dark-mode",
+		Labels:         []model.Label{{Name: "ui"}},
+		ReviewRequests: []model.Reviewer{{Login: "alice"}},
+	}
+}
+
+const samplePRFile = `---
+title: Add dark mode
+base: main
+head: dark-mode
+draft: true
+labels:
+  - ui
+reviewers:
+  - alice
+---
+Adds a dark theme.
+`
+
+func TestPRPullPush(t *testing.T) {
+	h := newHarness(t)
+	h.backend.AddPR(samplePR())
+	
+	h.mustRun(0, "pr", "pull", "7")
+	if got := h.read("prs/7.md"); got != samplePRFile {
+		t.Errorf("prs/7.md =\n%s\nwant\n%s", got, samplePRFile)
+	}
+	if _, err := os.Stat("prs/.ghi/base/7.json"); err != nil {
+		t.Errorf("base snapshot not saved: %v", err)
+	}
+	
+	h.mustRun(0, "pr", "status")
+	if !strings.Contains(h.out.String(), "prs/7.md  clean") {
+		t.Errorf("status = %q", h.out.String())
+	}
+	
+	edited := strings.NewReplacer(
+		"title: Add dark mode", "title: Add a dark theme",
+		"draft: true\n", "",
+		"  - alice", "  - bob",
+		"Adds a dark theme.", "Adds a dark theme.\n\nCloses #3.",
+	).Replace(samplePRFile)
+	h.write("prs/7.md", edited)
+	
+	h.mustRun(0, "pr", "status")
+	if !strings.Contains(h.out.String(), "locally modified") {
+		t.Errorf("status after edit = %q", h.out.String())
+	}
+	
+	h.mustRun(1, "pr", "diff", "7")
+	if !strings.Contains(h.out.String(), "draft: \"true\" -> \"false\"\nreviewers: added bob; removed alice\n") {
+		t.Errorf("diff = %q", h.out.String())
+	}
+	
+	h.mustRun(0, "pr", "push", "7")
+	if h.out.String() != "Updated pull request #7 from prs/7.md\n" {
+		t.Errorf("stdout = %q", h.out.String())
+	}
+	pr := h.backend.PR(7)
+	if pr.Title != "Add a dark theme" || pr.Body != "Adds a dark theme.\n\nCloses #3.\n" || pr.IsDraft {
+		t.Errorf("remote = %+v", pr)
+	}
+	if fm := pr.Frontmatter(); !slices.Equal(fm.Reviewers, []string{"bob"}) || fm.Base != "main" {
+		t.Errorf("remote frontmatter = %+v", fm)
+	}
+	
+	h.mustRun(0, "pr", "status", "-o", "json")
+	var entries []statusEntry
+	if err := json.Unmarshal(h.out.Bytes(), &entries); err != nil || len(entries) != 1 || entries[0].Status != statusClean {
+		t.Errorf("status after push = %s (%v)", h.out.String(), err)
+	}
+}
+
+func TestPRPullMergesRemoteChanges(t *testing.T) {
+	h := newHarness(t)
+	h.backend.AddPR(samplePR())
+	h.mustRun(0, "pr", "pull", "7")
+	
+	h.write("prs/7.md", strings.Replace(samplePRFile, "Adds a dark theme.\n", "Adds a dark theme.\n\nScreenshots below.\n", 1))
+	h.backend.UpdatePR(7, func(pr *model.PRData) {
+		pr.IsDraft = false
+		pr.ReviewRequests = append(pr.ReviewRequests, model.Reviewer{Login: "octo/design"})
+	})
+	
+	h.mustRun(0, "pr", "status")
+	if !strings.Contains(h.out.String(), "both modified") {
+		t.Errorf("status = %q", h.out.String())
+	}
+	
+	h.mustRun(0, "pr", "pull", "7")
+	want := strings.NewReplacer(
+		"draft: true\n", "",
+		"  - alice\n", "  - alice\n  - octo/design\n",
+		"Adds a dark theme.\n", "Adds a dark theme.\n\nScreenshots below.\n",
+	).Replace(samplePRFile)
+	if got := h.read("prs/7.md"); got != want {
+		t.Errorf("prs/7.md =\n%s\nwant\n%s", got, want)
+	}
+	
+	h.backend.UpdatePR(7, func(pr *model.PRData) { pr.State = "MERGED" })
+	h.mustRun(0, "pr", "status")
+	if !strings.Contains(h.out.String(), "merged remotely") {
+		t.Errorf("status of merged pull request = %q", h.out.String())
+	}
+}
+
+func TestPRPushRejectsHeadChange(t *testing.T) {
+	h := newHarness(t)
+	h.backend.AddPR(samplePR())
+	h.mustRun(0, "pr", "pull", "7")
+	
+	h.write("prs/7.md", strings.Replace(samplePRFile, "head: dark-mode", "head: other", 1))
+	h.mustRun(int(model.ExitIO), "pr", "push", "7")
+	if slices.Contains(h.backend.Calls, "EditPR 7") {
+		t.Error("pull request was edited")
+	}
+	
+	h.mustRun(int(model.ExitUsage), "pr", "pull", "abc")
+}
\ No newline at end of file
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// closedDir holds the files prune --archive moves out of the way, relative
+// to the issues directory. restore moves them back.
+const closedDir = "closed"
+
+// pruneOptions are the flags of prune.
+type pruneOptions struct {
+	// archive moves files to closedDir instead of deleting them.
+	archive bool
+	// force also prunes files edited since they were last pulled.
+	force bool
+}
+
+func (a *app) runPrune(cmd *cobra.Command, args []string) error {
+	var opts pruneOptions
+	opts.archive, _ = cmd.Flags().GetBool("archive")
+	opts.force, _ = cmd.Flags().GetBool("force")
+	
+	a.listResults()
+	if err := a.eachRepo(func(r *app) error { return r.prune(opts) }); err != nil {
+		return err
+	}
+	if a.dryRun {
+		fmt.Fprintln(a.out, dryRunNote)
+	}
+	return nil
+}
+
+// prune deletes or archives the files of closed issues. Files with local
+// edits since the last pull, or with no base to tell, are kept unless forced,
+// so unpushed work is not lost.
+func (a *app) prune(opts pruneOptions) error {
+	// Check if issues directory exists
+	if _, err := os.Stat(a.dir); os.IsNotExist(err) {
+		return model.NewIOError(fmt.Sprintf("%s directory does not exist", a.dir), nil)
+	}
+	
+	files, err := a.localIssueFiles()
+	if err != nil {
+		return model.NewIOError("failed to read issues directory", err)
+	}
+	numbers, err := a.localIssueNumbers()
+	if err != nil {
+		return model.NewIOError("failed to read issues directory", err)
+	}
+	
+	// Look up the local files' issues rather than listing closed issues,
+	// which would have to page through the whole repository history.
+	issues, err := a.client.ViewIssues(numbers)
+	if err != nil {
+		return model.NewEnvError("failed to look up local issues", err)
+	}
+	
+	bases := store.Open(a.dir)
+	pruned, skipped, unknown := 0, 0, 0
+	for _, issueNumber := range numbers {
+		issue, ok := issues[issueNumber]
+		if !ok || !strings.EqualFold(issue.State, "closed") {
+			continue
+		}
+		filePath := files[issueNumber]
+		
+		if !opts.force {
+			base, err := bases.Load(issueNumber)
+			if err != nil {
+				return model.NewIOError("failed to load base snapshot", err)
+			}
+			if base == nil {
+				// Without a base there is no telling whether the file was edited.
+				a.emit(a.result(issueNumber, filePath, "skipped"))
+				fmt.Fprintf(a.errOut, "Skipping %s: no record of the last pull. Run 'ghi prune --force' to prune it anyway.\n", filePath)
+				unknown++
+				continue
+			}
+			modified, err := a.locallyModified(bases, issueNumber)
+			if err != nil {
+				return err
+			}
+			if modified {
+				a.emit(a.result(issueNumber, filePath, "skipped"))
+				fmt.Fprintf(a.out, "Skipped %s: edited since the last pull\n", filePath)
+				skipped++
+				continue
+			}
+		}
+		
+		if err := a.pruneFile(issueNumber, filePath, opts.archive); err != nil {
+			return err
+		}
+		pruned++
+	}
+	
+	// Delete tmp directory if it exists
+	tmpDir := filepath.Join(a.dir, "tmp")
+	if _, err := os.Stat(tmpDir); err == nil {
+		if a.dryRun {
+			mutation{Op: "delete_file", Path: tmpDir}.write(a.out)
+		} else {
+			if err := os.RemoveAll(tmpDir); err != nil {
+				return model.NewIOError("failed to delete tmp directory", err)
+			}
+			fmt.Fprintf(a.out, "Deleted %s\n", tmpDir)
+		}
+	}
+	
+	verb := "Deleted"
+	switch {
+	case a.dryRun && opts.archive:
+		verb = "Would archive"
+	case a.dryRun:
+		verb = "Would delete"
+	case opts.archive:
+		verb = "Archived"
+	}
+	summary := fmt.Sprintf("%s %d closed issue file(s)", verb, pruned)
+	var kept []string
+	if skipped > 0 {
+		kept = append(kept, fmt.Sprintf("%d with local edits", skipped))
+	}
+	if unknown > 0 {
+		kept = append(kept, fmt.Sprintf("%d with no record of the last pull", unknown))
+	}
+	if kept != nil {
+		summary += fmt.Sprintf("; skipped %s (use --force to prune them)", strings.Join(kept, " and "))
+	}
+	fmt.Fprintln(a.out, summary)
+	return nil
+}
+
+// pruneFile deletes the file of a closed issue, or moves it to closedDir.
+// The base snapshot is kept so a restored file is still in sync.
+func (a *app) pruneFile(issueNumber string, filePath string, archive bool) error {
+	if !archive {
+		if a.dryRun {
+			a.planned(a.result(issueNumber, filePath, "deleted"), []mutation{{Op: "delete_file", Path: filePath}})
+			return nil
+		}
+		if err := os.Remove(filePath); err != nil {
+			return model.NewIOError(fmt.Sprintf("failed to delete %s", filePath), err)
+		}
+		a.files.track(issueNumber, "")
+		a.emit(a.result(issueNumber, filePath, "deleted"))
+		fmt.Fprintf(a.out, "Deleted %s\n", filePath)
+		return nil
+	}
+	
+	target := filepath.Join(a.dir, closedDir, filepath.Base(filePath))
+	if a.dryRun {
+		a.planned(a.result(issueNumber, target, "archived"), []mutation{{Op: "move_file", Path: filePath, To: target}})
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return model.NewIOError("failed to create archive directory", err)
+	}
+	if err := os.Rename(filePath, target); err != nil {
+		return model.NewIOError(fmt.Sprintf("failed to archive %s", filePath), err)
+	}
+	a.files.track(issueNumber, "")
+	a.emit(a.result(issueNumber, target, "archived"))
+	fmt.Fprintf(a.out, "Archived %s -> %s\n", filePath, target)
+	return nil
+}
+
+// runRestore moves archived issue files back into the issues directory.
+func (a *app) runRestore(cmd *cobra.Command, args []string) error {
+	const usage = "Usage: ghi restore <issue-number>|<from>-<to>..."
+	
+	numbers, err := parseIssueArgs(args)
+	if err != nil {
+		return model.NewUsageError(fmt.Sprintf("%v\n%s", err, usage))
+	}
+	
+	archived, err := a.issueFilesIn(filepath.Join(a.dir, closedDir))
+	if err != nil && !os.IsNotExist(err) {
+		return model.NewIOError("failed to read archive directory", err)
+	}
+	files, err := a.localIssueFiles()
+	if err != nil && !os.IsNotExist(err) {
+		return model.NewIOError("failed to read issues directory", err)
+	}
+	
+	// Numbers named on their own must be archived; those that only fall in a
+	// range are skipped. Everything is checked before any file is moved.
+	named := map[string]bool{}
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil {
+			named[strconv.Itoa(n)] = true
+		}
+	}
+	closed := filepath.Join(a.dir, closedDir)
+	var restore, missing []string
+	for _, n := range numbers {
+		archivedPath, ok := archived[n]
+		if !ok {
+			if named[n] {
+				return model.NewIOError(fmt.Sprintf("Issue #%s is not archived in %s", n, closed), nil)
+			}
+			missing = append(missing, n)
+			continue
+		}
+		if existing, ok := files[n]; ok {
+			return model.NewIOError(fmt.Sprintf("Cannot restore %s: %s already exists", archivedPath, existing), nil)
+		}
+		restore = append(restore, n)
+	}
+	
+	a.listResults()
+	for _, n := range restore {
+		archivedPath := archived[n]
+		target := filepath.Join(a.dir, filepath.Base(archivedPath))
+		if err := os.Rename(archivedPath, target); err != nil {
+			return model.NewIOError(fmt.Sprintf("failed to restore %s", archivedPath), err)
+		}
+		a.files.track(n, target)
+		a.emit(a.result(n, target, "restored"))
+		fmt.Fprintf(a.out, "Restored %s -> %s\n", archivedPath, target)
+	}
+	for _, n := range missing {
+		a.emit(a.result(n, "", "not_found"))
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(a.errOut, "Not archived in %s: #%s\n", closed, strings.Join(missing, " #"))
+	}
+	return nil
+}
\ No newline at end of file
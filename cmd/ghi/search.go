@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/search"
+	"github.com/spf13/cobra"
+)
+
+// snippetWidth is roughly how many bytes of the body a result shows.
+const snippetWidth = 160
+
+type searchResult struct {
+	Repo    string         `json:"repo,omitempty"`
+	Number  int            `json:"number"`
+	Path    string         `json:"path"`
+	Title   string         `json:"title"`
+	State   string         `json:"state,omitempty"`
+	Labels  []string       `json:"labels,omitempty"`
+	Score   float64        `json:"score"`
+	Snippet search.Snippet `json:"snippet"`
+}
+
+func (a *app) runSearch(cmd *cobra.Command, args []string) error {
+	query, err := search.Parse(strings.Join(args, " "))
+	if err != nil {
+		return model.NewUsageError(fmt.Sprintf("invalid query: %v", err))
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	
+	a.listResults()
+	return a.eachRepo(func(r *app) error { return r.search(query, limit) })
+}
+
+// search runs a query against the repository's files, updating the index
+// with the files that changed since the last search first.
+func (a *app) search(query search.Query, limit int) error {
+	files, err := a.localIssueFiles()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return model.NewIOError(fmt.Sprintf("%s directory does not exist", a.dir), nil)
+		}
+		return model.NewIOError("failed to read issues directory", err)
+	}
+	
+	ix := search.Open(search.Path(a.dir))
+	if _, err := ix.Update(files); err != nil {
+		return model.NewIOError("failed to index issue files", err)
+	}
+	// A stale index only costs time on the next search.
+	if err := ix.Save(); err != nil {
+		fmt.Fprintf(a.errOut, "Warning: failed to save search index: %v\n", err)
+	}
+	
+	results := ix.Search(query)
+	if len(results) == 0 {
+		fmt.Fprintln(a.out, "No matching issues")
+		return nil
+	}
+	shown := results
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	
+	terms := query.Terms()
+	open, close := a.highlightMarks()
+	for i, res := range shown {
+		_, body, _ := search.ReadFile(res.Path)
+		r := searchResult{
+			Repo:    a.repo,
+			Number:  res.Number,
+			Path:    res.Path,
+			Title:   res.Title,
+			State:   res.State,
+			Labels:  res.Labels,
+			Score:   res.Score,
+			Snippet: search.MakeSnippet(body, terms, snippetWidth),
+		}
+		a.emit(r)
+		
+		if i > 0 {
+			fmt.Fprintln(a.out)
+		}
+		title := search.Highlight(res.Title, terms).Mark(open, close)
+		if strings.EqualFold(res.State, "closed") {
+			title += " (closed)"
+		}
+		fmt.Fprintf(a.out, "#%d %s  %s\n", res.Number, title, res.Path)
+		if r.Snippet.Text != "" {
+			fmt.Fprintf(a.out, "    %s\n", r.Snippet.Mark(open, close))
+		}
+	}
+	
+	if len(shown) < len(results) {
+		fmt.Fprintf(a.out, "\nShowing %d of %d matching issues\n", len(shown), len(results))
+	}
+	return nil
+}
+
+// highlightMarks returns what surrounds matched words in text output: bold
+// on a terminal, and markdown bold otherwise.
+func (a *app) highlightMarks() (string, string) {
+	if f, ok := a.out.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			return "\x1b[1m", "\x1b[0m"
+		}
+	}
+	return "**", "**"
+}
\ No newline at end of file
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func TestSearch(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(sampleIssue())
+	h.backend.Add(model.IssueData{Title: "Dark mode", Body: "Make the login screen dark.\n", Labels: []model.Label{{Name: "ui"}}})
+	h.mustRun(0, "pull", "1", "2")
+	
+	h.backend.Calls = nil
+	h.mustRun(0, "search", "login")
+	want := "#1 Fix **login**  issues/1.md\n    line one line two line three\n\n#2 Dark mode  issues/2.md\n    Make the **login** screen dark.\n"
+	if h.out.String() != want {
+		t.Errorf("output = %q, want %q", h.out.String(), want)
+	}
+	if len(h.backend.Calls) != 0 {
+		t.Errorf("search called the backend: %v", h.backend.Calls)
+	}
+	if _, err := os.Stat("issues/.ghi/search/index.gob"); err != nil {
+		t.Errorf("index not saved: %v", err)
+	}
+	
+	// Edits are picked up by the next search.
+	h.write("issues/2.md", strings.Replace(h.read("issues/2.md"), "login", "settings", 1))
+	h.mustRun(0, "search", "login", "-o", "json")
+	var results []searchResult
+	if err := json.Unmarshal(h.out.Bytes(), &results); err != nil {
+		t.Fatalf("invalid JSON %q: %v", h.out.String(), err)
+	}
+	if len(results) != 1 || results[0].Number != 1 || results[0].Title != "Fix login" {
+		t.Errorf("results = %+v", results)
+	}
+	
+	h.mustRun(0, "search", "label:ui", "NOT", "login")
+	if !strings.HasPrefix(h.out.String(), "#2 Dark mode") {
+		t.Errorf("filtered output = %q", h.out.String())
+	}
+	
+	h.mustRun(0, "search", "nothing")
+	if h.out.String() != "No matching issues\n" {
+		t.Errorf("output without matches = %q", h.out.String())
+	}
+	
+	h.mustRun(int(model.ExitUsage), "search", `"unterminated`)
+}
\ No newline at end of file
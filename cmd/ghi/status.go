@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nomnel/ghi/internal/merge"
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// fileStatus classifies a local issue file against its base and the remote.
+type fileStatus string
+
+const (
+	statusClean           fileStatus = "clean"
+	statusLocalModified   fileStatus = "locally_modified"
+	statusRemoteModified  fileStatus = "remotely_modified"
+	statusBothModified    fileStatus = "both_modified"
+	statusClosedRemotely  fileStatus = "closed_remotely"
+	statusMergedRemotely  fileStatus = "merged_remotely"
+	statusMissingRemotely fileStatus = "missing_remotely"
+	statusInvalid         fileStatus = "invalid"
+)
+
+func (s fileStatus) String() string {
+	return strings.ReplaceAll(string(s), "_", " ")
+}
+
+type statusEntry struct {
+	Repo   string     `json:"repo,omitempty"`
+	Number int        `json:"number"`
+	Path   string     `json:"path"`
+	Status fileStatus `json:"status"`
+	Title  string     `json:"title,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+func (a *app) runStatus(cmd *cobra.Command, args []string) error {
+	repos, err := a.repos()
+	if err != nil {
+		return err
+	}
+	
+	entries := []statusEntry{}
+	for _, r := range repos {
+		repoEntries, err := r.statusEntries()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, repoEntries...)
+	}
+	
+	a.listResults()
+	for _, e := range entries {
+		a.emit(e)
+	}
+	
+	if len(entries) == 0 {
+		fmt.Fprintf(a.out, "No issue files in %s\n", a.cfg.IssuesDir)
+		return nil
+	}
+	
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		detail := e.Title
+		if e.Error != "" {
+			detail = e.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", e.Path, e.Status, detail)
+	}
+	return w.Flush()
+}
+
+// statusEntries classifies every issue file of one repository, fetching the
+// remote issues in one batch.
+func (a *app) statusEntries() ([]statusEntry, error) {
+	if _, err := os.Stat(a.dir); os.IsNotExist(err) {
+		return nil, model.NewIOError(fmt.Sprintf("%s directory does not exist", a.dir), nil)
+	}
+	
+	numbers, err := a.localIssueNumbers()
+	if err != nil {
+		return nil, model.NewIOError("failed to read issues directory", err)
+	}
+	
+	remotes, err := a.client.ViewIssues(numbers)
+	if err != nil {
+		return nil, backendError(err)
+	}
+	
+	bases := store.Open(a.dir)
+	entries := make([]statusEntry, 0, len(numbers))
+	
+	for _, issueNumber := range numbers {
+		entry := statusEntry{Repo: a.repo, Path: a.issuePath(issueNumber, "")}
+		entry.Number, _ = strconv.Atoi(issueNumber)
+		
+		base, err := bases.Load(issueNumber)
+		if err != nil {
+			return nil, model.NewIOError("failed to load base snapshot", err)
+		}
+		
+		local, err := a.readLocal(entry.Path, base)
+		if err != nil {
+			entry.Status = statusInvalid
+			entry.Error = err.Error()
+			entries = append(entries, entry)
+			continue
+		}
+		entry.Title = local.Frontmatter.Title
+		
+		entry.Status = classify(local, base, remotes[issueNumber])
+		entries = append(entries, entry)
+	}
+	
+	return entries, nil
+}
+
+// classify decides the status of one file. Without a base the file is
+// compared to the remote directly, and any difference counts as a local edit
+// because that is what push would send.
+func classify(local *localIssue, base *store.Base, remote *model.IssueData) fileStatus {
+	if remote == nil {
+		return statusMissingRemotely
+	}
+	
+	if strings.EqualFold(remote.State, "closed") && !strings.EqualFold(local.Frontmatter.State, "closed") {
+		return statusClosedRemotely
+	}
+	
+	if base == nil {
+		if merge.Modified(remote.Snapshot(), local.Snapshot) || merge.CommentsModified(nil, local.Comments) {
+			return statusLocalModified
+		}
+		return statusClean
+	}
+	
+	localChanged := merge.Modified(base.Snapshot, local.Snapshot) || merge.CommentsModified(base.Comments, local.Comments)
+	remoteChanged := remote.UpdatedAt != base.UpdatedAt && !merge.Equal(base.Snapshot, remote.Snapshot())
+	
+	switch {
+	case localChanged && remoteChanged:
+		return statusBothModified
+	case localChanged:
+		return statusLocalModified
+	case remoteChanged:
+		return statusRemoteModified
+	}
+	return statusClean
+}
\ No newline at end of file
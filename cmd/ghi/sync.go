@@ -0,0 +1,562 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nomnel/ghi/internal/diff"
+	"github.com/nomnel/ghi/internal/filefmt"
+	"github.com/nomnel/ghi/internal/merge"
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/nomnel/ghi/internal/store"
+)
+
+// issuePath returns the local file for an issue: the existing file with its
+// number, whatever it is called, or else a name from the filename template.
+func (a *app) issuePath(issueNumber string, title string) string {
+	if path, ok := a.files.lookup(a, issueNumber); ok {
+		return path
+	}
+	return filepath.Join(a.dir, a.cfg.IssueFileName(issueNumber, title))
+}
+
+// fileIndex caches the issue files of a directory for one command run, so
+// finding the file of an issue does not read the directory every time.
+// Commands that create, move or delete an issue file record it with track.
+type fileIndex struct {
+	mu sync.Mutex
+	// files is nil until the directory has been read.
+	files map[string]string
+}
+
+// load reads the directory the first time it is needed. The caller holds mu.
+func (x *fileIndex) load(a *app) error {
+	if x.files != nil {
+		return nil
+	}
+	files, err := a.issueFilesIn(a.dir)
+	if err != nil {
+		return err
+	}
+	x.files = files
+	return nil
+}
+
+func (x *fileIndex) lookup(a *app, issueNumber string) (string, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.load(a) != nil {
+		return "", false
+	}
+	path, ok := x.files[issueNumber]
+	return path, ok
+}
+
+// track records that the file of an issue is now at path, or gone when path
+// is empty.
+func (x *fileIndex) track(issueNumber string, path string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	switch {
+	case x.files == nil:
+		// Not read yet: reading it later finds the change.
+	case path == "":
+		delete(x.files, issueNumber)
+	default:
+		x.files[issueNumber] = path
+	}
+}
+
+type pullOutcome int
+
+const (
+	pullCreated pullOutcome = iota
+	pullUpdated
+	pullUnchanged
+	pullMerged
+	pullKept
+	pullConflict
+	// pullSkipped is left alone by pull --all because of local edits.
+	pullSkipped
+)
+
+func (o pullOutcome) String() string {
+	return [...]string{"created", "updated", "unchanged", "merged", "kept", "conflict", "skipped"}[o]
+}
+
+// pullIssue writes a fetched issue to its local file and records it as the new
+// base. Local edits made since the last pull are merged with the remote
+// changes unless force is set; the conflicting fields are returned when the
+// merge could not be completed. Comments are synced when asked for and from
+// then on whenever the file has a comments section.
+func (a *app) pullIssue(issue *model.IssueData, force bool, comments bool) (pullOutcome, []string, error) {
+	issueNumber := strconv.Itoa(issue.Number)
+	filePath := a.issuePath(issueNumber, issue.Title)
+	
+	bases := store.Open(a.dir)
+	base, err := bases.Load(issueNumber)
+	if err != nil {
+		return 0, nil, model.NewIOError("failed to load base snapshot", err)
+	}
+	
+	existing, err := os.ReadFile(filePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, nil, model.NewIOError(fmt.Sprintf("failed to read %s", filePath), err)
+	}
+	
+	snap := issue.Snapshot()
+	outcome := pullUpdated
+	var conflicts []string
+	var local *localIssue
+	
+	switch {
+	case existing == nil:
+		outcome = pullCreated
+	case !force && base != nil:
+		// Only a base tells us whether the file was edited since the last
+		// pull; without one the file is overwritten as before.
+		local, err = a.readLocal(filePath, base)
+		if err != nil {
+			return 0, nil, model.NewIOError(fmt.Sprintf("failed to read %s (use --force to overwrite)", filePath), err)
+		}
+		if merge.Modified(base.Snapshot, local.Snapshot) {
+			if issue.UpdatedAt == base.UpdatedAt {
+				snap, outcome = local.Snapshot, pullKept
+			} else {
+				res := merge.Issue(base.Snapshot, local.Snapshot, snap)
+				snap, conflicts, outcome = res.Snapshot, res.Conflicts, pullMerged
+			}
+		}
+	}
+	
+	var section *model.CommentSection
+	var remoteComments []model.Comment
+	if comments || base.SyncsComments() {
+		remoteComments, err = a.client.ListComments(issueNumber)
+		if err != nil {
+			return 0, nil, backendError(err)
+		}
+		
+		var localComments *model.CommentSection
+		if local != nil {
+			localComments = local.Comments
+		}
+		var commentConflicts []string
+		section, commentConflicts = merge.Comments(baseComments(base), localComments, remoteComments)
+		conflicts = append(conflicts, commentConflicts...)
+	}
+	if len(conflicts) > 0 {
+		outcome = pullConflict
+	}
+	snap.Frontmatter = a.cfg.FilterFrontmatter(snap.Frontmatter)
+	
+	content, err := a.encodeLocal(localIssue{Snapshot: snap, Comments: section}, existing)
+	if err != nil {
+		return 0, nil, err
+	}
+	
+	if bytes.Equal(existing, content) {
+		if outcome == pullUpdated {
+			outcome = pullUnchanged
+		}
+	} else {
+		// Kept local edits next to new remote comments are still a merge.
+		if outcome == pullKept {
+			outcome = pullMerged
+		}
+		if err := filefmt.AtomicWriteFile(filePath, content, 0o644); err != nil {
+			return 0, nil, model.NewIOError("failed to write file", err)
+		}
+		a.files.track(issueNumber, filePath)
+	}
+	
+	newBase := store.NewBase(issueNumber, issue.UpdatedAt, issue.Snapshot())
+	newBase.Comments, newBase.CommentsSynced = remoteComments, section != nil
+	if err := bases.Save(newBase); err != nil {
+		return 0, nil, model.NewIOError("failed to save base snapshot", err)
+	}
+	
+	return outcome, conflicts, nil
+}
+
+type pushOutcome int
+
+const (
+	pushUpdated pushOutcome = iota
+	pushMerged
+	pushConflict
+)
+
+func (o pushOutcome) String() string {
+	return [...]string{"updated", "merged", "conflict"}[o]
+}
+
+// pendingPush is a push worked out from the local file and the remote issue,
+// before anything is written or sent. local has the remote changes merged in.
+type pendingPush struct {
+	filePath       string
+	local          localIssue
+	remote         *model.IssueData
+	merged         bool
+	conflicts      []string
+	remoteComments []model.Comment
+	commentEdits   []model.Comment
+}
+
+func (p *pendingPush) outcome() pushOutcome {
+	switch {
+	case len(p.conflicts) > 0:
+		return pushConflict
+	case p.merged:
+		return pushMerged
+	}
+	return pushUpdated
+}
+
+// preparePush reads the local file of an issue and merges the remote changes
+// made since the last pull into it, without writing or sending anything.
+func (a *app) preparePush(issueNumber string) (*pendingPush, error) {
+	filePath := a.issuePath(issueNumber, "")
+	
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, model.NewIOError(fmt.Sprintf("%s not found. Run 'ghi pull %s' first", filePath, issueNumber), nil)
+		}
+		return nil, model.NewIOError("failed to read file", err)
+	}
+	
+	fm, body, err := filefmt.DecodeMarkdown(raw)
+	if err != nil {
+		if strings.Contains(err.Error(), "malformed frontmatter") {
+			return nil, model.NewIOError(fmt.Sprintf("Invalid frontmatter in %s", filePath), err)
+		}
+		return nil, model.NewIOError("failed to parse markdown", err)
+	}
+	
+	base, err := store.Open(a.dir).Load(issueNumber)
+	if err != nil {
+		return nil, model.NewIOError("failed to load base snapshot", err)
+	}
+	
+	var section *model.CommentSection
+	if base.SyncsComments() {
+		body, section, err = filefmt.SplitComments(body)
+		if err != nil {
+			return nil, model.NewIOError(fmt.Sprintf("Invalid comments section in %s", filePath), err)
+		}
+	}
+	
+	local := localIssue{Snapshot: model.Snapshot{Frontmatter: a.cfg.FilterFrontmatter(*fm), Body: string(body)}, Comments: section}
+	
+	if merge.HasConflictMarkers(local.Body) || merge.ScalarConflicts(local.Frontmatter) != nil {
+		return nil, model.NewConflictError(fmt.Sprintf("%s has unresolved conflict markers. Resolve them and run 'ghi push %s' again.", filePath, issueNumber))
+	}
+	
+	if !model.ValidState(fm.State) {
+		return nil, model.NewIOError(fmt.Sprintf("Invalid frontmatter in %s: state must be 'open' or 'closed'", filePath), nil)
+	}
+	
+	remote, err := a.client.ViewIssue(issueNumber)
+	if err != nil {
+		return nil, backendError(err)
+	}
+	
+	p := &pendingPush{filePath: filePath, local: local, remote: remote}
+	if local.Comments != nil {
+		p.remoteComments, err = a.client.ListComments(issueNumber)
+		if err != nil {
+			return nil, backendError(err)
+		}
+		p.commentEdits = merge.CommentEdits(baseComments(base), local.Comments)
+		if err := checkCommentEdits(filePath, p.commentEdits, p.remoteComments); err != nil {
+			return nil, err
+		}
+	}
+	
+	// The remote changed since it was last pulled: merge instead of
+	// overwriting someone else's edits. Comment edits do not always touch
+	// updatedAt, so comments are merged whenever they are synced.
+	if base != nil && (remote.UpdatedAt != base.UpdatedAt || local.Comments != nil) {
+		merged := local
+		if remote.UpdatedAt != base.UpdatedAt {
+			res := merge.Issue(base.Snapshot, local.Snapshot, remote.Snapshot())
+			merged.Snapshot, p.conflicts = res.Snapshot, res.Conflicts
+		}
+		if local.Comments != nil {
+			var commentConflicts []string
+			merged.Comments, commentConflicts = merge.Comments(base.Comments, local.Comments, p.remoteComments)
+			p.conflicts = append(p.conflicts, commentConflicts...)
+		}
+		p.merged = len(p.conflicts) == 0 && !reflect.DeepEqual(merged.Snapshot, local.Snapshot)
+		p.local = merged
+	}
+	
+	return p, nil
+}
+
+// mutations lists what pushing p writes and sends, for --dry-run. A conflict
+// only rewrites the file with conflict markers.
+func (p *pendingPush) mutations() []mutation {
+	var ms []mutation
+	if p.merged || len(p.conflicts) > 0 {
+		ms = append(ms, mutation{Op: "write_file", Path: p.filePath})
+	}
+	if len(p.conflicts) > 0 {
+		return ms
+	}
+	
+	ms = append(ms, editMutation(model.NewIssueEdit(p.local.Frontmatter, p.local.Body, p.remote), p.remote))
+	for _, c := range p.commentEdits {
+		m := mutation{Op: "edit_comment", Issue: p.remote.Number, Comment: c.ID}
+		if i := slices.IndexFunc(p.remoteComments, func(r model.Comment) bool { return r.ID == c.ID }); i >= 0 {
+			m.Body = diff.Hunks(p.remoteComments[i].Body, c.Body, diffContext)
+		}
+		ms = append(ms, m)
+	}
+	if p.local.Comments != nil {
+		if text := strings.TrimSpace(p.local.Comments.New); text != "" {
+			ms = append(ms, mutation{Op: "add_comment", Issue: p.remote.Number, Text: text})
+		}
+	}
+	return ms
+}
+
+// pushIssue updates the remote issue from its local file. Remote changes made
+// since the last pull are merged into the file first; if that merge conflicts
+// the file is rewritten with conflict markers and nothing is pushed. Edited
+// own comments and a new comment in the comments section are pushed after the
+// issue itself.
+func (a *app) pushIssue(issueNumber string) (pushOutcome, []string, error) {
+	p, err := a.preparePush(issueNumber)
+	if err != nil {
+		return 0, nil, err
+	}
+	bases := store.Open(a.dir)
+	
+	if len(p.conflicts) > 0 {
+		if err := a.writeLocal(p.filePath, p.local); err != nil {
+			return 0, nil, err
+		}
+		newBase := store.NewBase(issueNumber, p.remote.UpdatedAt, p.remote.Snapshot())
+		newBase.Comments, newBase.CommentsSynced = p.remoteComments, p.local.Comments != nil
+		if err := bases.Save(newBase); err != nil {
+			return 0, nil, model.NewIOError("failed to save base snapshot", err)
+		}
+		return pushConflict, p.conflicts, nil
+	}
+	if p.merged {
+		if err := a.writeLocal(p.filePath, p.local); err != nil {
+			return 0, nil, err
+		}
+	}
+	
+	local, remote := p.local, p.remote
+	edit := model.NewIssueEdit(local.Frontmatter, local.Body, remote)
+	
+	if err := a.client.EditIssue(issueNumber, edit); err != nil {
+		return 0, nil, backendError(err)
+	}
+	
+	// Record what was pushed as the new base. The remote updatedAt is only
+	// trusted if nobody else edited the issue in the meantime.
+	pushed := merge.Fill(local.Snapshot, remote.Snapshot())
+	newBase := store.NewBase(issueNumber, "", pushed)
+	if after, err := a.client.ViewIssue(issueNumber); err == nil && !merge.Modified(after.Snapshot(), pushed) {
+		newBase = store.NewBase(issueNumber, after.UpdatedAt, after.Snapshot())
+	}
+	
+	if local.Comments != nil {
+		comments, err := a.pushComments(issueNumber, p.filePath, local, p.commentEdits)
+		if err != nil {
+			return 0, nil, err
+		}
+		newBase.Comments, newBase.CommentsSynced = comments, true
+	}
+	
+	if err := bases.Save(newBase); err != nil {
+		return 0, nil, model.NewIOError("failed to save base snapshot", err)
+	}
+	
+	return p.outcome(), nil, nil
+}
+
+// checkCommentEdits rejects edits to comments the user cannot change.
+func checkCommentEdits(filePath string, edits []model.Comment, remote []model.Comment) error {
+	for _, edit := range edits {
+		i := slices.IndexFunc(remote, func(c model.Comment) bool { return c.ID == edit.ID })
+		if i < 0 {
+			return model.NewIOError(fmt.Sprintf("Comment %d in %s was deleted on GitHub; undo the edit or run 'ghi pull --force'", edit.ID, filePath), nil)
+		}
+		if !remote[i].Mine {
+			return model.NewIOError(fmt.Sprintf("Comment %d in %s is by @%s and cannot be edited; only comments marked 'editable' can be changed", edit.ID, filePath, remote[i].Author), nil)
+		}
+	}
+	return nil
+}
+
+// pushComments updates edited comments and posts the new comment, then
+// rewrites the comments section from the remote so the new comment is not
+// posted twice. It returns the comments to record in the base.
+func (a *app) pushComments(issueNumber string, filePath string, local localIssue, edits []model.Comment) ([]model.Comment, error) {
+	for _, c := range edits {
+		if err := a.client.EditComment(c.ID, c.Body); err != nil {
+			return nil, backendError(err)
+		}
+	}
+	
+	if text := strings.TrimSpace(local.Comments.New); text != "" {
+		if err := a.client.AddComment(issueNumber, text); err != nil {
+			return nil, backendError(err)
+		}
+	}
+	
+	// If the refetch fails the file still has to lose the posted comment;
+	// what was pushed becomes the base.
+	comments, err := a.client.ListComments(issueNumber)
+	if err != nil {
+		comments = local.Comments.Comments
+	}
+	
+	local.Comments = &model.CommentSection{Comments: comments}
+	if err := a.writeLocal(filePath, local); err != nil {
+		return nil, err
+	}
+	
+	return comments, nil
+}
+
+// localIssue is a decoded issue file. Comments is nil when the file does not
+// sync comments.
+type localIssue struct {
+	model.Snapshot
+	Comments *model.CommentSection
+}
+
+func baseComments(base *store.Base) []model.Comment {
+	if base == nil {
+		return nil
+	}
+	return base.Comments
+}
+
+// readLocal reads and decodes an issue file. Frontmatter fields that are not
+// synced are dropped, and the comments section is only split off when the
+// base records that the file syncs comments.
+func (a *app) readLocal(path string, base *store.Base) (*localIssue, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	
+	fm, body, err := filefmt.DecodeMarkdown(raw)
+	if err != nil {
+		return nil, err
+	}
+	
+	var section *model.CommentSection
+	if base.SyncsComments() {
+		body, section, err = filefmt.SplitComments(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	
+	return &localIssue{Snapshot: model.Snapshot{Frontmatter: a.cfg.FilterFrontmatter(*fm), Body: string(body)}, Comments: section}, nil
+}
+
+// encodeLocal encodes an issue file on top of its previous content, so keys
+// ghi does not manage survive.
+func (a *app) encodeLocal(local localIssue, prev []byte) ([]byte, error) {
+	content, err := filefmt.UpdateMarkdown(prev, local.Frontmatter, a.cfg.Fields, filefmt.AppendComments([]byte(local.Body), local.Comments))
+	if err != nil {
+		return nil, model.NewIOError("failed to encode markdown", err)
+	}
+	return content, nil
+}
+
+// writeLocal encodes an issue and atomically replaces its file.
+func (a *app) writeLocal(path string, local localIssue) error {
+	prev, _ := os.ReadFile(path)
+	content, err := a.encodeLocal(local, prev)
+	if err != nil {
+		return err
+	}
+	
+	if err := filefmt.AtomicWriteFile(path, content, 0o644); err != nil {
+		return model.NewIOError("failed to write file", err)
+	}
+	
+	return nil
+}
+
+func conflictError(path string, issueNumber string, fields []string) error {
+	return model.NewConflictError(fmt.Sprintf("Conflicts in %s (%s). Resolve them and run 'ghi push %s'.", path, strings.Join(fields, ", "), issueNumber))
+}
+
+// localIssueFiles maps the number of every issue file in the directory to
+// its path. Files are recognised by the filename template with any slug, so
+// renamed files are still found. The map is a copy of the command's index.
+func (a *app) localIssueFiles() (map[string]string, error) {
+	a.files.mu.Lock()
+	defer a.files.mu.Unlock()
+	if err := a.files.load(a); err != nil {
+		return nil, err
+	}
+	return maps.Clone(a.files.files), nil
+}
+
+// issueFilesIn maps the number of every issue file in dir to its path.
+func (a *app) issueFilesIn(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	
+	files := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if n, ok := a.cfg.MatchIssueFile(entry.Name()); ok {
+			if _, dup := files[n]; !dup {
+				files[n] = filepath.Join(dir, entry.Name())
+			}
+		}
+	}
+	
+	return files, nil
+}
+
+// localIssueNumbers returns the numbers of all issue files, in ascending
+// numeric order.
+func (a *app) localIssueNumbers() ([]string, error) {
+	files, err := a.localIssueFiles()
+	if err != nil {
+		return nil, err
+	}
+	
+	numbers := make([]string, 0, len(files))
+	for n := range files {
+		numbers = append(numbers, n)
+	}
+	
+	sort.Slice(numbers, func(i, j int) bool {
+		a, _ := strconv.Atoi(numbers[i])
+		b, _ := strconv.Atoi(numbers[j])
+		return a < b
+	})
+	
+	return numbers, nil
+}
\ No newline at end of file
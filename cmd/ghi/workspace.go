@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// In workspace mode each repository's files live in issues/<owner>/<repo>/,
+// so one directory can mirror several repositories. Commands that look at all
+// local files (status, prune, search, pull --all, push --all, push --new) then
+// run once per mirrored repository unless --repo picks one.
+
+// spansWorkspace reports whether cmd runs across every repository of a
+// workspace when no --repo is given.
+func spansWorkspace(cmd *cobra.Command) bool {
+	if cmd.HasParent() && (cmd.Parent().Name() == "pr" || cmd.Parent().Name() == "discussion") {
+		// Pull request and discussion commands work on the current
+		// repository.
+		return false
+	}
+	switch cmd.Name() {
+	case "status", "prune", "search":
+		return true
+	case "pull":
+		all, _ := cmd.Flags().GetBool("all")
+		return all || cmd.Flags().Changed("since")
+	case "push":
+		all, _ := cmd.Flags().GetBool("all")
+		createNew, _ := cmd.Flags().GetBool("new")
+		return all || createNew
+	}
+	return false
+}
+
+// workspaceRepos returns the "owner/name" of every repository directory in a
+// workspace, sorted.
+func workspaceRepos(root string) ([]string, error) {
+	owners, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var repos []string
+	for _, owner := range owners {
+		if !owner.IsDir() || strings.HasPrefix(owner.Name(), ".") || owner.Name() == "tmp" {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(root, owner.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range entries {
+			if repo.IsDir() && !strings.HasPrefix(repo.Name(), ".") {
+				repos = append(repos, owner.Name()+"/"+repo.Name())
+			}
+		}
+	}
+	
+	return repos, nil
+}
+
+// forRepo returns a copy of a that works on another repository of the
+// workspace.
+func (a *app) forRepo(repo string) (*app, error) {
+	client, err := a.connect(repo)
+	if err != nil {
+		return nil, err
+	}
+	
+	r := *a
+	r.repo = repo
+	r.client = client
+	r.dir = filepath.Join(a.cfg.IssuesDir, repo)
+	r.files = &fileIndex{}
+	return &r, nil
+}
+
+// repos returns one app per repository the command applies to: a itself, or
+// for a workspace-wide command every mirrored repository. An empty workspace
+// falls back to the current repository so it can be populated.
+func (a *app) repos() ([]*app, error) {
+	if a.dir != "" {
+		return []*app{a}, nil
+	}
+	
+	names, err := workspaceRepos(a.cfg.IssuesDir)
+	if err != nil {
+		return nil, model.NewIOError("failed to read workspace", err)
+	}
+	if len(names) == 0 {
+		current, err := a.client.Repository()
+		if err != nil {
+			return nil, backendError(err)
+		}
+		names = []string{current}
+	}
+	
+	apps := make([]*app, 0, len(names))
+	for _, name := range names {
+		r, err := a.forRepo(name)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, r)
+	}
+	return apps, nil
+}
+
+// eachRepo runs fn for every repository the command applies to. Across a
+// workspace each run is headed by the repository name, and a failure in one
+// repository is reported without stopping the others; the exit code is the
+// first failure's.
+func (a *app) eachRepo(fn func(r *app) error) error {
+	apps, err := a.repos()
+	if err != nil {
+		return err
+	}
+	if a.dir != "" {
+		return fn(a)
+	}
+	
+	var code model.ErrorType
+	for i, r := range apps {
+		if i > 0 {
+			fmt.Fprintln(a.out)
+		}
+		fmt.Fprintf(a.out, "==> %s\n", r.repo)
+		
+		err := fn(r)
+		if err == nil {
+			continue
+		}
+		
+		exitErr := &model.ExitError{Code: model.ExitIO, Err: err}
+		errors.As(err, &exitErr)
+		a.printError(exitErr, r.repo)
+		if code == 0 {
+			code = exitErr.Code
+		}
+	}
+	
+	if code != 0 {
+		return &model.ExitError{Code: code}
+	}
+	return nil
+}
\ No newline at end of file
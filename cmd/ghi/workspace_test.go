@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func TestRepoFlag(t *testing.T) {
+	h := newHarness(t)
+	h.remote("octo/other").Add(model.IssueData{Title: "elsewhere"})
+	
+	h.mustRun(0, "--repo", "octo/other", "pull", "1")
+	if got := h.read("issues/1.md"); !strings.Contains(got, "title: elsewhere") {
+		t.Errorf("issues/1.md =\n%s", got)
+	}
+	
+	h.mustRun(0, "-R", "octo/other", "close", "1")
+	if got := h.remote("octo/other").Issue(1).State; got != "CLOSED" {
+		t.Errorf("state = %q", got)
+	}
+	
+	h.mustRun(int(model.ExitUsage), "--repo", "not-a-repo", "pull", "1")
+}
+
+func TestWorkspace(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(model.IssueData{Title: "current", Body: "a\n"})
+	h.remote("octo/other").Add(model.IssueData{Title: "other", Body: "b\n"})
+	
+	// Without --repo single-issue commands use the current repository.
+	h.mustRun(0, "--workspace", "pull", "1")
+	h.mustRun(0, "--workspace", "--repo", "octo/other", "pull", "1")
+	
+	if got := h.read("issues/owner/repo/1.md"); !strings.Contains(got, "title: current") {
+		t.Errorf("issues/owner/repo/1.md =\n%s", got)
+	}
+	if got := h.read("issues/octo/other/1.md"); !strings.Contains(got, "title: other") {
+		t.Errorf("issues/octo/other/1.md =\n%s", got)
+	}
+	if _, err := os.Stat("issues/octo/other/.ghi/base/1.json"); err != nil {
+		t.Errorf("base not kept per repository: %v", err)
+	}
+	
+	h.write("issues/owner/repo/1.md", strings.Replace(h.read("issues/owner/repo/1.md"), "a\n", "a2\n", 1))
+	h.write("issues/octo/other/1.md", strings.Replace(h.read("issues/octo/other/1.md"), "b\n", "b2\n", 1))
+	
+	// Workspace-wide commands cover every mirrored repository.
+	h.mustRun(0, "--workspace", "status", "--json")
+	var entries []statusEntry
+	if err := json.Unmarshal(h.out.Bytes(), &entries); err != nil {
+		t.Fatalf("invalid JSON %q: %v", h.out.String(), err)
+	}
+	if len(entries) != 2 || entries[0].Repo != "octo/other" || entries[1].Repo != "owner/repo" {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for _, e := range entries {
+		if e.Status != statusLocalModified {
+			t.Errorf("%s status = %s", e.Path, e.Status)
+		}
+	}
+	
+	t.Setenv("GHI_WORKSPACE", "1")
+	h.mustRun(0, "push", "--all")
+	if h.backend.Issue(1).Body != "a2\n" || h.remote("octo/other").Issue(1).Body != "b2\n" {
+		t.Errorf("push --all did not cover every repository")
+	}
+	if out := h.out.String(); !strings.Contains(out, "==> octo/other") || !strings.Contains(out, "==> owner/repo") {
+		t.Errorf("stdout = %q", out)
+	}
+	
+	// --repo narrows a workspace-wide command to one repository.
+	h.remote("octo/other").Update(1, func(issue *model.IssueData) { issue.State = "CLOSED" })
+	h.backend.Update(1, func(issue *model.IssueData) { issue.State = "CLOSED" })
+	h.mustRun(0, "--repo", "octo/other", "prune")
+	if _, err := os.Stat("issues/octo/other/1.md"); err == nil {
+		t.Errorf("closed issue not pruned")
+	}
+	if _, err := os.Stat("issues/owner/repo/1.md"); err != nil {
+		t.Errorf("other repository pruned: %v", err)
+	}
+}
+
+func TestWorkspaceContinuesPastFailingRepository(t *testing.T) {
+	h := newHarness(t)
+	h.backend.Add(model.IssueData{Title: "current"})
+	h.remote("octo/other").Add(model.IssueData{Title: "other"})
+	h.mustRun(0, "--workspace", "pull", "1")
+	h.mustRun(0, "--workspace", "-R", "octo/other", "pull", "1")
+	
+	h.remote("octo/other").Fail["ListAllIssues"] = os.ErrPermission
+	h.backend.Update(1, func(issue *model.IssueData) { issue.Title = "renamed" })
+	
+	h.mustRun(int(model.ExitEnv), "--workspace", "pull", "--all")
+	if !strings.Contains(h.errOut.String(), "octo/other: ") {
+		t.Errorf("stderr = %q", h.errOut.String())
+	}
+	if got := h.read("issues/owner/repo/1.md"); !strings.Contains(got, "title: renamed") {
+		t.Errorf("owner/repo not pulled after octo/other failed:\n%s", got)
+	}
+}
\ No newline at end of file
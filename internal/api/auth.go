@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/gh"
+)
+
+func resolveEnvironment(c *Client) error {
+	if c.Token == "" {
+		token, err := Token()
+		if err != nil {
+			return err
+		}
+		c.Token = token
+	}
+	
+	if c.Owner == "" || c.Repo == "" {
+		owner, repo, err := Repository()
+		if err != nil {
+			return err
+		}
+		c.Owner, c.Repo = owner, repo
+	}
+	
+	return nil
+}
+
+// Token returns GITHUB_TOKEN or GH_TOKEN when set, otherwise the token the gh
+// CLI is logged in with.
+func Token() (string, error) {
+	for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			return token, nil
+		}
+	}
+	
+	token, err := gh.AuthToken()
+	if err != nil || token == "" {
+		return "", &backend.Error{Kind: backend.KindAuth, Message: "no GitHub token: set GITHUB_TOKEN or run 'gh auth login'"}
+	}
+	return token, nil
+}
+
+var remoteRegex = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// Repository returns the repository of the current directory: GH_REPO when
+// set, otherwise what gh resolves, falling back to the origin remote when gh
+// is not installed.
+func Repository() (owner string, repo string, err error) {
+	if nameWithOwner := os.Getenv("GH_REPO"); nameWithOwner != "" {
+		return backend.SplitRepo(nameWithOwner)
+	}
+	
+	if _, err := exec.LookPath("gh"); err == nil {
+		return gh.GetRepositoryInfo()
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), backend.DefaultTimeout)
+	defer cancel()
+	
+	out, err := exec.CommandContext(ctx, "git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return "", "", &backend.Error{Kind: backend.KindNotFound, Message: "could not determine the GitHub repository: run inside a repo with an 'origin' remote"}
+	}
+	
+	m := remoteRegex.FindStringSubmatch(strings.TrimSpace(string(out)))
+	if m == nil {
+		return "", "", fmt.Errorf("origin remote is not a GitHub repository: %s", strings.TrimSpace(string(out)))
+	}
+	return m[1], m[2], nil
+}
\ No newline at end of file
@@ -0,0 +1,631 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/model"
+)
+
+const (
+	DefaultBaseURL = "https://api.github.com"
+	apiVersion     = "2022-11-28"
+)
+
+// Client implements backend.Backend against the GitHub REST and GraphQL APIs
+// directly, without spawning a gh process per call.
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string
+	Token      string
+	Owner      string
+	Repo       string
+	// Retry retries rate-limited, 5xx and failed requests.
+	Retry *backend.Retrier
+	
+	// quotas holds the latest rate limit headers per resource.
+	quotaMu sync.Mutex
+	quotas  map[string]backend.Quota
+	
+	// resolve fills in Token, Owner and Repo on first use when they were not
+	// given up front.
+	resolve func(c *Client) error
+	once    sync.Once
+	initErr error
+}
+
+var (
+	_ backend.Backend       = (*Client)(nil)
+	_ backend.QuotaReporter = (*Client)(nil)
+)
+
+func New(token, owner, repo string) *Client {
+	return &Client{
+		HTTPClient: &http.Client{Timeout: backend.DefaultTimeout},
+		BaseURL:    DefaultBaseURL,
+		Token:      token,
+		Owner:      owner,
+		Repo:       repo,
+		Retry:      backend.NewRetrier(),
+	}
+}
+
+// NewFromEnvironment returns a client that authenticates with GITHUB_TOKEN,
+// GH_TOKEN or the token `gh auth token` prints, and operates on repo
+// ("owner/name") or, when that is empty, on the repository of the current
+// directory. Both are resolved on the first call.
+func NewFromEnvironment(repo string) (*Client, error) {
+	c := New("", "", "")
+	if repo != "" {
+		owner, name, err := backend.SplitRepo(repo)
+		if err != nil {
+			return nil, err
+		}
+		c.Owner, c.Repo = owner, name
+	}
+	if base := os.Getenv("GITHUB_API_URL"); base != "" {
+		c.BaseURL = strings.TrimSuffix(base, "/")
+	}
+	c.resolve = resolveEnvironment
+	return c, nil
+}
+
+func (c *Client) setup() error {
+	c.once.Do(func() {
+		if c.resolve != nil {
+			c.initErr = c.resolve(c)
+		}
+	})
+	return c.initErr
+}
+
+// graphQLURL derives the GraphQL endpoint, which lives outside the REST prefix
+// on GitHub Enterprise Server (/api/v3 vs /api/graphql).
+func (c *Client) graphQLURL() string {
+	if base, ok := strings.CutSuffix(c.BaseURL, "/api/v3"); ok {
+		return base + "/api/graphql"
+	}
+	return c.BaseURL + "/graphql"
+}
+
+// do sends a REST request. "{owner}" and "{repo}" in path are replaced with
+// the client's repository, like gh api does.
+func (c *Client) do(method, path string, in, out any) error {
+	if err := c.setup(); err != nil {
+		return err
+	}
+	
+	path = strings.NewReplacer("{owner}", url.PathEscape(c.Owner), "{repo}", url.PathEscape(c.Repo)).Replace(path)
+	return c.send(method, c.BaseURL+"/"+path, in, out)
+}
+
+// send sends a request, retrying it when that is safe. A POST may have
+// created something before the response was lost, so it is only retried
+// after a rate limit.
+func (c *Client) send(method, target string, in, out any) error {
+	return c.Retry.Do(method != http.MethodPost, func() error {
+		return c.sendOnce(method, target, in, out)
+	})
+}
+
+func (c *Client) sendOnce(method, target string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return &backend.Error{Kind: backend.KindUnavailable, Message: fmt.Sprintf("GitHub API request failed: %v", err)}
+	}
+	defer resp.Body.Close()
+	c.recordQuota(resp.Header)
+	
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &backend.Error{Kind: backend.KindUnavailable, Message: fmt.Sprintf("failed to read GitHub API response: %v", err)}
+	}
+	
+	if resp.StatusCode >= 300 {
+		return responseError(resp, data)
+	}
+	
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse API response: %w", err)
+		}
+	}
+	
+	return nil
+}
+
+func responseError(resp *http.Response, data []byte) *backend.Error {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(data, &payload)
+	
+	msg := payload.Message
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	
+	// Retry-After comes with secondary rate limits and some 5xx responses;
+	// an exhausted primary limit only tells when it resets.
+	var retryAfter time.Duration
+	exhausted := resp.Header.Get("X-RateLimit-Remaining") == "0"
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		retryAfter = time.Duration(seconds) * time.Second
+	} else if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil && exhausted {
+		retryAfter = max(time.Until(time.Unix(reset, 0)), time.Second)
+	}
+	
+	kind := backend.KindForStatus(resp.StatusCode)
+	if resp.StatusCode == http.StatusForbidden &&
+		(exhausted || resp.Header.Get("Retry-After") != "" || strings.Contains(strings.ToLower(msg), "rate limit")) {
+		kind = backend.KindRateLimited
+	}
+	
+	return &backend.Error{
+		Kind:       kind,
+		StatusCode: resp.StatusCode,
+		Message:    fmt.Sprintf("GitHub API error (HTTP %d): %s", resp.StatusCode, msg),
+		RetryAfter: retryAfter,
+	}
+}
+
+// recordQuota remembers the rate limit headers of a response.
+func (c *Client) recordQuota(h http.Header) {
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, _ := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	reset, _ := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	resource := h.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+	
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+	if c.quotas == nil {
+		c.quotas = map[string]backend.Quota{}
+	}
+	c.quotas[resource] = backend.Quota{Resource: resource, Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}
+}
+
+// Quotas returns the rate limits reported with the responses so far.
+func (c *Client) Quotas() ([]backend.Quota, error) {
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+	var quotas []backend.Quota
+	for _, q := range c.quotas {
+		quotas = append(quotas, q)
+	}
+	slices.SortFunc(quotas, func(a, b backend.Quota) int { return strings.Compare(a.Resource, b.Resource) })
+	return quotas, nil
+}
+
+// GraphQL posts a query with the repository's $owner and $name variables set.
+func (c *Client) GraphQL(query string, vars map[string]string, out any) error {
+	if err := c.setup(); err != nil {
+		return err
+	}
+	
+	variables := map[string]any{"owner": c.Owner, "name": c.Repo}
+	for k, v := range vars {
+		variables[k] = v
+	}
+	
+	payload := map[string]any{
+		"query":     query,
+		"variables": variables,
+	}
+	// Queries are read-only, so unlike other POSTs they are safe to retry;
+	// mutations are not.
+	mutation := strings.HasPrefix(strings.TrimSpace(query), "mutation")
+	return c.Retry.Do(!mutation, func() error {
+		var data json.RawMessage
+		if err := c.sendOnce(http.MethodPost, c.graphQLURL(), payload, &data); err != nil {
+			return err
+		}
+		if err := c.graphQLRateLimit(data); err != nil {
+			return err
+		}
+		if out != nil && len(data) > 0 {
+			if err := json.Unmarshal(data, out); err != nil {
+				return fmt.Errorf("failed to parse API response: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// graphQLRateLimit turns a RATE_LIMITED GraphQL error, which GitHub may
+// send with status 200, into a rate limit error.
+func (c *Client) graphQLRateLimit(data []byte) error {
+	var response struct {
+		Errors []backend.GraphQLError `json:"errors"`
+	}
+	json.Unmarshal(data, &response)
+	for _, e := range response.Errors {
+		if e.Type != "RATE_LIMITED" {
+			continue
+		}
+		err := &backend.Error{Kind: backend.KindRateLimited, Message: "GitHub API error: " + e.Message}
+		c.quotaMu.Lock()
+		if q, ok := c.quotas["graphql"]; ok && q.Remaining == 0 {
+			err.RetryAfter = max(time.Until(q.Reset), time.Second)
+		}
+		c.quotaMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+type restIssue struct {
+	Number      int              `json:"number"`
+	Title       string           `json:"title"`
+	Body        string           `json:"body"`
+	State       string           `json:"state"`
+	Labels      []model.Label    `json:"labels"`
+	Assignees   []model.User     `json:"assignees"`
+	Milestone   *model.Milestone `json:"milestone"`
+	UpdatedAt   string           `json:"updated_at"`
+	HTMLURL     string           `json:"html_url"`
+	User        model.User       `json:"user"`
+	PullRequest *struct{}        `json:"pull_request"`
+}
+
+func (r *restIssue) listItem() model.IssueListItem {
+	return model.IssueListItem{
+		Number:    r.Number,
+		Title:     r.Title,
+		URL:       r.HTMLURL,
+		State:     strings.ToUpper(r.State),
+		Labels:    r.Labels,
+		Assignees: r.Assignees,
+		Author:    r.User,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// issueData converts the REST representation to the one gh returns, whose
+// states are upper case.
+func (r *restIssue) issueData() *model.IssueData {
+	return &model.IssueData{
+		Number:    r.Number,
+		Title:     r.Title,
+		Body:      r.Body,
+		State:     strings.ToUpper(r.State),
+		Labels:    r.Labels,
+		Assignees: r.Assignees,
+		Milestone: r.Milestone,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+func (c *Client) Repository() (string, error) {
+	if err := c.setup(); err != nil {
+		return "", err
+	}
+	return c.Owner + "/" + c.Repo, nil
+}
+
+func (c *Client) ViewIssue(issueNumber string) (*model.IssueData, error) {
+	var issue restIssue
+	if err := c.do(http.MethodGet, "repos/{owner}/{repo}/issues/"+issueNumber, nil, &issue); err != nil {
+		return nil, err
+	}
+	return issue.issueData(), nil
+}
+
+func (c *Client) ViewIssues(issueNumbers []string) (map[string]*model.IssueData, error) {
+	return backend.ViewIssues(c, issueNumbers)
+}
+
+// ListAllIssues uses GraphQL for a full listing and the REST issues endpoint,
+// whose since parameter filters on the update time, for an incremental one.
+func (c *Client) ListAllIssues(state string, since time.Time) ([]*model.IssueData, error) {
+	if since.IsZero() {
+		return backend.ListAllIssues(c, state, since)
+	}
+	if state != "open" && state != "closed" && state != "all" {
+		return nil, &backend.Error{Kind: backend.KindInvalid, Message: fmt.Sprintf("invalid state %q: must be open, closed or all", state)}
+	}
+	
+	query := url.Values{}
+	query.Set("state", state)
+	query.Set("since", since.UTC().Format(time.RFC3339))
+	query.Set("sort", "updated")
+	query.Set("direction", "asc")
+	query.Set("per_page", "100")
+	
+	var result []*model.IssueData
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		
+		var issues []restIssue
+		if err := c.do(http.MethodGet, "repos/{owner}/{repo}/issues?"+query.Encode(), nil, &issues); err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			// The issues endpoint also returns pull requests.
+			if issue.PullRequest == nil {
+				result = append(result, issue.issueData())
+			}
+		}
+		if len(issues) < 100 {
+			break
+		}
+	}
+	
+	return result, nil
+}
+
+func (c *Client) EditIssue(issueNumber string, edit model.IssueEdit) error {
+	issuePath := "repos/{owner}/{repo}/issues/" + issueNumber
+	
+	patch := map[string]any{"body": edit.Body}
+	if strings.TrimSpace(edit.Title) != "" {
+		patch["title"] = edit.Title
+	}
+	if edit.State != "" {
+		patch["state"] = edit.State
+	}
+	if edit.Milestone != "" {
+		number, err := c.milestoneNumber(edit.Milestone)
+		if err != nil {
+			return err
+		}
+		patch["milestone"] = number
+	}
+	if edit.RemoveMilestone {
+		patch["milestone"] = nil
+	}
+	
+	if err := c.do(http.MethodPatch, issuePath, patch, nil); err != nil {
+		return err
+	}
+	
+	if err := c.editLabels(issuePath, edit.AddLabels, edit.RemoveLabels); err != nil {
+		return err
+	}
+	if len(edit.AddAssignees) > 0 {
+		if err := c.do(http.MethodPost, issuePath+"/assignees", map[string]any{"assignees": edit.AddAssignees}, nil); err != nil {
+			return err
+		}
+	}
+	if len(edit.RemoveAssignees) > 0 {
+		if err := c.do(http.MethodDelete, issuePath+"/assignees", map[string]any{"assignees": edit.RemoveAssignees}, nil); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// editLabels adds and removes labels of the issue at issuePath. Removing a
+// label the issue does not have is not an error.
+func (c *Client) editLabels(issuePath string, add, remove []string) error {
+	if len(add) > 0 {
+		if err := c.do(http.MethodPost, issuePath+"/labels", map[string]any{"labels": add}, nil); err != nil {
+			return err
+		}
+	}
+	for _, label := range remove {
+		if err := c.do(http.MethodDelete, issuePath+"/labels/"+url.PathEscape(label), nil, nil); err != nil && !backend.IsKind(err, backend.KindNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// milestoneNumber resolves a milestone title to the number the REST API wants.
+func (c *Client) milestoneNumber(title string) (int, error) {
+	var milestones []struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	}
+	if err := c.do(http.MethodGet, "repos/{owner}/{repo}/milestones?state=all&per_page=100", nil, &milestones); err != nil {
+		return 0, err
+	}
+	for _, m := range milestones {
+		if m.Title == title {
+			return m.Number, nil
+		}
+	}
+	return 0, &backend.Error{Kind: backend.KindInvalid, Message: fmt.Sprintf("milestone %q not found", title)}
+}
+
+func (c *Client) CreateIssue(draft model.IssueDraft) (int, error) {
+	in := map[string]any{"title": draft.Title, "body": draft.Body}
+	if len(draft.Labels) > 0 {
+		in["labels"] = draft.Labels
+	}
+	if len(draft.Assignees) > 0 {
+		in["assignees"] = draft.Assignees
+	}
+	if draft.Milestone != "" {
+		number, err := c.milestoneNumber(draft.Milestone)
+		if err != nil {
+			return 0, err
+		}
+		in["milestone"] = number
+	}
+	
+	var issue restIssue
+	if err := c.do(http.MethodPost, "repos/{owner}/{repo}/issues", in, &issue); err != nil {
+		return 0, err
+	}
+	if issue.Number == 0 {
+		return 0, fmt.Errorf("API response missing issue number")
+	}
+	return issue.Number, nil
+}
+
+func (c *Client) ListCreatedIssues(since time.Time) ([]*model.IssueData, error) {
+	return backend.ListCreatedIssues(c, since)
+}
+
+func (c *Client) CloseIssue(issueNumber string) error {
+	return c.do(http.MethodPatch, "repos/{owner}/{repo}/issues/"+issueNumber, map[string]any{"state": "closed"}, nil)
+}
+
+func (c *Client) ReopenIssue(issueNumber string) error {
+	return c.do(http.MethodPatch, "repos/{owner}/{repo}/issues/"+issueNumber, map[string]any{"state": "open"}, nil)
+}
+
+func (c *Client) ListComments(issueNumber string) ([]model.Comment, error) {
+	return backend.ListComments(c, issueNumber)
+}
+
+func (c *Client) AddComment(issueNumber string, body string) error {
+	return c.do(http.MethodPost, "repos/{owner}/{repo}/issues/"+issueNumber+"/comments", map[string]any{"body": body}, nil)
+}
+
+func (c *Client) EditComment(id int64, body string) error {
+	return c.do(http.MethodPatch, fmt.Sprintf("repos/{owner}/{repo}/issues/comments/%d", id), map[string]any{"body": body}, nil)
+}
+
+func (c *Client) ListIssues(opts backend.ListOptions) ([]model.IssueListItem, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = backend.DefaultListLimit
+	}
+	
+	if opts.Search != "" {
+		return c.searchIssues(opts, limit)
+	}
+	
+	query := url.Values{}
+	query.Set("state", "open")
+	if opts.State != "" {
+		query.Set("state", opts.State)
+	}
+	if len(opts.Labels) > 0 {
+		query.Set("labels", strings.Join(opts.Labels, ","))
+	}
+	if opts.Assignee != "" {
+		query.Set("assignee", opts.Assignee)
+	}
+	if opts.Author != "" {
+		query.Set("creator", opts.Author)
+	}
+	if opts.Mention != "" {
+		query.Set("mentioned", opts.Mention)
+	}
+	if opts.Milestone != "" {
+		milestone := opts.Milestone
+		if !model.IsNumeric(milestone) && milestone != "*" && milestone != "none" {
+			number, err := c.milestoneNumber(milestone)
+			if err != nil {
+				return nil, err
+			}
+			milestone = strconv.Itoa(number)
+		}
+		query.Set("milestone", milestone)
+	}
+	query.Set("per_page", "100")
+	
+	var items []model.IssueListItem
+	for page := 1; len(items) < limit; page++ {
+		query.Set("page", strconv.Itoa(page))
+		
+		var issues []restIssue
+		if err := c.do(http.MethodGet, "repos/{owner}/{repo}/issues?"+query.Encode(), nil, &issues); err != nil {
+			return nil, err
+		}
+		
+		for _, issue := range issues {
+			// The issues endpoint also returns pull requests.
+			if issue.PullRequest != nil || len(items) >= limit {
+				continue
+			}
+			items = append(items, issue.listItem())
+		}
+		
+		if len(issues) < 100 {
+			break
+		}
+	}
+	
+	return items, nil
+}
+
+// searchIssues serves ListIssues with a search query through the search API,
+// mirroring how `gh issue list --search` combines it with the other filters.
+func (c *Client) searchIssues(opts backend.ListOptions, limit int) ([]model.IssueListItem, error) {
+	if err := c.setup(); err != nil {
+		return nil, err
+	}
+	
+	terms := []string{fmt.Sprintf("repo:%s/%s", c.Owner, c.Repo), "is:issue", opts.Search}
+	switch opts.State {
+	case "", "open":
+		terms = append(terms, "state:open")
+	case "closed":
+		terms = append(terms, "state:closed")
+	}
+	for _, label := range opts.Labels {
+		terms = append(terms, fmt.Sprintf("label:%q", label))
+	}
+	if opts.Assignee != "" {
+		terms = append(terms, "assignee:"+opts.Assignee)
+	}
+	if opts.Author != "" {
+		terms = append(terms, "author:"+opts.Author)
+	}
+	if opts.Mention != "" {
+		terms = append(terms, "mentions:"+opts.Mention)
+	}
+	if opts.Milestone != "" {
+		terms = append(terms, fmt.Sprintf("milestone:%q", opts.Milestone))
+	}
+	
+	query := url.Values{}
+	query.Set("q", strings.Join(terms, " "))
+	query.Set("per_page", strconv.Itoa(min(limit, 100)))
+	
+	var response struct {
+		Items []restIssue `json:"items"`
+	}
+	if err := c.do(http.MethodGet, "search/issues?"+query.Encode(), nil, &response); err != nil {
+		return nil, err
+	}
+	
+	items := make([]model.IssueListItem, 0, len(response.Items))
+	for _, issue := range response.Items {
+		items = append(items, issue.listItem())
+	}
+	return items, nil
+}
\ No newline at end of file
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/model"
+)
+
+const issueJSON = `{"number":1,"title":"Fix login","body":"","state":"open","updated_at":"2024-01-01T00:00:00Z"}`
+
+// newTestClient returns a client for a stand-in server whose handler answers
+// the nth request (counting from 1), and the delays the client slept for.
+func newTestClient(t *testing.T, handler func(n int, w http.ResponseWriter, r *http.Request)) (*Client, *[]time.Duration) {
+	t.Helper()
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(int(requests.Add(1)), w, r)
+	}))
+	t.Cleanup(srv.Close)
+	
+	c := New("token", "owner", "repo")
+	c.BaseURL = srv.URL
+	var slept []time.Duration
+	c.Retry.Sleep = func(d time.Duration) { slept = append(slept, d) }
+	return c, &slept
+}
+
+func TestRetriesServerErrors(t *testing.T) {
+	var calls int
+	c, slept := newTestClient(t, func(n int, w http.ResponseWriter, r *http.Request) {
+		calls = n
+		if n < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, issueJSON)
+	})
+	
+	issue, err := c.ViewIssue("1")
+	if err != nil {
+		t.Fatalf("ViewIssue: %v", err)
+	}
+	if issue.Title != "Fix login" || calls != 3 {
+		t.Errorf("got %q after %d requests, want Fix login after 3", issue.Title, calls)
+	}
+	// Jittered exponential backoff: half to all of 1s, then of 2s.
+	if len(*slept) != 2 || (*slept)[0] < 500*time.Millisecond || (*slept)[0] > time.Second ||
+		(*slept)[1] < time.Second || (*slept)[1] > 2*time.Second {
+		t.Errorf("backoff = %v", *slept)
+	}
+}
+
+func TestGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int
+	c, _ := newTestClient(t, func(n int, w http.ResponseWriter, r *http.Request) {
+		calls = n
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	
+	_, err := c.ViewIssue("1")
+	if !backend.IsKind(err, backend.KindUnavailable) {
+		t.Fatalf("err = %v, want unavailable", err)
+	}
+	if calls != backend.DefaultMaxAttempts {
+		t.Errorf("%d requests, want %d", calls, backend.DefaultMaxAttempts)
+	}
+}
+
+func TestDoesNotRetryCreateOnServerError(t *testing.T) {
+	var calls int
+	c, _ := newTestClient(t, func(n int, w http.ResponseWriter, r *http.Request) {
+		calls = n
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	
+	if err := c.AddComment("1", "hello"); err == nil {
+		t.Fatal("AddComment succeeded")
+	}
+	if calls != 1 {
+		t.Errorf("POST sent %d times, want once", calls)
+	}
+}
+
+func TestDoesNotRetryGraphQLMutations(t *testing.T) {
+	var mutations int
+	c, _ := newTestClient(t, func(n int, w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "mutation") {
+			fmt.Fprint(w, `{"data":{"repository":{"id":"R_1","hasDiscussionsEnabled":true,"discussionCategories":{"nodes":[{"id":"C_1","name":"Ideas"}]}}}}`)
+			return
+		}
+		mutations++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	
+	if _, err := c.CreateDiscussion(model.DiscussionDraft{Title: "Roadmap", Category: "ideas"}); err == nil {
+		t.Fatal("CreateDiscussion succeeded")
+	}
+	if mutations != 1 {
+		t.Errorf("mutation sent %d times, want once", mutations)
+	}
+}
+
+func TestViewDiscussionThread(t *testing.T) {
+	c, _ := newTestClient(t, func(n int, w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"repository":{"discussion":{"id":"D_1","number":5,"title":"Plugin API","body":"b","isAnswered":true,"category":{"name":"Ideas"},
+			"comments":{"pageInfo":{"hasNextPage":false},"nodes":[{"databaseId":9,"author":null,"body":"c","isAnswer":true,
+			"replies":{"nodes":[{"databaseId":10,"author":{"login":"bob"},"body":"r"}]}}]}}}}}`)
+	})
+	
+	d, err := c.ViewDiscussion("5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Category != "Ideas" || !d.Answered || len(d.Comments) != 1 {
+		t.Fatalf("discussion = %+v", d)
+	}
+	if c := d.Comments[0]; c.Author != "ghost" || !c.IsAnswer || len(c.Replies) != 1 || c.Replies[0].Author != "bob" || c.Replies[0].ID != 10 {
+		t.Errorf("thread = %+v", d.Comments)
+	}
+}
+
+func TestDoesNotRetryClientErrors(t *testing.T) {
+	var calls int
+	c, _ := newTestClient(t, func(n int, w http.ResponseWriter, r *http.Request) {
+		calls = n
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Not Found"}`)
+	})
+	
+	if _, err := c.ViewIssue("1"); !backend.IsKind(err, backend.KindNotFound) {
+		t.Fatalf("err = %v, want not found", err)
+	}
+	if calls != 1 {
+		t.Errorf("%d requests, want 1", calls)
+	}
+}
+
+func TestHonorsRetryAfter(t *testing.T) {
+	c, slept := newTestClient(t, func(n int, w http.ResponseWriter, r *http.Request) {
+		if n == 1 {
+			// A secondary rate limit.
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit."}`)
+			return
+		}
+		fmt.Fprint(w, `{"body":"hi"}`)
+	})
+	
+	// Rate limits are retried even for a POST: GitHub rejected it unseen.
+	if err := c.AddComment("1", "hello"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if len(*slept) != 1 || (*slept)[0] <= 6*time.Second || (*slept)[0] > 7*time.Second {
+		t.Errorf("slept %v, want 7s", *slept)
+	}
+}
+
+func TestWaitsForPrimaryRateLimitReset(t *testing.T) {
+	reset := time.Now().Add(30 * time.Second).Unix()
+	c, slept := newTestClient(t, func(n int, w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.Header().Set("X-RateLimit-Resource", "core")
+		if n == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"API rate limit exceeded for user ID 1."}`)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		fmt.Fprint(w, issueJSON)
+	})
+	
+	if _, err := c.ViewIssue("1"); err != nil {
+		t.Fatalf("ViewIssue: %v", err)
+	}
+	if len(*slept) != 1 || (*slept)[0] < 25*time.Second || (*slept)[0] > 31*time.Second {
+		t.Errorf("slept %v, want about 30s", *slept)
+	}
+	
+	quotas, _ := c.Quotas()
+	if len(quotas) != 1 || quotas[0].Resource != "core" || quotas[0].Remaining != 4999 || quotas[0].Limit != 5000 {
+		t.Errorf("quotas = %+v", quotas)
+	}
+}
+
+func TestFailsWhenResetIsTooFarAway(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	c, slept := newTestClient(t, func(n int, w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	
+	_, err := c.ViewIssue("1")
+	if !backend.IsKind(err, backend.KindRateLimited) {
+		t.Fatalf("err = %v, want rate limited", err)
+	}
+	if len(*slept) != 0 {
+		t.Errorf("slept %v before giving up", *slept)
+	}
+}
+
+func TestRetriesRateLimitedGraphQL(t *testing.T) {
+	c, slept := newTestClient(t, func(n int, w http.ResponseWriter, r *http.Request) {
+		if n == 1 {
+			fmt.Fprint(w, `{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"viewer":{"login":"me"}}}`)
+	})
+	
+	var out struct {
+		Data struct {
+			Viewer struct{ Login string }
+		}
+	}
+	if err := c.GraphQL("query { viewer { login } }", nil, &out); err != nil {
+		t.Fatalf("GraphQL: %v", err)
+	}
+	if out.Data.Viewer.Login != "me" || len(*slept) != 1 {
+		t.Errorf("login %q after sleeping %v", out.Data.Viewer.Login, *slept)
+	}
+}
\ No newline at end of file
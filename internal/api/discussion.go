@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// Discussions have no REST API, so they go through GraphQL.
+
+func (c *Client) ViewDiscussion(number string) (*model.DiscussionData, error) {
+	return backend.ViewDiscussion(c, number)
+}
+
+func (c *Client) ListDiscussions(category string, limit int) ([]model.DiscussionListItem, error) {
+	return backend.ListDiscussions(c, category, limit)
+}
+
+func (c *Client) EditDiscussion(number string, edit model.DiscussionEdit) error {
+	return backend.EditDiscussion(c, number, edit)
+}
+
+func (c *Client) CreateDiscussion(draft model.DiscussionDraft) (int, error) {
+	return backend.CreateDiscussion(c, draft)
+}
\ No newline at end of file
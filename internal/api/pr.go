@@ -0,0 +1,138 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/model"
+)
+
+type restPR struct {
+	Number   int     `json:"number"`
+	NodeID   string  `json:"node_id"`
+	Title    string  `json:"title"`
+	Body     string  `json:"body"`
+	State    string  `json:"state"`
+	Draft    bool    `json:"draft"`
+	MergedAt *string `json:"merged_at"`
+	Base     struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Head struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Labels             []model.Label `json:"labels"`
+	RequestedReviewers []model.User  `json:"requested_reviewers"`
+	RequestedTeams     []struct {
+		Slug string `json:"slug"`
+	} `json:"requested_teams"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// prData converts the REST representation to the one gh returns, which has
+// a MERGED state and lists teams as ORG/SLUG.
+func (r *restPR) prData(owner string) *model.PRData {
+	pr := &model.PRData{
+		Number:      r.Number,
+		Title:       r.Title,
+		Body:        r.Body,
+		State:       strings.ToUpper(r.State),
+		IsDraft:     r.Draft,
+		BaseRefName: r.Base.Ref,
+		HeadRefName: r.Head.Ref,
+		Labels:      r.Labels,
+		UpdatedAt:   r.UpdatedAt,
+	}
+	if r.MergedAt != nil {
+		pr.State = "MERGED"
+	}
+	for _, u := range r.RequestedReviewers {
+		pr.ReviewRequests = append(pr.ReviewRequests, model.Reviewer{Login: u.Login})
+	}
+	for _, t := range r.RequestedTeams {
+		pr.ReviewRequests = append(pr.ReviewRequests, model.Reviewer{Login: owner + "/" + t.Slug})
+	}
+	return pr
+}
+
+func (c *Client) ViewPR(prNumber string) (*model.PRData, error) {
+	var pr restPR
+	if err := c.do(http.MethodGet, "repos/{owner}/{repo}/pulls/"+prNumber, nil, &pr); err != nil {
+		return nil, err
+	}
+	return pr.prData(c.Owner), nil
+}
+
+func (c *Client) EditPR(prNumber string, edit model.PREdit) error {
+	patch := map[string]any{"body": edit.Body}
+	if strings.TrimSpace(edit.Title) != "" {
+		patch["title"] = edit.Title
+	}
+	if edit.Base != "" {
+		patch["base"] = edit.Base
+	}
+	
+	var pr restPR
+	if err := c.do(http.MethodPatch, "repos/{owner}/{repo}/pulls/"+prNumber, patch, &pr); err != nil {
+		return err
+	}
+	
+	// Labels belong to the pull request's issue.
+	if err := c.editLabels("repos/{owner}/{repo}/issues/"+prNumber, edit.AddLabels, edit.RemoveLabels); err != nil {
+		return err
+	}
+	
+	reviewersPath := "repos/{owner}/{repo}/pulls/" + prNumber + "/requested_reviewers"
+	if len(edit.AddReviewers) > 0 {
+		if err := c.do(http.MethodPost, reviewersPath, c.reviewers(edit.AddReviewers), nil); err != nil {
+			return err
+		}
+	}
+	if len(edit.RemoveReviewers) > 0 {
+		if err := c.do(http.MethodDelete, reviewersPath, c.reviewers(edit.RemoveReviewers), nil); err != nil {
+			return err
+		}
+	}
+	
+	if edit.Draft != nil {
+		return c.setDraft(pr.NodeID, *edit.Draft)
+	}
+	return nil
+}
+
+// reviewers splits reviewer names into the users and team slugs the review
+// request endpoints take.
+func (c *Client) reviewers(names []string) map[string][]string {
+	in := map[string][]string{"reviewers": {}, "team_reviewers": {}}
+	for _, name := range names {
+		if _, slug, ok := strings.Cut(name, "/"); ok {
+			in["team_reviewers"] = append(in["team_reviewers"], slug)
+		} else {
+			in["reviewers"] = append(in["reviewers"], name)
+		}
+	}
+	return in
+}
+
+// setDraft changes the draft state, which only GraphQL can do.
+func (c *Client) setDraft(nodeID string, draft bool) error {
+	mutation := `mutation($id: ID!) { markPullRequestReadyForReview(input: {pullRequestId: $id}) { clientMutationId } }`
+	if draft {
+		mutation = `mutation($id: ID!) { convertPullRequestToDraft(input: {pullRequestId: $id}) { clientMutationId } }`
+	}
+	
+	var response struct {
+		Errors []backend.GraphQLError `json:"errors"`
+	}
+	if err := c.send(http.MethodPost, c.graphQLURL(), map[string]any{
+		"query":     mutation,
+		"variables": map[string]string{"id": nodeID},
+	}, &response); err != nil {
+		return err
+	}
+	if len(response.Errors) > 0 {
+		return &backend.Error{Message: "GraphQL error: " + response.Errors[0].Message}
+	}
+	return nil
+}
\ No newline at end of file
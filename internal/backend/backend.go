@@ -0,0 +1,185 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// DefaultTimeout bounds a single GitHub operation.
+const DefaultTimeout = 30 * time.Second
+
+// Backend is the set of GitHub issue operations ghi needs. The gh package
+// implements it by running the gh CLI and the api package by talking to the
+// GitHub REST and GraphQL APIs directly.
+type Backend interface {
+	// Repository returns the "owner/name" of the repository the backend
+	// operates on.
+	Repository() (string, error)
+	ViewIssue(issueNumber string) (*model.IssueData, error)
+	// ViewIssues fetches several issues at once. Issues that do not exist
+	// are absent from the result.
+	ViewIssues(issueNumbers []string) (map[string]*model.IssueData, error)
+	// ListAllIssues fetches every issue in the given state ("open",
+	// "closed" or "all") with full content. A non-zero since limits it to
+	// issues updated at or after that time.
+	ListAllIssues(state string, since time.Time) ([]*model.IssueData, error)
+	ListIssues(opts ListOptions) ([]model.IssueListItem, error)
+	EditIssue(issueNumber string, edit model.IssueEdit) error
+	CreateIssue(draft model.IssueDraft) (int, error)
+	// ListCreatedIssues returns the issues the authenticated user created
+	// at or after since, so an interrupted create can be recognised.
+	ListCreatedIssues(since time.Time) ([]*model.IssueData, error)
+	CloseIssue(issueNumber string) error
+	ReopenIssue(issueNumber string) error
+	// ListComments fetches every comment on an issue, oldest first.
+	ListComments(issueNumber string) ([]model.Comment, error)
+	AddComment(issueNumber string, body string) error
+	EditComment(id int64, body string) error
+	ViewPR(prNumber string) (*model.PRData, error)
+	EditPR(prNumber string, edit model.PREdit) error
+	// ViewDiscussion fetches a discussion with its comments and replies.
+	ViewDiscussion(number string) (*model.DiscussionData, error)
+	ListDiscussions(category string, limit int) ([]model.DiscussionListItem, error)
+	EditDiscussion(number string, edit model.DiscussionEdit) error
+	CreateDiscussion(draft model.DiscussionDraft) (int, error)
+}
+
+// SplitRepo splits an "owner/name" repository reference.
+func SplitRepo(nameWithOwner string) (owner string, name string, err error) {
+	owner, name, ok := strings.Cut(nameWithOwner, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", &Error{Kind: KindInvalid, Message: fmt.Sprintf("invalid repository %q: expected OWNER/REPO", nameWithOwner)}
+	}
+	return owner, name, nil
+}
+
+// ListOptions filters ListIssues. Zero values mean "no filter"; a zero Limit
+// uses the default of 30 that `gh issue list` uses.
+type ListOptions struct {
+	State     string
+	Labels    []string
+	Assignee  string
+	Author    string
+	Mention   string
+	Milestone string
+	Search    string
+	Limit     int
+}
+
+const DefaultListLimit = 30
+
+// ListFlags names the `gh issue list` flags ParseListArgs accepts, for usage
+// and error messages.
+const ListFlags = "-s/--state, -l/--label, -a/--assignee, -A/--author, --mention, -m/--milestone, -S/--search and -L/--limit"
+
+// ParseListArgs parses the `gh issue list` flags ghi understands, so both
+// backends accept the same options. Other gh flags, such as --app, --json or
+// --web, are rejected rather than passed through.
+func ParseListArgs(args []string) (ListOptions, error) {
+	var opts ListOptions
+	
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if !strings.HasPrefix(name, "-") {
+			return opts, &Error{Kind: KindInvalid, Message: fmt.Sprintf("unexpected argument %q", args[i])}
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, &Error{Kind: KindInvalid, Message: fmt.Sprintf("flag needs an argument: %s", name)}
+			}
+			i++
+			value = args[i]
+		}
+		
+		switch name {
+		case "-s", "--state":
+			switch value {
+			case "open", "closed", "all":
+				opts.State = value
+			default:
+				return opts, &Error{Kind: KindInvalid, Message: fmt.Sprintf("invalid argument %q for %q flag: valid values are {open|closed|all}", value, name)}
+			}
+		case "-l", "--label":
+			opts.Labels = append(opts.Labels, strings.Split(value, ",")...)
+		case "-a", "--assignee":
+			opts.Assignee = value
+		case "-A", "--author":
+			opts.Author = value
+		case "--mention":
+			opts.Mention = value
+		case "-m", "--milestone":
+			opts.Milestone = value
+		case "-S", "--search":
+			opts.Search = value
+		case "-L", "--limit":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return opts, &Error{Kind: KindInvalid, Message: fmt.Sprintf("invalid argument %q for %q flag: must be a positive number", value, name)}
+			}
+			opts.Limit = n
+		default:
+			return opts, &Error{Kind: KindInvalid, Message: fmt.Sprintf("unknown flag: %s (supported: %s)", name, ListFlags)}
+		}
+	}
+	
+	return opts, nil
+}
+
+// Kind classifies backend failures so callers can react without parsing
+// error messages.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindAuth
+	KindNotFound
+	KindForbidden
+	KindRateLimited
+	KindInvalid
+	KindUnavailable
+)
+
+// Error is returned by backends for failed GitHub operations.
+type Error struct {
+	Kind Kind
+	// StatusCode is the HTTP status when known; the gh CLI does not expose it.
+	StatusCode int
+	Message    string
+	// RetryAfter is how long GitHub asked to wait before trying again, when
+	// it said so.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// IsKind reports whether err is a backend error of the given kind.
+func IsKind(err error, kind Kind) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == kind
+}
+
+// KindForStatus maps an HTTP status code to an error kind.
+func KindForStatus(status int) Kind {
+	switch {
+	case status == 401:
+		return KindAuth
+	case status == 403:
+		return KindForbidden
+	case status == 404 || status == 410:
+		return KindNotFound
+	case status == 422 || status == 400:
+		return KindInvalid
+	case status == 429:
+		return KindRateLimited
+	case status >= 500:
+		return KindUnavailable
+	}
+	return KindUnknown
+}
\ No newline at end of file
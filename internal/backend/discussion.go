@@ -0,0 +1,415 @@
+package backend
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// Discussions are only reachable through GraphQL, so both backends share
+// these functions. Optional variables are declared nullable and left unset
+// when unused.
+
+// discussionCommentsPageSize is the number of comments per query; every
+// comment brings at most discussionRepliesLimit replies with it.
+const (
+	discussionCommentsPageSize = 50
+	discussionRepliesLimit     = 100
+)
+
+type graphQLDiscussionComment struct {
+	DatabaseID int64       `json:"databaseId"`
+	Author     *model.User `json:"author"`
+	CreatedAt  string      `json:"createdAt"`
+	Body       string      `json:"body"`
+	IsAnswer   bool        `json:"isAnswer"`
+}
+
+func (g *graphQLDiscussionComment) comment() model.DiscussionComment {
+	// Comments by deleted accounts have no author.
+	author := "ghost"
+	if g.Author != nil {
+		author = g.Author.Login
+	}
+	return model.DiscussionComment{
+		ID:        g.DatabaseID,
+		Author:    author,
+		CreatedAt: g.CreatedAt,
+		Body:      g.Body,
+		IsAnswer:  g.IsAnswer,
+	}
+}
+
+// ViewDiscussion fetches a discussion with its comments and their replies,
+// one GraphQL query per page of comments.
+func ViewDiscussion(r GraphQLRunner, number string) (*model.DiscussionData, error) {
+	if !model.IsNumeric(number) {
+		return nil, &Error{Kind: KindInvalid, Message: fmt.Sprintf("invalid discussion number: %s", number)}
+	}
+	
+	query := fmt.Sprintf(`query($owner: String!, $name: String!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    discussion(number: %s) {
+      id number title body url updatedAt isAnswered
+      category { name }
+      comments(first: %d, after: $after) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          databaseId author { login } createdAt body isAnswer
+          replies(first: %d) { nodes { databaseId author { login } createdAt body } }
+        }
+      }
+    }
+  }
+}
+`, number, discussionCommentsPageSize, discussionRepliesLimit)
+
+	var discussion *model.DiscussionData
+	vars := map[string]string{}
+	
+	for {
+		var response struct {
+			Data struct {
+				Repository struct {
+					Discussion *struct {
+						ID         string `json:"id"`
+						Number     int    `json:"number"`
+						Title      string `json:"title"`
+						Body       string `json:"body"`
+						URL        string `json:"url"`
+						UpdatedAt  string `json:"updatedAt"`
+						IsAnswered bool   `json:"isAnswered"`
+						Category   struct {
+							Name string `json:"name"`
+						} `json:"category"`
+						Comments struct {
+							PageInfo struct {
+								HasNextPage bool   `json:"hasNextPage"`
+								EndCursor   string `json:"endCursor"`
+							} `json:"pageInfo"`
+							Nodes []struct {
+								graphQLDiscussionComment
+								Replies struct {
+									Nodes []graphQLDiscussionComment `json:"nodes"`
+								} `json:"replies"`
+							} `json:"nodes"`
+						} `json:"comments"`
+					} `json:"discussion"`
+				} `json:"repository"`
+			} `json:"data"`
+			Errors []GraphQLError `json:"errors"`
+		}
+		if err := r.GraphQL(query, vars, &response); err != nil {
+			return nil, err
+		}
+		for _, e := range response.Errors {
+			if e.Type == "NOT_FOUND" {
+				return nil, &Error{Kind: KindNotFound, Message: fmt.Sprintf("discussion #%s not found", number)}
+			}
+			return nil, &Error{Message: "GraphQL error: " + e.Message}
+		}
+		d := response.Data.Repository.Discussion
+		if d == nil {
+			return nil, &Error{Kind: KindNotFound, Message: fmt.Sprintf("discussion #%s not found", number)}
+		}
+		
+		if discussion == nil {
+			discussion = &model.DiscussionData{
+				ID:        d.ID,
+				Number:    d.Number,
+				Title:     d.Title,
+				Body:      d.Body,
+				Category:  d.Category.Name,
+				Answered:  d.IsAnswered,
+				URL:       d.URL,
+				UpdatedAt: d.UpdatedAt,
+				Comments:  []model.DiscussionComment{},
+			}
+		}
+		
+		for _, node := range d.Comments.Nodes {
+			c := node.comment()
+			for _, reply := range node.Replies.Nodes {
+				c.Replies = append(c.Replies, reply.comment())
+			}
+			discussion.Comments = append(discussion.Comments, c)
+		}
+		
+		if !d.Comments.PageInfo.HasNextPage {
+			break
+		}
+		vars["after"] = d.Comments.PageInfo.EndCursor
+	}
+	
+	return discussion, nil
+}
+
+// ListDiscussions returns up to limit discussions, most recently updated
+// first. A non-empty category limits it to that category.
+func ListDiscussions(r GraphQLRunner, category string, limit int) ([]model.DiscussionListItem, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	
+	vars := map[string]string{}
+	if category != "" {
+		repo, err := discussionCategories(r)
+		if err != nil {
+			return nil, err
+		}
+		id, err := repo.categoryID(category)
+		if err != nil {
+			return nil, err
+		}
+		vars["categoryId"] = id
+	}
+	
+	query := `query($owner: String!, $name: String!, $after: String, $categoryId: ID) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: ` + strconv.Itoa(min(limit, 100)) + `, after: $after, categoryId: $categoryId, orderBy: {field: UPDATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes { number title url updatedAt isAnswered category { name } author { login } }
+    }
+  }
+}
+`
+
+	var discussions []model.DiscussionListItem
+	
+	for len(discussions) < limit {
+		var response struct {
+			Data struct {
+				Repository struct {
+					Discussions struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []struct {
+							Number     int         `json:"number"`
+							Title      string      `json:"title"`
+							URL        string      `json:"url"`
+							UpdatedAt  string      `json:"updatedAt"`
+							IsAnswered bool        `json:"isAnswered"`
+							Author     *model.User `json:"author"`
+							Category   struct {
+								Name string `json:"name"`
+							} `json:"category"`
+						} `json:"nodes"`
+					} `json:"discussions"`
+				} `json:"repository"`
+			} `json:"data"`
+			Errors []GraphQLError `json:"errors"`
+		}
+		if err := r.GraphQL(query, vars, &response); err != nil {
+			return nil, err
+		}
+		if len(response.Errors) > 0 {
+			return nil, &Error{Message: "GraphQL error: " + response.Errors[0].Message}
+		}
+		
+		page := response.Data.Repository.Discussions
+		for _, node := range page.Nodes {
+			author := "ghost"
+			if node.Author != nil {
+				author = node.Author.Login
+			}
+			discussions = append(discussions, model.DiscussionListItem{
+				Number:    node.Number,
+				Title:     node.Title,
+				Category:  node.Category.Name,
+				Answered:  node.IsAnswered,
+				Author:    author,
+				URL:       node.URL,
+				UpdatedAt: node.UpdatedAt,
+			})
+		}
+		
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		vars["after"] = page.PageInfo.EndCursor
+	}
+	
+	return discussions[:min(len(discussions), limit)], nil
+}
+
+// EditDiscussion updates the title, body and category of a discussion. An
+// empty title or category leaves it unchanged.
+func EditDiscussion(r GraphQLRunner, number string, edit model.DiscussionEdit) error {
+	id, err := discussionID(r, number)
+	if err != nil {
+		return err
+	}
+	
+	vars := map[string]string{"id": id, "body": edit.Body}
+	if strings.TrimSpace(edit.Title) != "" {
+		vars["title"] = edit.Title
+	}
+	if edit.Category != "" {
+		repo, err := discussionCategories(r)
+		if err != nil {
+			return err
+		}
+		if vars["categoryId"], err = repo.categoryID(edit.Category); err != nil {
+			return err
+		}
+	}
+	
+	mutation := `mutation($id: ID!, $body: String!, $title: String, $categoryId: ID) {
+  updateDiscussion(input: {discussionId: $id, body: $body, title: $title, categoryId: $categoryId}) {
+    discussion { number }
+  }
+}
+`
+	var response struct {
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := r.GraphQL(mutation, vars, &response); err != nil {
+		return err
+	}
+	if len(response.Errors) > 0 {
+		return &Error{Message: "GraphQL error: " + response.Errors[0].Message}
+	}
+	return nil
+}
+
+// discussionID returns the node ID of a discussion.
+func discussionID(r GraphQLRunner, number string) (string, error) {
+	if !model.IsNumeric(number) {
+		return "", &Error{Kind: KindInvalid, Message: fmt.Sprintf("invalid discussion number: %s", number)}
+	}
+	
+	query := `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    discussion(number: ` + number + `) { id }
+  }
+}
+`
+	var response struct {
+		Data struct {
+			Repository struct {
+				Discussion *struct {
+					ID string `json:"id"`
+				} `json:"discussion"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := r.GraphQL(query, nil, &response); err != nil {
+		return "", err
+	}
+	for _, e := range response.Errors {
+		if e.Type != "NOT_FOUND" {
+			return "", &Error{Message: "GraphQL error: " + e.Message}
+		}
+	}
+	if response.Data.Repository.Discussion == nil {
+		return "", &Error{Kind: KindNotFound, Message: fmt.Sprintf("discussion #%s not found", number)}
+	}
+	return response.Data.Repository.Discussion.ID, nil
+}
+
+// CreateDiscussion starts a discussion and returns its number.
+func CreateDiscussion(r GraphQLRunner, draft model.DiscussionDraft) (int, error) {
+	repo, err := discussionCategories(r)
+	if err != nil {
+		return 0, err
+	}
+	categoryID, err := repo.categoryID(draft.Category)
+	if err != nil {
+		return 0, err
+	}
+	
+	mutation := `mutation($repositoryId: ID!, $categoryId: ID!, $title: String!, $body: String!) {
+  createDiscussion(input: {repositoryId: $repositoryId, categoryId: $categoryId, title: $title, body: $body}) {
+    discussion { number }
+  }
+}
+`
+	vars := map[string]string{
+		"repositoryId": repo.ID,
+		"categoryId":   categoryID,
+		"title":        draft.Title,
+		"body":         draft.Body,
+	}
+	
+	var response struct {
+		Data struct {
+			CreateDiscussion struct {
+				Discussion struct {
+					Number int `json:"number"`
+				} `json:"discussion"`
+			} `json:"createDiscussion"`
+		} `json:"data"`
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := r.GraphQL(mutation, vars, &response); err != nil {
+		return 0, err
+	}
+	if len(response.Errors) > 0 {
+		return 0, &Error{Message: "GraphQL error: " + response.Errors[0].Message}
+	}
+	return response.Data.CreateDiscussion.Discussion.Number, nil
+}
+
+// discussionRepo is the repository ID and discussion categories that the
+// mutations and the category filter need.
+type discussionRepo struct {
+	ID         string
+	Categories []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+}
+
+func discussionCategories(r GraphQLRunner) (*discussionRepo, error) {
+	query := `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    id hasDiscussionsEnabled
+    discussionCategories(first: 100) { nodes { id name } }
+  }
+}
+`
+	var response struct {
+		Data struct {
+			Repository struct {
+				ID                    string `json:"id"`
+				HasDiscussionsEnabled bool   `json:"hasDiscussionsEnabled"`
+				DiscussionCategories  struct {
+					Nodes []struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"nodes"`
+				} `json:"discussionCategories"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := r.GraphQL(query, nil, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Errors) > 0 {
+		return nil, &Error{Message: "GraphQL error: " + response.Errors[0].Message}
+	}
+	
+	repo := response.Data.Repository
+	if !repo.HasDiscussionsEnabled {
+		return nil, &Error{Kind: KindInvalid, Message: "discussions are not enabled for this repository"}
+	}
+	return &discussionRepo{ID: repo.ID, Categories: repo.DiscussionCategories.Nodes}, nil
+}
+
+// categoryID finds a category by name, ignoring case as GitHub does.
+func (d *discussionRepo) categoryID(name string) (string, error) {
+	var names []string
+	for _, c := range d.Categories {
+		if strings.EqualFold(c.Name, name) {
+			return c.ID, nil
+		}
+		names = append(names, c.Name)
+	}
+	return "", &Error{Kind: KindInvalid, Message: fmt.Sprintf("unknown discussion category %q (available: %s)", name, strings.Join(names, ", "))}
+}
\ No newline at end of file
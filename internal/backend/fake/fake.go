@@ -0,0 +1,796 @@
+// Package fake provides an in-memory GitHub issue store implementing
+// backend.Backend, for exercising commands without a GitHub account.
+package fake
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// Backend is an in-memory issue tracker. It is safe for concurrent use.
+type Backend struct {
+	mu       sync.Mutex
+	issues   map[int]*model.IssueData
+	prs      map[int]*model.PRData
+	discs    map[int]*model.DiscussionData
+	comments map[int][]model.Comment
+	created  map[int]creation
+	next     int
+	
+	// epoch is the time the backend was made; every change advances the
+	// clock by a second so timestamps are unique and ordered.
+	epoch time.Time
+	clock int
+	
+	// Repo is the "owner/name" the backend reports as its repository.
+	Repo string
+	
+	// Viewer is the login of the authenticated user; comments by Viewer
+	// are the only ones EditComment accepts.
+	Viewer string
+	
+	// Fail makes the named method (e.g. "EditIssue") return the error
+	// instead of doing anything.
+	Fail map[string]error
+	
+	// Categories are the discussion categories of the repository.
+	Categories []string
+	
+	// Quota is what Quotas reports.
+	Quota []backend.Quota
+	
+	// Calls records every method call as "Method" or "Method <n>".
+	Calls []string
+}
+
+// creation records who created an issue through CreateIssue, and when.
+type creation struct {
+	by string
+	at time.Time
+}
+
+var (
+	_ backend.Backend       = (*Backend)(nil)
+	_ backend.QuotaReporter = (*Backend)(nil)
+)
+
+func New() *Backend {
+	return &Backend{
+		issues:     map[int]*model.IssueData{},
+		prs:        map[int]*model.PRData{},
+		discs:      map[int]*model.DiscussionData{},
+		comments:   map[int][]model.Comment{},
+		created:    map[int]creation{},
+		next:       1,
+		epoch:      time.Now().UTC().Truncate(time.Second),
+		Repo:       "owner/repo",
+		Viewer:     "me",
+		Fail:       map[string]error{},
+		Categories: []string{"Announcements", "General", "Ideas", "Q&A"},
+	}
+}
+
+func (b *Backend) tick() string {
+	b.clock++
+	return b.now().Format(time.RFC3339)
+}
+
+func (b *Backend) now() time.Time {
+	return b.epoch.Add(time.Duration(b.clock) * time.Second)
+}
+
+// Add stores an issue, assigning the next free number when Number is zero.
+// State defaults to OPEN. It returns the stored issue's number.
+func (b *Backend) Add(issue model.IssueData) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if issue.Number == 0 {
+		issue.Number = b.next
+	}
+	if issue.State == "" {
+		issue.State = "OPEN"
+	}
+	issue.UpdatedAt = b.tick()
+	b.issues[issue.Number] = &issue
+	b.next = max(b.next, issue.Number+1)
+	return issue.Number
+}
+
+// Issue returns a copy of the stored issue, or nil.
+func (b *Backend) Issue(number int) *model.IssueData {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if issue, ok := b.issues[number]; ok {
+		return clone(issue)
+	}
+	return nil
+}
+
+// Update applies fn to a stored issue as a remote edit by someone else.
+func (b *Backend) Update(number int, fn func(issue *model.IssueData)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	fn(b.issues[number])
+	b.issues[number].UpdatedAt = b.tick()
+}
+
+// Delete removes an issue, as if it had been deleted or transferred.
+func (b *Backend) Delete(number int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	delete(b.issues, number)
+}
+
+// AddPR stores a pull request like Add stores an issue; pull requests and
+// issues share one number sequence, as on GitHub.
+func (b *Backend) AddPR(pr model.PRData) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if pr.Number == 0 {
+		pr.Number = b.next
+	}
+	if pr.State == "" {
+		pr.State = "OPEN"
+	}
+	pr.UpdatedAt = b.tick()
+	b.prs[pr.Number] = &pr
+	b.next = max(b.next, pr.Number+1)
+	return pr.Number
+}
+
+// PR returns a copy of the stored pull request, or nil.
+func (b *Backend) PR(number int) *model.PRData {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if pr, ok := b.prs[number]; ok {
+		return clonePR(pr)
+	}
+	return nil
+}
+
+// UpdatePR applies fn to a stored pull request as a remote edit by someone
+// else.
+func (b *Backend) UpdatePR(number int, fn func(pr *model.PRData)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	fn(b.prs[number])
+	b.prs[number].UpdatedAt = b.tick()
+}
+
+// AddDiscussion stores a discussion like Add stores an issue; discussions
+// share the number sequence too.
+func (b *Backend) AddDiscussion(d model.DiscussionData) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if d.Number == 0 {
+		d.Number = b.next
+	}
+	d.ID = fmt.Sprintf("D_%d", d.Number)
+	if d.Comments == nil {
+		d.Comments = []model.DiscussionComment{}
+	}
+	d.UpdatedAt = b.tick()
+	b.discs[d.Number] = &d
+	b.next = max(b.next, d.Number+1)
+	return d.Number
+}
+
+// Discussion returns a copy of the stored discussion, or nil.
+func (b *Backend) Discussion(number int) *model.DiscussionData {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if d, ok := b.discs[number]; ok {
+		return cloneDiscussion(d)
+	}
+	return nil
+}
+
+// UpdateDiscussion applies fn to a stored discussion as a remote edit by
+// someone else.
+func (b *Backend) UpdateDiscussion(number int, fn func(d *model.DiscussionData)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	fn(b.discs[number])
+	b.discs[number].UpdatedAt = b.tick()
+}
+
+// AddRemoteComment adds a comment to an issue and returns its ID. Author
+// defaults to someone other than Viewer.
+func (b *Backend) AddRemoteComment(number int, author string, body string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if author == "" {
+		author = "someone"
+	}
+	return b.addComment(number, author, body)
+}
+
+// Comments returns a copy of an issue's comments.
+func (b *Backend) Comments(number int) []model.Comment {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	return slices.Clone(b.comments[number])
+}
+
+// EditRemoteComment changes a comment's body as its author would.
+func (b *Backend) EditRemoteComment(id int64, body string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if c := b.comment(id); c != nil {
+		c.Body = body
+	}
+}
+
+// commentIDBase keeps comment IDs apart from issue numbers and above 32 bits,
+// like real ones.
+const commentIDBase = 5_000_000_000
+
+func (b *Backend) addComment(number int, author string, body string) int64 {
+	b.clock++
+	id := commentIDBase + int64(b.clock)
+	b.comments[number] = append(b.comments[number], model.Comment{
+		ID:        id,
+		Author:    author,
+		CreatedAt: b.now().Format(time.RFC3339),
+		Body:      body,
+	})
+	if issue, ok := b.issues[number]; ok {
+		issue.UpdatedAt = b.tick()
+	}
+	return id
+}
+
+func (b *Backend) comment(id int64) *model.Comment {
+	for number := range b.comments {
+		for i := range b.comments[number] {
+			if b.comments[number][i].ID == id {
+				return &b.comments[number][i]
+			}
+		}
+	}
+	return nil
+}
+
+func clone(issue *model.IssueData) *model.IssueData {
+	c := *issue
+	c.Labels = slices.Clone(issue.Labels)
+	c.Assignees = slices.Clone(issue.Assignees)
+	if issue.Milestone != nil {
+		m := *issue.Milestone
+		c.Milestone = &m
+	}
+	return &c
+}
+
+func clonePR(pr *model.PRData) *model.PRData {
+	c := *pr
+	c.Labels = slices.Clone(pr.Labels)
+	c.ReviewRequests = slices.Clone(pr.ReviewRequests)
+	return &c
+}
+
+func cloneDiscussion(d *model.DiscussionData) *model.DiscussionData {
+	c := *d
+	c.Comments = make([]model.DiscussionComment, len(d.Comments))
+	for i, comment := range d.Comments {
+		comment.Replies = slices.Clone(comment.Replies)
+		c.Comments[i] = comment
+	}
+	return &c
+}
+
+// begin records a call and returns the injected failure for it, if any.
+func (b *Backend) begin(method string, issueNumber string) error {
+	call := method
+	if issueNumber != "" {
+		call += " " + issueNumber
+	}
+	b.Calls = append(b.Calls, call)
+	return b.Fail[method]
+}
+
+func (b *Backend) lookup(issueNumber string) (*model.IssueData, error) {
+	n, err := strconv.Atoi(issueNumber)
+	if err == nil {
+		if issue, ok := b.issues[n]; ok {
+			return issue, nil
+		}
+	}
+	return nil, &backend.Error{Kind: backend.KindNotFound, StatusCode: 404, Message: fmt.Sprintf("issue #%s not found", issueNumber)}
+}
+
+func (b *Backend) Repository() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("Repository", ""); err != nil {
+		return "", err
+	}
+	return b.Repo, nil
+}
+
+func (b *Backend) ViewIssue(issueNumber string) (*model.IssueData, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("ViewIssue", issueNumber); err != nil {
+		return nil, err
+	}
+	issue, err := b.lookup(issueNumber)
+	if err != nil {
+		return nil, err
+	}
+	return clone(issue), nil
+}
+
+func (b *Backend) ViewIssues(issueNumbers []string) (map[string]*model.IssueData, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("ViewIssues", ""); err != nil {
+		return nil, err
+	}
+	result := map[string]*model.IssueData{}
+	for _, n := range issueNumbers {
+		if issue, err := b.lookup(n); err == nil {
+			result[n] = clone(issue)
+		}
+	}
+	return result, nil
+}
+
+func (b *Backend) sorted() []*model.IssueData {
+	var issues []*model.IssueData
+	for _, issue := range b.issues {
+		issues = append(issues, issue)
+	}
+	slices.SortFunc(issues, func(x, y *model.IssueData) int { return x.Number - y.Number })
+	return issues
+}
+
+func matchesState(issue *model.IssueData, state string) bool {
+	return state == "all" || strings.EqualFold(issue.State, state)
+}
+
+func (b *Backend) ListAllIssues(state string, since time.Time) ([]*model.IssueData, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("ListAllIssues", ""); err != nil {
+		return nil, err
+	}
+	var result []*model.IssueData
+	for _, issue := range b.sorted() {
+		updated, _ := time.Parse(time.RFC3339, issue.UpdatedAt)
+		if matchesState(issue, state) && !updated.Before(since) {
+			result = append(result, clone(issue))
+		}
+	}
+	return result, nil
+}
+
+// ListIssues returns the newest matching issues first, like GitHub does.
+// Author, mention and search filters are not modelled and match everything.
+func (b *Backend) ListIssues(opts backend.ListOptions) ([]model.IssueListItem, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("ListIssues", ""); err != nil {
+		return nil, err
+	}
+	
+	state := opts.State
+	if state == "" {
+		state = "open"
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = backend.DefaultListLimit
+	}
+	
+	issues := b.sorted()
+	slices.Reverse(issues)
+	
+	var items []model.IssueListItem
+	for _, issue := range issues {
+		fm := issue.Frontmatter()
+		switch {
+		case !matchesState(issue, state):
+			continue
+		case opts.Assignee != "" && !slices.Contains(fm.Assignees, opts.Assignee):
+			continue
+		case opts.Milestone != "" && fm.Milestone != opts.Milestone:
+			continue
+		case slices.ContainsFunc(opts.Labels, func(l string) bool { return !slices.Contains(fm.Labels, l) }):
+			continue
+		}
+		if len(items) == limit {
+			break
+		}
+		items = append(items, model.IssueListItem{
+			Number:    issue.Number,
+			Title:     issue.Title,
+			URL:       fmt.Sprintf("https://github.com/%s/issues/%d", b.Repo, issue.Number),
+			State:     issue.State,
+			Labels:    slices.Clone(issue.Labels),
+			Assignees: slices.Clone(issue.Assignees),
+			Author:    model.User{Login: b.created[issue.Number].by},
+			UpdatedAt: issue.UpdatedAt,
+		})
+	}
+	return items, nil
+}
+
+func (b *Backend) EditIssue(issueNumber string, edit model.IssueEdit) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("EditIssue", issueNumber); err != nil {
+		return err
+	}
+	issue, err := b.lookup(issueNumber)
+	if err != nil {
+		return err
+	}
+	
+	if strings.TrimSpace(edit.Title) != "" {
+		issue.Title = edit.Title
+	}
+	issue.Body = edit.Body
+	
+	for _, name := range edit.AddLabels {
+		issue.Labels = append(issue.Labels, model.Label{Name: name})
+	}
+	issue.Labels = slices.DeleteFunc(issue.Labels, func(l model.Label) bool { return slices.Contains(edit.RemoveLabels, l.Name) })
+	
+	for _, login := range edit.AddAssignees {
+		issue.Assignees = append(issue.Assignees, model.User{Login: login})
+	}
+	issue.Assignees = slices.DeleteFunc(issue.Assignees, func(u model.User) bool { return slices.Contains(edit.RemoveAssignees, u.Login) })
+	
+	if edit.Milestone != "" {
+		issue.Milestone = &model.Milestone{Title: edit.Milestone}
+	}
+	if edit.RemoveMilestone {
+		issue.Milestone = nil
+	}
+	if edit.State != "" {
+		issue.State = strings.ToUpper(edit.State)
+	}
+	
+	issue.UpdatedAt = b.tick()
+	return nil
+}
+
+func (b *Backend) ViewPR(prNumber string) (*model.PRData, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("ViewPR", prNumber); err != nil {
+		return nil, err
+	}
+	pr, err := b.lookupPR(prNumber)
+	if err != nil {
+		return nil, err
+	}
+	return clonePR(pr), nil
+}
+
+func (b *Backend) EditPR(prNumber string, edit model.PREdit) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("EditPR", prNumber); err != nil {
+		return err
+	}
+	pr, err := b.lookupPR(prNumber)
+	if err != nil {
+		return err
+	}
+	
+	if strings.TrimSpace(edit.Title) != "" {
+		pr.Title = edit.Title
+	}
+	pr.Body = edit.Body
+	if edit.Base != "" {
+		pr.BaseRefName = edit.Base
+	}
+	
+	for _, name := range edit.AddLabels {
+		pr.Labels = append(pr.Labels, model.Label{Name: name})
+	}
+	pr.Labels = slices.DeleteFunc(pr.Labels, func(l model.Label) bool { return slices.Contains(edit.RemoveLabels, l.Name) })
+	
+	for _, login := range edit.AddReviewers {
+		pr.ReviewRequests = append(pr.ReviewRequests, model.Reviewer{Login: login})
+	}
+	pr.ReviewRequests = slices.DeleteFunc(pr.ReviewRequests, func(r model.Reviewer) bool { return slices.Contains(edit.RemoveReviewers, r.Login) })
+	
+	if edit.Draft != nil {
+		pr.IsDraft = *edit.Draft
+	}
+	
+	pr.UpdatedAt = b.tick()
+	return nil
+}
+
+func (b *Backend) lookupPR(prNumber string) (*model.PRData, error) {
+	n, err := strconv.Atoi(prNumber)
+	if err == nil {
+		if pr, ok := b.prs[n]; ok {
+			return pr, nil
+		}
+	}
+	return nil, &backend.Error{Kind: backend.KindNotFound, StatusCode: 404, Message: fmt.Sprintf("pull request #%s not found", prNumber)}
+}
+
+func (b *Backend) ViewDiscussion(number string) (*model.DiscussionData, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("ViewDiscussion", number); err != nil {
+		return nil, err
+	}
+	d, err := b.lookupDiscussion(number)
+	if err != nil {
+		return nil, err
+	}
+	return cloneDiscussion(d), nil
+}
+
+func (b *Backend) ListDiscussions(category string, limit int) ([]model.DiscussionListItem, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("ListDiscussions", ""); err != nil {
+		return nil, err
+	}
+	if category != "" {
+		name, err := b.category(category)
+		if err != nil {
+			return nil, err
+		}
+		category = name
+	}
+	if limit <= 0 {
+		limit = backend.DefaultListLimit
+	}
+	
+	var items []model.DiscussionListItem
+	for _, d := range b.discs {
+		if category != "" && d.Category != category {
+			continue
+		}
+		items = append(items, model.DiscussionListItem{
+			Number:    d.Number,
+			Title:     d.Title,
+			Category:  d.Category,
+			Answered:  d.Answered,
+			Author:    b.Viewer,
+			URL:       fmt.Sprintf("https://github.com/%s/discussions/%d", b.Repo, d.Number),
+			UpdatedAt: d.UpdatedAt,
+		})
+	}
+	// Timestamps are unique, so this is the order GitHub uses.
+	slices.SortFunc(items, func(x, y model.DiscussionListItem) int { return strings.Compare(y.UpdatedAt, x.UpdatedAt) })
+	return items[:min(len(items), limit)], nil
+}
+
+func (b *Backend) EditDiscussion(number string, edit model.DiscussionEdit) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("EditDiscussion", number); err != nil {
+		return err
+	}
+	d, err := b.lookupDiscussion(number)
+	if err != nil {
+		return err
+	}
+	
+	if edit.Category != "" {
+		name, err := b.category(edit.Category)
+		if err != nil {
+			return err
+		}
+		d.Category = name
+	}
+	if strings.TrimSpace(edit.Title) != "" {
+		d.Title = edit.Title
+	}
+	d.Body = edit.Body
+	
+	d.UpdatedAt = b.tick()
+	return nil
+}
+
+func (b *Backend) CreateDiscussion(draft model.DiscussionDraft) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("CreateDiscussion", ""); err != nil {
+		return 0, err
+	}
+	category, err := b.category(draft.Category)
+	if err != nil {
+		return 0, err
+	}
+	
+	number := b.next
+	b.next++
+	b.discs[number] = &model.DiscussionData{
+		ID:        fmt.Sprintf("D_%d", number),
+		Number:    number,
+		Title:     draft.Title,
+		Body:      draft.Body,
+		Category:  category,
+		Comments:  []model.DiscussionComment{},
+		UpdatedAt: b.tick(),
+	}
+	return number, nil
+}
+
+func (b *Backend) lookupDiscussion(number string) (*model.DiscussionData, error) {
+	n, err := strconv.Atoi(number)
+	if err == nil {
+		if d, ok := b.discs[n]; ok {
+			return d, nil
+		}
+	}
+	return nil, &backend.Error{Kind: backend.KindNotFound, Message: fmt.Sprintf("discussion #%s not found", number)}
+}
+
+// category returns the name of a category as the repository spells it.
+func (b *Backend) category(name string) (string, error) {
+	for _, c := range b.Categories {
+		if strings.EqualFold(c, name) {
+			return c, nil
+		}
+	}
+	return "", &backend.Error{Kind: backend.KindInvalid, Message: fmt.Sprintf("unknown discussion category %q (available: %s)", name, strings.Join(b.Categories, ", "))}
+}
+
+func (b *Backend) CreateIssue(draft model.IssueDraft) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("CreateIssue", ""); err != nil {
+		return 0, err
+	}
+	
+	number := b.next
+	b.next++
+	issue := &model.IssueData{Number: number, Title: draft.Title, Body: draft.Body, State: "OPEN", UpdatedAt: b.tick()}
+	for _, name := range draft.Labels {
+		issue.Labels = append(issue.Labels, model.Label{Name: name})
+	}
+	for _, login := range draft.Assignees {
+		issue.Assignees = append(issue.Assignees, model.User{Login: login})
+	}
+	if draft.Milestone != "" {
+		issue.Milestone = &model.Milestone{Title: draft.Milestone}
+	}
+	b.issues[number] = issue
+	b.created[number] = creation{by: b.Viewer, at: b.now()}
+	return number, nil
+}
+
+func (b *Backend) ListCreatedIssues(since time.Time) ([]*model.IssueData, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("ListCreatedIssues", ""); err != nil {
+		return nil, err
+	}
+	
+	var issues []*model.IssueData
+	for number, issue := range b.issues {
+		if c, ok := b.created[number]; ok && c.by == b.Viewer && !c.at.Before(since) {
+			issues = append(issues, clone(issue))
+		}
+	}
+	slices.SortFunc(issues, func(x, y *model.IssueData) int { return y.Number - x.Number })
+	return issues, nil
+}
+
+func (b *Backend) setState(method, issueNumber, state string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin(method, issueNumber); err != nil {
+		return err
+	}
+	issue, err := b.lookup(issueNumber)
+	if err != nil {
+		return err
+	}
+	issue.State = state
+	issue.UpdatedAt = b.tick()
+	return nil
+}
+
+func (b *Backend) CloseIssue(issueNumber string) error {
+	return b.setState("CloseIssue", issueNumber, "CLOSED")
+}
+
+func (b *Backend) ReopenIssue(issueNumber string) error {
+	return b.setState("ReopenIssue", issueNumber, "OPEN")
+}
+
+func (b *Backend) ListComments(issueNumber string) ([]model.Comment, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("ListComments", issueNumber); err != nil {
+		return nil, err
+	}
+	issue, err := b.lookup(issueNumber)
+	if err != nil {
+		return nil, err
+	}
+	
+	comments := slices.Clone(b.comments[issue.Number])
+	for i := range comments {
+		comments[i].Mine = comments[i].Author == b.Viewer
+	}
+	return comments, nil
+}
+
+func (b *Backend) AddComment(issueNumber string, body string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("AddComment", issueNumber); err != nil {
+		return err
+	}
+	issue, err := b.lookup(issueNumber)
+	if err != nil {
+		return err
+	}
+	b.addComment(issue.Number, b.Viewer, body)
+	return nil
+}
+
+func (b *Backend) EditComment(id int64, body string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if err := b.begin("EditComment", strconv.FormatInt(id, 10)); err != nil {
+		return err
+	}
+	c := b.comment(id)
+	if c == nil {
+		return &backend.Error{Kind: backend.KindNotFound, StatusCode: 404, Message: fmt.Sprintf("comment %d not found", id)}
+	}
+	if c.Author != b.Viewer {
+		return &backend.Error{Kind: backend.KindForbidden, StatusCode: 403, Message: fmt.Sprintf("comment %d was written by @%s", id, c.Author)}
+	}
+	c.Body = body
+	return nil
+}
+
+func (b *Backend) Quotas() ([]backend.Quota, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Quota, nil
+}
\ No newline at end of file
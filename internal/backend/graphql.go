@@ -0,0 +1,350 @@
+package backend
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// GraphQLRunner executes a GraphQL query against the current repository. The
+// runner supplies the $owner and $name variables; vars adds string variables.
+// A response that carries GraphQL errors is still decoded into out so callers
+// can tell missing issues apart from real failures.
+type GraphQLRunner interface {
+	GraphQL(query string, vars map[string]string, out any) error
+}
+
+// viewIssuesBatchSize bounds how many issues are requested per GraphQL query.
+const viewIssuesBatchSize = 50
+
+const issueFragment = `fragment issueFields on Issue {
+  number
+  title
+  body
+  state
+  updatedAt
+  labels(first: 100) { nodes { name } }
+  assignees(first: 100) { nodes { login } }
+  milestone { title }
+}`
+
+type graphQLIssue struct {
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	State     string `json:"state"`
+	UpdatedAt string `json:"updatedAt"`
+	Labels    struct {
+		Nodes []model.Label `json:"nodes"`
+	} `json:"labels"`
+	Assignees struct {
+		Nodes []model.User `json:"nodes"`
+	} `json:"assignees"`
+	Milestone *model.Milestone `json:"milestone"`
+}
+
+func (g *graphQLIssue) issueData() *model.IssueData {
+	return &model.IssueData{
+		Number:    g.Number,
+		Title:     g.Title,
+		Body:      g.Body,
+		State:     g.State,
+		Labels:    g.Labels.Nodes,
+		Assignees: g.Assignees.Nodes,
+		Milestone: g.Milestone,
+		UpdatedAt: g.UpdatedAt,
+	}
+}
+
+type GraphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// ViewIssues fetches several issues with one GraphQL query per batch instead of
+// one request per issue.
+func ViewIssues(r GraphQLRunner, issueNumbers []string) (map[string]*model.IssueData, error) {
+	result := make(map[string]*model.IssueData, len(issueNumbers))
+	
+	for start := 0; start < len(issueNumbers); start += viewIssuesBatchSize {
+		end := min(start+viewIssuesBatchSize, len(issueNumbers))
+		
+		var query strings.Builder
+		query.WriteString("query($owner: String!, $name: String!) {\n  repository(owner: $owner, name: $name) {\n")
+		for _, n := range issueNumbers[start:end] {
+			if !model.IsNumeric(n) {
+				return nil, &Error{Kind: KindInvalid, Message: fmt.Sprintf("invalid issue number: %s", n)}
+			}
+			fmt.Fprintf(&query, "    i%s: issue(number: %s) { ...issueFields }\n", n, n)
+		}
+		query.WriteString("  }\n}\n")
+		query.WriteString(issueFragment)
+		
+		var response struct {
+			Data struct {
+				Repository map[string]*graphQLIssue `json:"repository"`
+			} `json:"data"`
+			Errors []GraphQLError `json:"errors"`
+		}
+		if err := r.GraphQL(query.String(), nil, &response); err != nil {
+			return nil, err
+		}
+		
+		for _, e := range response.Errors {
+			// Missing issues are reported per alias; everything else is fatal.
+			if e.Type != "NOT_FOUND" {
+				return nil, &Error{Message: "GraphQL error: " + e.Message}
+			}
+		}
+		
+		for _, issue := range response.Data.Repository {
+			if issue != nil {
+				result[strconv.Itoa(issue.Number)] = issue.issueData()
+			}
+		}
+	}
+	
+	return result, nil
+}
+
+// ListAllIssues fetches every issue in the given state with full content, one
+// GraphQL query per page of 100 issues. A non-zero since keeps only issues
+// updated at or after it.
+func ListAllIssues(r GraphQLRunner, state string, since time.Time) ([]*model.IssueData, error) {
+	states := ""
+	switch state {
+	case "open":
+		states = ", states: [OPEN]"
+	case "closed":
+		states = ", states: [CLOSED]"
+	case "all":
+	default:
+		return nil, &Error{Kind: KindInvalid, Message: fmt.Sprintf("invalid state %q: must be open, closed or all", state)}
+	}
+	
+	vars := map[string]string{}
+	params, filter := "", ""
+	if !since.IsZero() {
+		params, filter = ", $since: DateTime", ", filterBy: {since: $since}"
+		vars["since"] = since.UTC().Format(time.RFC3339)
+	}
+	
+	query := `query($owner: String!, $name: String!, $after: String` + params + `) {
+  repository(owner: $owner, name: $name) {
+    issues(first: 100, after: $after` + states + filter + `, orderBy: {field: CREATED_AT, direction: ASC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes { ...issueFields }
+    }
+  }
+}
+` + issueFragment
+
+	var issues []*model.IssueData
+	
+	for {
+		var response struct {
+			Data struct {
+				Repository struct {
+					Issues struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []*graphQLIssue `json:"nodes"`
+					} `json:"issues"`
+				} `json:"repository"`
+			} `json:"data"`
+			Errors []GraphQLError `json:"errors"`
+		}
+		if err := r.GraphQL(query, vars, &response); err != nil {
+			return nil, err
+		}
+		if len(response.Errors) > 0 {
+			return nil, &Error{Message: "GraphQL error: " + response.Errors[0].Message}
+		}
+		
+		page := response.Data.Repository.Issues
+		for _, issue := range page.Nodes {
+			issues = append(issues, issue.issueData())
+		}
+		
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		vars["after"] = page.PageInfo.EndCursor
+	}
+	
+	return issues, nil
+}
+
+type graphQLComment struct {
+	FullDatabaseID  string      `json:"fullDatabaseId"`
+	Author          *model.User `json:"author"`
+	CreatedAt       string      `json:"createdAt"`
+	Body            string      `json:"body"`
+	ViewerDidAuthor bool        `json:"viewerDidAuthor"`
+}
+
+// ListComments fetches every comment on an issue, one GraphQL query per page
+// of 100 comments.
+func ListComments(r GraphQLRunner, issueNumber string) ([]model.Comment, error) {
+	if !model.IsNumeric(issueNumber) {
+		return nil, &Error{Kind: KindInvalid, Message: fmt.Sprintf("invalid issue number: %s", issueNumber)}
+	}
+	
+	// fullDatabaseId is the REST comment ID; databaseId overflows 32 bits.
+	query := `query($owner: String!, $name: String!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    issue(number: ` + issueNumber + `) {
+      comments(first: 100, after: $after) {
+        pageInfo { hasNextPage endCursor }
+        nodes { fullDatabaseId author { login } createdAt body viewerDidAuthor }
+      }
+    }
+  }
+}
+`
+
+	var comments []model.Comment
+	vars := map[string]string{}
+	
+	for {
+		var response struct {
+			Data struct {
+				Repository struct {
+					Issue *struct {
+						Comments struct {
+							PageInfo struct {
+								HasNextPage bool   `json:"hasNextPage"`
+								EndCursor   string `json:"endCursor"`
+							} `json:"pageInfo"`
+							Nodes []graphQLComment `json:"nodes"`
+						} `json:"comments"`
+					} `json:"issue"`
+				} `json:"repository"`
+			} `json:"data"`
+			Errors []GraphQLError `json:"errors"`
+		}
+		if err := r.GraphQL(query, vars, &response); err != nil {
+			return nil, err
+		}
+		for _, e := range response.Errors {
+			if e.Type == "NOT_FOUND" {
+				return nil, &Error{Kind: KindNotFound, Message: fmt.Sprintf("issue #%s not found", issueNumber)}
+			}
+			return nil, &Error{Message: "GraphQL error: " + e.Message}
+		}
+		if response.Data.Repository.Issue == nil {
+			return nil, &Error{Kind: KindNotFound, Message: fmt.Sprintf("issue #%s not found", issueNumber)}
+		}
+		
+		page := response.Data.Repository.Issue.Comments
+		for _, node := range page.Nodes {
+			id, err := strconv.ParseInt(node.FullDatabaseID, 10, 64)
+			if err != nil {
+				return nil, &Error{Message: fmt.Sprintf("invalid comment ID %q", node.FullDatabaseID)}
+			}
+			// Comments by deleted accounts have no author.
+			author := "ghost"
+			if node.Author != nil {
+				author = node.Author.Login
+			}
+			comments = append(comments, model.Comment{
+				ID:        id,
+				Author:    author,
+				CreatedAt: node.CreatedAt,
+				Body:      node.Body,
+				Mine:      node.ViewerDidAuthor,
+			})
+		}
+		
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		vars["after"] = page.PageInfo.EndCursor
+	}
+	
+	return comments, nil
+}
+
+// ListCreatedIssues fetches the issues the viewer created at or after since,
+// newest first. GitHub filters by update time, which is never earlier than
+// creation time, so the creation time is checked here.
+func ListCreatedIssues(r GraphQLRunner, since time.Time) ([]*model.IssueData, error) {
+	var viewer struct {
+		Data struct {
+			Viewer model.User `json:"viewer"`
+		} `json:"data"`
+		Errors []GraphQLError `json:"errors"`
+	}
+	viewerQuery := `query($owner: String!, $name: String!) {
+  viewer { login }
+  repository(owner: $owner, name: $name) { id }
+}
+`
+	if err := r.GraphQL(viewerQuery, nil, &viewer); err != nil {
+		return nil, err
+	}
+	if len(viewer.Errors) > 0 {
+		return nil, &Error{Message: "GraphQL error: " + viewer.Errors[0].Message}
+	}
+	
+	query := `query($owner: String!, $name: String!, $login: String!, $since: DateTime!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    issues(first: 100, after: $after, filterBy: {createdBy: $login, since: $since}, orderBy: {field: CREATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes { createdAt ...issueFields }
+    }
+  }
+}
+` + issueFragment
+
+	var issues []*model.IssueData
+	vars := map[string]string{
+		"login": viewer.Data.Viewer.Login,
+		"since": since.UTC().Format(time.RFC3339),
+	}
+	
+	for {
+		var response struct {
+			Data struct {
+				Repository struct {
+					Issues struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []struct {
+							CreatedAt time.Time `json:"createdAt"`
+							graphQLIssue
+						} `json:"nodes"`
+					} `json:"issues"`
+				} `json:"repository"`
+			} `json:"data"`
+			Errors []GraphQLError `json:"errors"`
+		}
+		if err := r.GraphQL(query, vars, &response); err != nil {
+			return nil, err
+		}
+		if len(response.Errors) > 0 {
+			return nil, &Error{Message: "GraphQL error: " + response.Errors[0].Message}
+		}
+		
+		page := response.Data.Repository.Issues
+		for _, node := range page.Nodes {
+			if !node.CreatedAt.Before(since) {
+				issues = append(issues, node.issueData())
+			}
+		}
+		
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		vars["after"] = page.PageInfo.EndCursor
+	}
+	
+	return issues, nil
+}
\ No newline at end of file
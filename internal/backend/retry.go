@@ -0,0 +1,159 @@
+package backend
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Defaults of NewRetrier.
+const (
+	DefaultMaxAttempts = 4
+	DefaultBaseDelay   = time.Second
+	DefaultMaxDelay    = 30 * time.Second
+	DefaultMaxWait     = 5 * time.Minute
+)
+
+// secondaryLimitDelay is how long GitHub asks clients to wait after a
+// secondary rate limit that came without a Retry-After header.
+const secondaryLimitDelay = time.Minute
+
+// Retrier retries GitHub calls that failed for a reason that goes away by
+// itself: rate limits, 5xx responses and network errors. It is safe for
+// concurrent use, and once one call is rate limited every call through the
+// same Retrier waits until the limit is expected to have reset, so parallel
+// workers do not keep hammering the API.
+type Retrier struct {
+	// MaxAttempts bounds how often a call is tried, the first try included.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry of a failed call. It
+	// doubles with every retry up to MaxDelay and is jittered.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MaxWait is the longest a rate limit is waited out; a limit that
+	// resets later fails the call right away.
+	MaxWait time.Duration
+	// Log receives a line for every retry when set (verbose mode).
+	Log io.Writer
+	// Sleep waits between attempts; tests replace it.
+	Sleep func(time.Duration)
+	
+	mu    sync.Mutex
+	until time.Time
+}
+
+func NewRetrier() *Retrier {
+	return &Retrier{
+		MaxAttempts: DefaultMaxAttempts,
+		BaseDelay:   DefaultBaseDelay,
+		MaxDelay:    DefaultMaxDelay,
+		MaxWait:     DefaultMaxWait,
+	}
+}
+
+// Do calls op until it succeeds, fails for good or runs out of attempts.
+// Calls that are not idempotent, such as creating an issue, are only retried
+// after a rate limit, which GitHub answers without doing anything; after a
+// 5xx or a network error they may already have taken effect.
+func (r *Retrier) Do(idempotent bool, op func() error) error {
+	if r == nil {
+		return op()
+	}
+	
+	for attempt := 1; ; attempt++ {
+		r.wait()
+		err := op()
+		if err == nil {
+			return nil
+		}
+		
+		e, ok := err.(*Error)
+		if !ok || attempt >= r.MaxAttempts {
+			return err
+		}
+		
+		var delay time.Duration
+		switch {
+		case e.Kind == KindRateLimited:
+			delay = e.RetryAfter
+			if delay <= 0 {
+				delay = max(r.backoff(attempt), secondaryLimitDelay)
+			}
+			if delay > r.MaxWait {
+				return &Error{Kind: e.Kind, StatusCode: e.StatusCode, RetryAfter: e.RetryAfter,
+					Message: fmt.Sprintf("%s (resets in %s)", e.Message, delay.Round(time.Second))}
+			}
+			r.pause(delay)
+		case e.Kind == KindUnavailable && idempotent:
+			delay = e.RetryAfter
+			if delay <= 0 {
+				delay = r.backoff(attempt)
+			}
+		default:
+			return err
+		}
+		
+		if r.Log != nil {
+			fmt.Fprintf(r.Log, "%s; retrying in %s (attempt %d of %d)\n", e.Message, delay.Round(time.Millisecond), attempt+1, r.MaxAttempts)
+		}
+		if e.Kind != KindRateLimited {
+			r.sleep(delay)
+		}
+	}
+}
+
+// backoff returns the jittered exponential delay before retry number
+// attempt: a random duration between half and all of BaseDelay·2^(attempt-1),
+// capped at MaxDelay.
+func (r *Retrier) backoff(attempt int) time.Duration {
+	d := r.BaseDelay << (attempt - 1)
+	if d > r.MaxDelay || d <= 0 {
+		d = r.MaxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// pause makes every call wait d from now, unless they already wait longer.
+func (r *Retrier) pause(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if until := time.Now().Add(d); until.After(r.until) {
+		r.until = until
+	}
+}
+
+func (r *Retrier) wait() {
+	r.mu.Lock()
+	d := time.Until(r.until)
+	r.mu.Unlock()
+	if d > 0 {
+		r.sleep(d)
+	}
+}
+
+func (r *Retrier) sleep(d time.Duration) {
+	if r.Sleep != nil {
+		r.Sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+// Quota is the rate limit GitHub reports for one resource (core, graphql,
+// search, ...).
+type Quota struct {
+	Resource  string    `json:"resource"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// QuotaReporter is implemented by backends that can tell the remaining rate
+// limit quota, for verbose mode.
+type QuotaReporter interface {
+	// Quotas returns the quotas of the resources used so far; nil when no
+	// call has been made.
+	Quotas() ([]Quota, error)
+}
\ No newline at end of file
@@ -0,0 +1,307 @@
+// Package config loads the project configuration file, .ghi.yaml.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the configuration file. It is looked up in the
+// current directory and its parents, like git looks up .git.
+const FileName = ".ghi.yaml"
+
+const (
+	DefaultIssuesDir = "issues"
+	DefaultFilename  = "{number}.md"
+)
+
+// Fields are the frontmatter fields ghi can sync.
+var Fields = []string{"title", "labels", "assignees", "milestone", "state"}
+
+// Config is the project configuration. The zero value is not usable; start
+// from Default.
+type Config struct {
+	// IssuesDir is where issue files live. Relative paths in the file are
+	// relative to the file's directory; after loading it is relative to the
+	// current directory.
+	IssuesDir string `yaml:"issues_dir"`
+	// Filename names new issue files. {number} is the issue number and
+	// {slug} a lowercased, dash-separated form of the title.
+	Filename string `yaml:"filename"`
+	// Repo is the default "owner/name" repository, used when --repo is not
+	// given.
+	Repo      string `yaml:"repo"`
+	Workspace bool   `yaml:"workspace"`
+	Backend   string `yaml:"backend"`
+	// Timeout bounds a single GitHub operation.
+	Timeout time.Duration `yaml:"timeout"`
+	// Fields lists the frontmatter fields that are written on pull and
+	// pushed; the others are left alone on GitHub.
+	Fields []string     `yaml:"fields"`
+	List   ListDefaults `yaml:"list"`
+	
+	// Path is the file the configuration was read from, empty when none
+	// was found.
+	Path string `yaml:"-"`
+	
+	file *filePattern
+}
+
+// ListDefaults are filters ghi list applies unless overridden on the command
+// line.
+type ListDefaults struct {
+	State     string   `yaml:"state"`
+	Labels    []string `yaml:"labels"`
+	Assignee  string   `yaml:"assignee"`
+	Author    string   `yaml:"author"`
+	Mention   string   `yaml:"mention"`
+	Milestone string   `yaml:"milestone"`
+	Search    string   `yaml:"search"`
+	Limit     int      `yaml:"limit"`
+}
+
+// Default returns the configuration used when there is no .ghi.yaml.
+func Default() *Config {
+	c := &Config{
+		IssuesDir: DefaultIssuesDir,
+		Filename:  DefaultFilename,
+		Timeout:   backend.DefaultTimeout,
+		Fields:    slices.Clone(Fields),
+	}
+	c.file, _ = compilePattern(c.Filename)
+	return c
+}
+
+// Find loads the nearest .ghi.yaml from the current directory upwards, or
+// returns Default when there is none.
+func Find() (*Config, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	
+	for dir := cwd; ; dir = filepath.Dir(dir) {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return Load(path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if filepath.Dir(dir) == dir {
+			return Default(), nil
+		}
+	}
+}
+
+// Load reads a configuration file. Settings it does not mention keep their
+// defaults.
+func Load(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	
+	c := Default()
+	dec := yaml.NewDecoder(strings.NewReader(string(raw)))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	c.Path = path
+	
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	
+	if !filepath.IsAbs(c.IssuesDir) {
+		c.IssuesDir = filepath.Join(filepath.Dir(path), c.IssuesDir)
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, c.IssuesDir); err == nil {
+			c.IssuesDir = rel
+		}
+	}
+	
+	return c, nil
+}
+
+func (c *Config) validate() error {
+	if c.IssuesDir == "" {
+		return errors.New("issues_dir must not be empty")
+	}
+	if c.Repo != "" {
+		if _, _, err := backend.SplitRepo(c.Repo); err != nil {
+			return err
+		}
+	}
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	for _, f := range c.Fields {
+		if !slices.Contains(Fields, f) {
+			return fmt.Errorf("unknown field %q in fields: use %s", f, strings.Join(Fields, ", "))
+		}
+	}
+	
+	pattern, err := compilePattern(c.Filename)
+	if err != nil {
+		return err
+	}
+	c.file = pattern
+	return nil
+}
+
+// Syncs reports whether a frontmatter field is synced.
+func (c *Config) Syncs(field string) bool {
+	return slices.Contains(c.Fields, field)
+}
+
+// FilterFrontmatter clears the fields that are not synced, which makes them
+// unmanaged: absent from pulled files and ignored by push.
+func (c *Config) FilterFrontmatter(fm model.Frontmatter) model.Frontmatter {
+	if !c.Syncs("title") {
+		fm.Title = ""
+	}
+	if !c.Syncs("labels") {
+		fm.Labels = nil
+	}
+	if !c.Syncs("assignees") {
+		fm.Assignees = nil
+	}
+	if !c.Syncs("milestone") {
+		fm.Milestone, fm.NoMilestone = "", false
+	}
+	if !c.Syncs("state") {
+		fm.State = ""
+	}
+	return fm
+}
+
+// ApplyListDefaults fills the filters opts leaves unset from the configured
+// defaults.
+func (c *Config) ApplyListDefaults(opts *backend.ListOptions) {
+	d := c.List
+	if opts.State == "" {
+		opts.State = d.State
+	}
+	if len(opts.Labels) == 0 {
+		opts.Labels = d.Labels
+	}
+	if opts.Assignee == "" {
+		opts.Assignee = d.Assignee
+	}
+	if opts.Author == "" {
+		opts.Author = d.Author
+	}
+	if opts.Mention == "" {
+		opts.Mention = d.Mention
+	}
+	if opts.Milestone == "" {
+		opts.Milestone = d.Milestone
+	}
+	if opts.Search == "" {
+		opts.Search = d.Search
+	}
+	if opts.Limit == 0 {
+		opts.Limit = d.Limit
+	}
+}
+
+// IssueFileName returns the file name for a new issue file.
+func (c *Config) IssueFileName(issueNumber string, title string) string {
+	return c.file.name(issueNumber, Slug(title))
+}
+
+// MatchIssueFile returns the issue number of a file name that fits the
+// filename template with any slug, so files keep being found after their
+// title (or the name itself) changes.
+func (c *Config) MatchIssueFile(name string) (string, bool) {
+	m := c.file.match.FindStringSubmatch(name)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// slugSeparator matches {slug} with one separator next to it, which is
+// dropped together with an empty slug.
+var slugSeparator = regexp.MustCompile(`\{slug\}[-_.]|[-_.]?\{slug\}`)
+
+type filePattern struct {
+	template string
+	match    *regexp.Regexp
+}
+
+func compilePattern(template string) (*filePattern, error) {
+	switch {
+	case strings.Count(template, "{number}") != 1:
+		return nil, fmt.Errorf("filename %q must contain {number} once", template)
+	case strings.Count(template, "{slug}") > 1:
+		return nil, fmt.Errorf("filename %q must contain {slug} at most once", template)
+	case strings.ContainsAny(template, `/\`):
+		return nil, fmt.Errorf("filename %q must not contain a directory", template)
+	case !strings.HasSuffix(template, ".md"):
+		return nil, fmt.Errorf("filename %q must end in .md", template)
+	}
+	
+	// The slug and its separator are optional in matched names, and the
+	// slug may be anything.
+	expr := regexp.QuoteMeta(template)
+	if loc := slugSeparator.FindStringIndex(template); loc != nil {
+		slug := regexp.QuoteMeta(template[loc[0]:loc[1]])
+		slug = strings.Replace(slug, regexp.QuoteMeta("{slug}"), ".*", 1)
+		expr = regexp.QuoteMeta(template[:loc[0]]) + "(?:" + slug + ")?" + regexp.QuoteMeta(template[loc[1]:])
+	}
+	expr = strings.Replace(expr, regexp.QuoteMeta("{number}"), "([0-9]+)", 1)
+	
+	match, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("filename %q: %w", template, err)
+	}
+	return &filePattern{template: template, match: match}, nil
+}
+
+func (p *filePattern) name(issueNumber string, slug string) string {
+	name := p.template
+	if slug == "" {
+		name = slugSeparator.ReplaceAllString(name, "")
+	}
+	return strings.NewReplacer("{number}", issueNumber, "{slug}", slug).Replace(name)
+}
+
+// maxSlugLength keeps file names readable for long titles.
+const maxSlugLength = 50
+
+// Slug turns a title into a lowercase, dash-separated file name part. Letters
+// and digits of any script are kept.
+func Slug(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+	return b.String()
+}
\ No newline at end of file
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Fix login redirect":      "fix-login-redirect",
+		"  [bug] Crash on start!": "bug-crash-on-start",
+		"v2.0: API/REST":          "v2-0-api-rest",
+		"日本語 タイトル":                "日本語-タイトル",
+		"":                        "",
+		"!!!":                     "",
+		strings.Repeat("a", 80):   strings.Repeat("a", maxSlugLength),
+	}
+	for title, want := range tests {
+		if got := Slug(title); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestIssueFileNames(t *testing.T) {
+	tests := []struct {
+		template string
+		title    string
+		want     string
+		matches  map[string]string
+	}{
+		{
+			template: "{number}.md",
+			title:    "Anything",
+			want:     "42.md",
+			matches:  map[string]string{"42.md": "42", "42-x.md": "", "x.md": ""},
+		},
+		{
+			template: "{number}-{slug}.md",
+			title:    "Fix login",
+			want:     "42-fix-login.md",
+			matches:  map[string]string{"42-fix-login.md": "42", "42-renamed.md": "42", "42.md": "42", "a-42.md": ""},
+		},
+		{
+			template: "{slug}-{number}.md",
+			title:    "",
+			want:     "42.md",
+			matches:  map[string]string{"fix-login-42.md": "42", "42.md": "42", "v2-release-7.md": "7"},
+		},
+		{
+			template: "issue_{number}_{slug}.md",
+			title:    "Fix",
+			want:     "issue_42_fix.md",
+			matches:  map[string]string{"issue_42_fix.md": "42", "issue_42.md": "42", "42_fix.md": ""},
+		},
+	}
+	for _, tt := range tests {
+		c := Default()
+		c.Filename = tt.template
+		if err := c.validate(); err != nil {
+			t.Fatalf("%s: %v", tt.template, err)
+		}
+		if got := c.IssueFileName("42", tt.title); got != tt.want {
+			t.Errorf("%s: IssueFileName = %q, want %q", tt.template, got, tt.want)
+		}
+		for name, want := range tt.matches {
+			got, ok := c.MatchIssueFile(name)
+			if got != want || ok != (want != "") {
+				t.Errorf("%s: MatchIssueFile(%q) = %q, %v; want %q", tt.template, name, got, ok, want)
+			}
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+	
+	c, err := Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Path != "" || c.IssuesDir != DefaultIssuesDir || c.Timeout != 30*time.Second || len(c.Fields) != len(Fields) {
+		t.Errorf("default config = %+v", c)
+	}
+	
+	content := "issues_dir: tracker\nfilename: \"{number}-{slug}.md\"\nrepo: octo/widgets\ntimeout: 1m\nfields: [title, state]\nlist:\n  labels: [bug]\n  limit: 5\n"
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	
+	c, err = Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("..", "..", "tracker"); c.IssuesDir != want {
+		t.Errorf("IssuesDir = %q, want %q", c.IssuesDir, want)
+	}
+	if c.Repo != "octo/widgets" || c.Timeout != time.Minute || c.List.Limit != 5 || c.List.Labels[0] != "bug" {
+		t.Errorf("config = %+v", c)
+	}
+	if c.Syncs("labels") || !c.Syncs("state") {
+		t.Errorf("fields = %v", c.Fields)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":      "issue_dir: x\n",
+		"bad yaml":         "fields: [title\n",
+		"bad field":        "fields: [body]\n",
+		"no number":        "filename: \"{slug}.md\"\n",
+		"not markdown":     "filename: \"{number}.txt\"\n",
+		"directory":        "filename: \"x/{number}.md\"\n",
+		"bad repo":         "repo: widgets\n",
+		"negative timeout": "timeout: -1s\n",
+	}
+	for name, content := range tests {
+		path := filepath.Join(t.TempDir(), FileName)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,105 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+)
+
+// SplitLines splits s into lines, keeping each line's trailing "\n" so that
+// joining the result reproduces s byte for byte.
+func SplitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Match pairs a line index in a with the equal line index in b.
+type Match struct {
+	A int
+	B int
+}
+
+// Matches returns a longest common subsequence of a and b as index pairs in
+// increasing order. Common prefixes and suffixes are matched directly, and
+// the region that actually changed is split in half recursively (Hirschberg's
+// algorithm) so memory stays linear in the input however much was rewritten.
+func Matches(a, b []string) []Match {
+	var matches []Match
+	
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches = append(matches, Match{A: prefix, B: prefix})
+		prefix++
+	}
+	
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	
+	matches = appendLCS(matches, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix, prefix)
+	
+	for k := suffix; k > 0; k-- {
+		matches = append(matches, Match{A: len(a) - k, B: len(b) - k})
+	}
+	
+	return matches
+}
+
+// appendLCS appends a longest common subsequence of a and b to matches,
+// offsetting the indexes by offA and offB.
+func appendLCS(matches []Match, a, b []string, offA, offB int) []Match {
+	if len(a) == 0 || len(b) == 0 {
+		return matches
+	}
+	if len(a) == 1 {
+		if j := slices.Index(b, a[0]); j >= 0 {
+			matches = append(matches, Match{A: offA, B: offB + j})
+		}
+		return matches
+	}
+	
+	// Split b where the LCS of the top half of a with b[:k] plus that of the
+	// bottom half with b[k:] is longest; some LCS passes through that point.
+	mid := len(a) / 2
+	head := lcsLengths(a[:mid], b)
+	tail := lcsLengths(reversed(a[mid:]), reversed(b))
+	split, best := 0, int32(-1)
+	for k := range head {
+		if n := head[k] + tail[len(b)-k]; n > best {
+			split, best = k, n
+		}
+	}
+	
+	matches = appendLCS(matches, a[:mid], b[:split], offA, offB)
+	return appendLCS(matches, a[mid:], b[split:], offA+mid, offB+split)
+}
+
+// lcsLengths returns the LCS length of a with every prefix b[:j], keeping
+// only one row of the table at a time.
+func lcsLengths(a, b []string) []int32 {
+	prev := make([]int32, len(b)+1)
+	cur := make([]int32, len(b)+1)
+	for _, line := range a {
+		for j := range b {
+			if line == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+func reversed(lines []string) []string {
+	r := slices.Clone(lines)
+	slices.Reverse(r)
+	return r
+}
\ No newline at end of file
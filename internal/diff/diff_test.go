@@ -0,0 +1,122 @@
+package diff
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHunks(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	new := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl"
+	
+	hunks := Hunks(old, new, 1)
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2: %+v", len(hunks), hunks)
+	}
+	if got := hunks[0].Header(); got != "@@ -1,3 +1,3 @@" {
+		t.Errorf("first header = %s", got)
+	}
+	if want := []string{" a", "-b", "+B", " c"}; !slices.Equal(hunks[0].Lines, want) {
+		t.Errorf("first hunk = %q, want %q", hunks[0].Lines, want)
+	}
+	if want := []string{" k", "+l", noNewline}; hunks[1].Header() != "@@ -11,1 +11,2 @@" || !slices.Equal(hunks[1].Lines, want) {
+		t.Errorf("second hunk = %s %q", hunks[1].Header(), hunks[1].Lines)
+	}
+	
+	// Changes closer than twice the context share a hunk.
+	if hunks := Hunks(old, strings.Replace(new, "e\n", "E\n", 1), 1); len(hunks) != 2 || hunks[0].Header() != "@@ -1,6 +1,6 @@" {
+		t.Errorf("merged hunks = %+v", hunks)
+	}
+	
+	if hunks := Hunks("", "new\n", 3); len(hunks) != 1 || hunks[0].Header() != "@@ -0,0 +1,1 @@" {
+		t.Errorf("hunks of an added text = %+v", hunks)
+	}
+	if Hunks(old, old, 3) != nil {
+		t.Error("equal texts have hunks")
+	}
+}
+
+func TestWriteWords(t *testing.T) {
+	var out strings.Builder
+	WriteWords(&out, Hunks("Fix the login page.\n", "Fix the signup page!\n", 3))
+	if want := "@@ -1,1 +1,1 @@\nFix the [-login-]{+signup+} page[-.-]{+!+}\n"; out.String() != want {
+		t.Errorf("WriteWords = %q, want %q", out.String(), want)
+	}
+}
+
+func TestMatchesLargeRewrite(t *testing.T) {
+	// Every fifth line survives a rewrite of a long body.
+	var old, new []string
+	for i := range 5000 {
+		old = append(old, fmt.Sprintf("old line %d\n", i))
+		if i%5 == 0 {
+			new = append(new, old[i])
+		} else {
+			new = append(new, fmt.Sprintf("new line %d\n", i))
+		}
+	}
+	
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	matches := Matches(old, new)
+	runtime.ReadMemStats(&after)
+	if len(matches) != 1000 {
+		t.Errorf("got %d matches, want 1000", len(matches))
+	}
+	for _, m := range matches {
+		if old[m.A] != new[m.B] {
+			t.Fatalf("match %+v pairs different lines", m)
+		}
+	}
+	// A full table would take 100MB here.
+	if n := after.TotalAlloc - before.TotalAlloc; n > 10<<20 {
+		t.Errorf("Matches allocated %d bytes", n)
+	}
+	
+	// The word diff of such a hunk falls back to lines.
+	hunks := Hunks(strings.Join(old, ""), strings.Join(new, ""), 3)
+	var words, lines strings.Builder
+	WriteWords(&words, hunks)
+	WriteUnified(&lines, hunks)
+	if words.String() != lines.String() {
+		t.Error("WriteWords of a rewritten body is not a line diff")
+	}
+}
+
+func TestMatchesIsLongest(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"abcbdab", "bdcaba"},
+		{"xaxbxcx", "abc"},
+		{"abc", "def"},
+		{"aaaa", "aa"},
+		{"abcdefgh", "hgfedcba"},
+	}
+	for _, tt := range tests {
+		a, b := strings.Split(tt.a, ""), strings.Split(tt.b, "")
+		matches := Matches(a, b)
+		if want := lcsLengths(a, b)[len(b)]; len(matches) != int(want) {
+			t.Errorf("Matches(%s, %s) has %d pairs, want %d", tt.a, tt.b, len(matches), want)
+		}
+		for k, m := range matches {
+			if a[m.A] != b[m.B] || k > 0 && (m.A <= matches[k-1].A || m.B <= matches[k-1].B) {
+				t.Errorf("Matches(%s, %s) = %+v", tt.a, tt.b, matches)
+				break
+			}
+		}
+	}
+}
+
+func TestFieldChanges(t *testing.T) {
+	if c := Set("labels", []string{"bug", "ui"}, []string{"ui", "docs"}); c == nil || c.String() != "labels: added docs; removed bug" {
+		t.Errorf("Set = %+v", c)
+	}
+	if c := Set("labels", []string{"bug", "ui"}, []string{"ui", "bug"}); c != nil {
+		t.Errorf("reordered set changed: %+v", c)
+	}
+	if c := Scalar("title", "a", "b"); c == nil || c.String() != `title: "a" -> "b"` {
+		t.Errorf("Scalar = %+v", c)
+	}
+}
\ No newline at end of file
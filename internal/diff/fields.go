@@ -0,0 +1,61 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FieldChange is a changed frontmatter field. Scalars have Old and New; lists
+// are compared as sets and have Added and Removed.
+type FieldChange struct {
+	Field   string   `json:"field"`
+	Old     string   `json:"old,omitempty"`
+	New     string   `json:"new,omitempty"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Scalar returns the change of a scalar field, or nil if it is unchanged.
+func Scalar(field, old, new string) *FieldChange {
+	if old == new {
+		return nil
+	}
+	return &FieldChange{Field: field, Old: old, New: new}
+}
+
+// Set returns the change of a list field whose order does not matter, or nil
+// if both lists hold the same items.
+func Set(field string, old, new []string) *FieldChange {
+	c := &FieldChange{Field: field}
+	for _, item := range new {
+		if !slices.Contains(old, item) && !slices.Contains(c.Added, item) {
+			c.Added = append(c.Added, item)
+		}
+	}
+	for _, item := range old {
+		if !slices.Contains(new, item) && !slices.Contains(c.Removed, item) {
+			c.Removed = append(c.Removed, item)
+		}
+	}
+	if c.Added == nil && c.Removed == nil {
+		return nil
+	}
+	return c
+}
+
+// String describes the change on one line, e.g. `title: "a" -> "b"` or
+// "labels: added ui; removed bug".
+func (c FieldChange) String() string {
+	if c.Added == nil && c.Removed == nil {
+		return fmt.Sprintf("%s: %q -> %q", c.Field, c.Old, c.New)
+	}
+	var parts []string
+	if c.Added != nil {
+		parts = append(parts, "added "+strings.Join(c.Added, ", "))
+	}
+	if c.Removed != nil {
+		parts = append(parts, "removed "+strings.Join(c.Removed, ", "))
+	}
+	return c.Field + ": " + strings.Join(parts, "; ")
+}
\ No newline at end of file
@@ -0,0 +1,183 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// noNewline follows a hunk line that has no trailing newline, as in git.
+const noNewline = `\ No newline at end of file`
+
+// Hunk is a group of changed lines with their context, as in a unified diff.
+// Every line starts with ' ' (context), '-' (old only) or '+' (new only) and
+// has no trailing newline.
+type Hunk struct {
+	OldStart int      `json:"oldStart"`
+	OldLines int      `json:"oldLines"`
+	NewStart int      `json:"newStart"`
+	NewLines int      `json:"newLines"`
+	Lines    []string `json:"lines"`
+}
+
+// Header returns the "@@ -a,b +c,d @@" line of the hunk.
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
+
+type lineOp struct {
+	kind byte
+	line string
+}
+
+// lineOps turns the matches of a and b into an edit script: every line of
+// a and b once, deletions before insertions between two matched lines.
+func lineOps(a, b []string) []lineOp {
+	var ops []lineOp
+	i, j := 0, 0
+	for _, m := range append(Matches(a, b), Match{A: len(a), B: len(b)}) {
+		for ; i < m.A; i++ {
+			ops = append(ops, lineOp{'-', a[i]})
+		}
+		for ; j < m.B; j++ {
+			ops = append(ops, lineOp{'+', b[j]})
+		}
+		if i < len(a) {
+			ops = append(ops, lineOp{' ', a[i]})
+			i++
+			j++
+		}
+	}
+	return ops
+}
+
+// Hunks compares old and new line by line and returns the changes with
+// context lines around them. Changes closer than twice the context share a
+// hunk. Equal texts have no hunks.
+func Hunks(old, new string, context int) []Hunk {
+	ops := lineOps(SplitLines(old), SplitLines(new))
+	
+	// oldAt[k] and newAt[k] count the lines before ops[k].
+	oldAt := make([]int, len(ops)+1)
+	newAt := make([]int, len(ops)+1)
+	for k, op := range ops {
+		oldAt[k+1], newAt[k+1] = oldAt[k], newAt[k]
+		if op.kind != '+' {
+			oldAt[k+1]++
+		}
+		if op.kind != '-' {
+			newAt[k+1]++
+		}
+	}
+	
+	var hunks []Hunk
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+		
+		last := k
+		for e := k + 1; e < len(ops) && e-last <= 2*context+1; e++ {
+			if ops[e].kind != ' ' {
+				last = e
+			}
+		}
+		start, end := max(k-context, 0), min(last+context+1, len(ops))
+		
+		h := Hunk{
+			OldStart: oldAt[start] + 1,
+			OldLines: oldAt[end] - oldAt[start],
+			NewStart: newAt[start] + 1,
+			NewLines: newAt[end] - newAt[start],
+		}
+		// An empty side starts at the line before it, like diff -u.
+		if h.OldLines == 0 {
+			h.OldStart--
+		}
+		if h.NewLines == 0 {
+			h.NewStart--
+		}
+		for _, op := range ops[start:end] {
+			line, hasNewline := strings.CutSuffix(op.line, "\n")
+			h.Lines = append(h.Lines, string(op.kind)+line)
+			if !hasNewline {
+				h.Lines = append(h.Lines, noNewline)
+			}
+		}
+		hunks = append(hunks, h)
+		k = end
+	}
+	
+	return hunks
+}
+
+// WriteUnified writes hunks in unified diff format.
+func WriteUnified(w io.Writer, hunks []Hunk) {
+	for _, h := range hunks {
+		fmt.Fprintln(w, h.Header())
+		for _, line := range h.Lines {
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+var wordRegex = regexp.MustCompile(`\s+|\w+|[^\s\w]`)
+
+// maxWordCells bounds the words compared in one hunk (old times new). Larger
+// hunks, such as a rewritten body, are written line by line instead.
+const maxWordCells = 1 << 22
+
+// WriteWords writes hunks as a word diff: the new text with removed words
+// as [-...-] and added ones as {+...+}, like git diff --word-diff.
+func WriteWords(w io.Writer, hunks []Hunk) {
+	for _, h := range hunks {
+		var old, new strings.Builder
+		for i, line := range h.Lines {
+			if line == noNewline {
+				continue
+			}
+			text := line[1:]
+			if i+1 >= len(h.Lines) || h.Lines[i+1] != noNewline {
+				text += "\n"
+			}
+			if line[0] != '+' {
+				old.WriteString(text)
+			}
+			if line[0] != '-' {
+				new.WriteString(text)
+			}
+		}
+		
+		a := wordRegex.FindAllString(old.String(), -1)
+		b := wordRegex.FindAllString(new.String(), -1)
+		if len(a)*len(b) > maxWordCells {
+			WriteUnified(w, []Hunk{h})
+			continue
+		}
+		
+		fmt.Fprintln(w, h.Header())
+		var out strings.Builder
+		i, j := 0, 0
+		for _, m := range append(Matches(a, b), Match{A: len(a), B: len(b)}) {
+			if i < m.A {
+				out.WriteString("[-" + strings.Join(a[i:m.A], "") + "-]")
+			}
+			if j < m.B {
+				out.WriteString("{+" + strings.Join(b[j:m.B], "") + "+}")
+			}
+			i, j = m.A, m.B
+			if i < len(a) {
+				out.WriteString(a[i])
+				i++
+				j++
+			}
+		}
+		text := out.String()
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		io.WriteString(w, text)
+	}
+}
\ No newline at end of file
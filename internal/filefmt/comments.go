@@ -0,0 +1,134 @@
+package filefmt
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// The comments section follows the issue body. Every marker is an HTML
+// comment on a line of its own, so the file still renders as markdown.
+const (
+	commentsHeader   = "<!-- ghi:comments: only comments marked 'editable' can be changed -->"
+	commentsPrefix   = "<!-- ghi:comments"
+	commentEnd       = "<!-- /ghi:comment -->"
+	newCommentHeader = "<!-- ghi:new-comment: text below is posted as a new comment on push -->"
+	newCommentPrefix = "<!-- ghi:new-comment"
+)
+
+var commentStartRegex = regexp.MustCompile(`^<!-- ghi:comment id=([0-9]+) author=@(\S*) created=(\S*)( editable)? -->$`)
+
+// Lines of the issue body or of a comment that could be read as markers are
+// written with an extra leading backslash, and lines that already start with
+// backslashes before a marker get one more, so unescaping is exact.
+var (
+	markerLineRegex  = regexp.MustCompile(`(?m)^(\\*<!-- /?ghi:)`)
+	escapedLineRegex = regexp.MustCompile(`(?m)^\\(\\*<!-- /?ghi:)`)
+)
+
+func escapeMarkers(s string) string {
+	return markerLineRegex.ReplaceAllString(s, `\$1`)
+}
+
+func unescapeMarkers(s string) string {
+	return escapedLineRegex.ReplaceAllString(s, "$1")
+}
+
+// commentsStart returns the offset of the comments header line, or -1.
+func commentsStart(body []byte) int {
+	if bytes.HasPrefix(body, []byte(commentsPrefix)) {
+		return 0
+	}
+	if i := bytes.Index(body, []byte("\n"+commentsPrefix)); i >= 0 {
+		return i + 1
+	}
+	return -1
+}
+
+// SplitComments separates the comments section from an issue body. The
+// section is nil when the body has none.
+func SplitComments(body []byte) ([]byte, *model.CommentSection, error) {
+	start := commentsStart(body)
+	if start < 0 {
+		return body, nil, nil
+	}
+	
+	// AppendComments puts the header on a new line; that newline is not part
+	// of the issue body.
+	issueBody := []byte(unescapeMarkers(string(body[:max(start-1, 0)])))
+	
+	lines := strings.SplitAfter(string(body[start:]), "\n")
+	section := &model.CommentSection{}
+	
+	var current *model.Comment
+	var text strings.Builder
+	
+	for i := 1; i < len(lines); i++ {
+		line := lines[i]
+		marker := strings.TrimRight(line, "\r\n")
+		
+		switch {
+		case current != nil && marker == commentEnd:
+			current.Body = unescapeMarkers(strings.TrimSuffix(text.String(), "\n"))
+			section.Comments = append(section.Comments, *current)
+			current = nil
+		case current != nil:
+			text.WriteString(line)
+		case strings.HasPrefix(marker, newCommentPrefix):
+			section.New = strings.Join(lines[i+1:], "")
+			return issueBody, section, nil
+		case strings.HasPrefix(marker, "<!-- ghi:comment "):
+			m := commentStartRegex.FindStringSubmatch(marker)
+			if m == nil {
+				return nil, nil, fmt.Errorf("malformed comment marker: %s", marker)
+			}
+			id, err := strconv.ParseInt(m[1], 10, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("malformed comment marker: %s", marker)
+			}
+			current = &model.Comment{ID: id, Author: m[2], CreatedAt: m[3], Mine: m[4] != ""}
+			text.Reset()
+		case strings.TrimSpace(marker) != "":
+			return nil, nil, fmt.Errorf("text outside a comment in the comments section: %q (write new comments below the new-comment marker)", marker)
+		}
+	}
+	
+	if current != nil {
+		return nil, nil, fmt.Errorf("comment %d is missing its closing marker", current.ID)
+	}
+	
+	return issueBody, section, nil
+}
+
+// AppendComments writes a comments section after an issue body. A nil
+// section leaves the body unchanged. Marker lines in the body and comments
+// are escaped, so SplitComments(AppendComments(b, s)) returns b and s again
+// byte for byte whatever they contain.
+func AppendComments(body []byte, section *model.CommentSection) []byte {
+	if section == nil {
+		return body
+	}
+	
+	var buf bytes.Buffer
+	buf.WriteString(escapeMarkers(string(body)))
+	buf.WriteString("\n" + commentsHeader + "\n")
+	
+	for _, c := range section.Comments {
+		editable := ""
+		if c.Mine {
+			editable = " editable"
+		}
+		fmt.Fprintf(&buf, "\n<!-- ghi:comment id=%d author=@%s created=%s%s -->\n", c.ID, c.Author, c.CreatedAt, editable)
+		buf.WriteString(escapeMarkers(c.Body))
+		buf.WriteString("\n" + commentEnd + "\n")
+	}
+	
+	buf.WriteString("\n" + newCommentHeader + "\n")
+	buf.WriteString(section.New)
+	
+	return buf.Bytes()
+}
\ No newline at end of file
@@ -0,0 +1,61 @@
+package filefmt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func TestCommentsRoundTripMarkerLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		section *model.CommentSection
+	}{
+		{
+			name: "body quoting a ghi file",
+			body: "Example:\n<!-- ghi:comments: only comments marked 'editable' can be changed -->\n<!-- ghi:new-comment: text below is posted as a new comment on push -->\nnot a comment\n",
+			section: &model.CommentSection{
+				Comments: []model.Comment{{ID: 1, Author: "octocat", CreatedAt: "2024-01-01T00:00:00Z", Body: "plain"}},
+			},
+		},
+		{
+			name: "comment with marker lines",
+			body: "body\n",
+			section: &model.CommentSection{
+				Comments: []model.Comment{
+					{ID: 1, Author: "octocat", CreatedAt: "2024-01-01T00:00:00Z", Body: "before\n<!-- /ghi:comment -->\n<!-- ghi:comment id=9 author=@x created=y -->\nafter"},
+					{ID: 2, Author: "me", CreatedAt: "2024-01-02T00:00:00Z", Mine: true, Body: "\\<!-- /ghi:comment -->\n\\\\<!-- ghi:comments"},
+				},
+				New: "a new comment\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := AppendComments([]byte(tt.body), tt.section)
+			body, section, err := SplitComments(raw)
+			if err != nil {
+				t.Fatalf("SplitComments: %v\n%s", err, raw)
+			}
+			if string(body) != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+			if !reflect.DeepEqual(section, tt.section) {
+				t.Errorf("section = %+v, want %+v", section, tt.section)
+			}
+		})
+	}
+}
+
+func TestAppendCommentsWithoutSectionKeepsBody(t *testing.T) {
+	body := "<!-- ghi:comments -->\n"
+	if got := string(AppendComments([]byte(body), nil)); got != body {
+		t.Errorf("AppendComments(nil section) = %q", got)
+	}
+	if strings.Contains(string(AppendComments([]byte(body), &model.CommentSection{})), "\n"+body) {
+		t.Error("marker line in the body was not escaped")
+	}
+}
\ No newline at end of file
@@ -0,0 +1,97 @@
+package filefmt
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/nomnel/ghi/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// discussionKeys are the keys ghi manages in discussion files, in the order
+// it writes them.
+var discussionKeys = []string{"title", "category", "answered"}
+
+func discussionFields(fm model.DiscussionFrontmatter) fields {
+	return fields{keys: discussionKeys, value: func(key string) any {
+		switch key {
+		case "title":
+			return nonEmpty(fm.Title)
+		case "category":
+			return nonEmpty(fm.Category)
+		case "answered":
+			if fm.Answered {
+				return true
+			}
+		}
+		return nil
+	}}
+}
+
+// The thread of a discussion follows its body. Like the comments section of
+// an issue file it is marked with HTML comments, but it is read-only: pull
+// rewrites it and push ignores it.
+const (
+	threadHeader = "<!-- ghi:thread: read-only, pull replaces it and push ignores it -->"
+	threadPrefix = "<!-- ghi:thread"
+	replyEnd     = "<!-- /ghi:reply -->"
+)
+
+// UpdateDiscussionMarkdown encodes a discussion file on top of its previous
+// content, with the thread after the body. A nil thread writes none.
+func UpdateDiscussionMarkdown(prev []byte, fm model.DiscussionFrontmatter, body []byte, thread []model.DiscussionComment) ([]byte, error) {
+	return updateMarkdown(prev, discussionFields(fm), discussionKeys, appendThread(body, thread))
+}
+
+// DecodeDiscussionMarkdown splits a discussion file into its frontmatter and
+// body, dropping the thread.
+func DecodeDiscussionMarkdown(raw []byte) (*model.DiscussionFrontmatter, []byte, error) {
+	doc, err := splitMarkdown(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	var fm model.DiscussionFrontmatter
+	if err := yaml.Unmarshal(doc.header, &fm); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse frontmatter YAML: %w", err)
+	}
+	
+	body := doc.body
+	if bytes.HasPrefix(body, []byte(threadPrefix)) {
+		body = body[:0]
+	} else if i := bytes.Index(body, []byte("\n"+threadPrefix)); i >= 0 {
+		body = body[:i]
+	}
+	
+	return &fm, body, nil
+}
+
+// appendThread writes the comments of a discussion after its body, each
+// reply right after the comment it answers.
+func appendThread(body []byte, thread []model.DiscussionComment) []byte {
+	if thread == nil {
+		return body
+	}
+	
+	var buf bytes.Buffer
+	buf.Write(body)
+	buf.WriteString("\n" + threadHeader + "\n")
+	
+	for _, c := range thread {
+		answer := ""
+		if c.IsAnswer {
+			answer = " answer"
+		}
+		fmt.Fprintf(&buf, "\n<!-- ghi:comment id=%d author=@%s created=%s%s -->\n", c.ID, c.Author, c.CreatedAt, answer)
+		buf.WriteString(c.Body)
+		buf.WriteString("\n" + commentEnd + "\n")
+		
+		for _, r := range c.Replies {
+			fmt.Fprintf(&buf, "\n<!-- ghi:reply to=%d id=%d author=@%s created=%s -->\n", c.ID, r.ID, r.Author, r.CreatedAt)
+			buf.WriteString(r.Body)
+			buf.WriteString("\n" + replyEnd + "\n")
+		}
+	}
+	
+	return buf.Bytes()
+}
\ No newline at end of file
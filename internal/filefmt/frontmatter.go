@@ -0,0 +1,225 @@
+package filefmt
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// frontmatterKeys are the keys ghi manages in issue files, in the order it
+// writes them.
+var frontmatterKeys = []string{"title", "labels", "assignees", "milestone", "state"}
+
+// fields is the frontmatter layout of one kind of file: the keys ghi
+// manages, in the order it writes them, and the value of each key, nil when
+// the key is not written.
+type fields struct {
+	keys  []string
+	value func(key string) any
+}
+
+func issueFields(fm model.Frontmatter) fields {
+	return fields{keys: frontmatterKeys, value: func(key string) any { return frontmatterValue(fm, key) }}
+}
+
+// UpdateMarkdown encodes an issue file like EncodeMarkdown, but on top of
+// the previous content of the file: only the owned keys are rewritten, and
+// other keys, YAML comments and key order stay as the user wrote them. A
+// header whose owned values did not change is kept byte for byte. Without a
+// usable previous file the result is the same as EncodeMarkdown.
+func UpdateMarkdown(prev []byte, fm model.Frontmatter, owned []string, body []byte) ([]byte, error) {
+	return updateMarkdown(prev, issueFields(fm), owned, body)
+}
+
+func updateMarkdown(prev []byte, f fields, owned []string, body []byte) ([]byte, error) {
+	doc, err := splitMarkdown(prev)
+	if prev == nil || err != nil {
+		return encodeMarkdown(f, body)
+	}
+	
+	var root yaml.Node
+	if err := yaml.Unmarshal(doc.header, &root); err != nil {
+		return encodeMarkdown(f, body)
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if len(root.Content) != 1 || root.Content[0].Kind != yaml.MappingNode {
+		return encodeMarkdown(f, body)
+	}
+	
+	changed, err := updateMapping(root.Content[0], f, owned)
+	if err != nil {
+		return nil, err
+	}
+	
+	var buf bytes.Buffer
+	if !changed {
+		buf.Write(doc.head)
+		buf.Write(body)
+		return buf.Bytes(), nil
+	}
+	
+	// The new header keeps the file's byte order mark and line endings.
+	var header bytes.Buffer
+	header.WriteString(frontmatterDelimiter + "\n")
+	encoder := yaml.NewEncoder(&header)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	encoder.Close()
+	header.WriteString(frontmatterDelimiter + "\n")
+	
+	if doc.bom {
+		buf.WriteString(byteOrderMark)
+	}
+	if doc.crlf {
+		buf.Write(bytes.ReplaceAll(header.Bytes(), []byte("\n"), []byte("\r\n")))
+	} else {
+		buf.Write(header.Bytes())
+	}
+	buf.Write(body)
+	
+	return buf.Bytes(), nil
+}
+
+// updateMapping sets the owned keys of a frontmatter mapping to the values
+// in f, removing keys whose value is empty, and reports whether anything
+// changed. Values that are already equal keep their node, so their style and
+// comments survive.
+func updateMapping(m *yaml.Node, f fields, owned []string) (bool, error) {
+	changed := false
+	for _, key := range f.keys {
+		if !slices.Contains(owned, key) {
+			continue
+		}
+		want := f.value(key)
+		i := keyIndex(m, key)
+		
+		switch {
+		case i < 0 && want == nil:
+			continue
+		case want == nil:
+			m.Content = slices.Delete(m.Content, i, i+2)
+		case i >= 0 && sameValue(m.Content[i+1], want):
+			continue
+		default:
+			value := valueNode(want)
+			if i >= 0 {
+				old := m.Content[i+1]
+				value.Style |= old.Style & yaml.FlowStyle
+				value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+				m.Content[i+1] = value
+			} else {
+				at := insertIndex(m, f.keys, key)
+				m.Content = slices.Insert(m.Content, at, stringNode(key), value)
+			}
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// frontmatterValue returns the value of an owned key, or nil when it is not
+// written: an empty string or a nil list. An empty non-nil list is written as
+// [] and a cleared milestone as "", which unlike a missing key mean "none".
+func frontmatterValue(fm model.Frontmatter, key string) any {
+	var s string
+	var list []string
+	switch key {
+	case "title":
+		s = fm.Title
+	case "milestone":
+		s = fm.Milestone
+	case "state":
+		s = fm.State
+	case "labels":
+		list = fm.Labels
+	case "assignees":
+		list = fm.Assignees
+	}
+	
+	switch {
+	case s != "":
+		return s
+	case key == "milestone" && fm.NoMilestone:
+		return ""
+	case list != nil:
+		return list
+	}
+	return nil
+}
+
+// valueNode builds the node for a frontmatter value. The nodes are built by
+// hand because yaml.v3 turns values such as "\n" into block scalars that
+// decode as "".
+func valueNode(value any) *yaml.Node {
+	switch value := value.(type) {
+	case []string:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range value {
+			node.Content = append(node.Content, stringNode(item))
+		}
+		return node
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value)}
+	}
+	return stringNode(value.(string))
+}
+
+// stringNode is a string scalar; ones with line breaks are double-quoted.
+func stringNode(s string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+	if strings.ContainsAny(s, "\r\n\u0085\u2028\u2029") {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	return node
+}
+
+func sameValue(node *yaml.Node, want any) bool {
+	switch want := want.(type) {
+	case string:
+		var s string
+		return node.Kind == yaml.ScalarNode && node.Decode(&s) == nil && s == want
+	case []string:
+		var list []string
+		return node.Kind == yaml.SequenceNode && node.Decode(&list) == nil && slices.Equal(list, want)
+	case bool:
+		var b bool
+		return node.Kind == yaml.ScalarNode && node.Decode(&b) == nil && b == want
+	}
+	return false
+}
+
+// keyIndex returns the index of key's key node in a mapping, or -1.
+func keyIndex(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertIndex places a new owned key after the owned keys that precede it in
+// ghi's order, or before the first owned key that follows it.
+func insertIndex(m *yaml.Node, keys []string, key string) int {
+	pos := slices.Index(keys, key)
+	for k := pos - 1; k >= 0; k-- {
+		if i := keyIndex(m, keys[k]); i >= 0 {
+			return i + 2
+		}
+	}
+	for _, next := range keys[pos+1:] {
+		if i := keyIndex(m, next); i >= 0 {
+			return i
+		}
+	}
+	return len(m.Content)
+}
\ No newline at end of file
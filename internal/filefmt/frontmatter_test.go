@@ -0,0 +1,113 @@
+package filefmt
+
+import (
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+var owned = []string{"title", "labels", "assignees", "milestone", "state"}
+
+func TestUpdateMarkdownKeepsUnknownKeys(t *testing.T) {
+	prev := "---\n# my notes\npriority: high  # triage first\ntitle: Fix login\nlabels: [bug, ui]\nnotes:\n  - ask @octocat\nstate: open\n---\nold body\n"
+	fm := model.Frontmatter{Title: "Fix login", Labels: []string{"bug", "ui"}, State: "open"}
+	
+	got, err := UpdateMarkdown([]byte(prev), fm, owned, []byte("new body\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := prev[:len(prev)-len("old body\n")] + "new body\n"; string(got) != want {
+		t.Errorf("unchanged header was rewritten:\n%s", got)
+	}
+	
+	fm.Title = "Fix login redirect"
+	fm.Labels = []string{"bug"}
+	fm.Milestone = "v1.0"
+	fm.State = ""
+	got, err = UpdateMarkdown([]byte(prev), fm, owned, []byte("body\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\n# my notes\npriority: high # triage first\ntitle: Fix login redirect\nlabels: [bug]\nmilestone: v1.0\nnotes:\n  - ask @octocat\n---\nbody\n"
+	if string(got) != want {
+		t.Errorf("UpdateMarkdown =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUpdateMarkdownLeavesUnownedKeys(t *testing.T) {
+	prev := "---\ntitle: Old\nstate: whatever\n---\n"
+	got, err := UpdateMarkdown([]byte(prev), model.Frontmatter{Title: "New"}, []string{"title"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "---\ntitle: New\nstate: whatever\n---\n"; string(got) != want {
+		t.Errorf("UpdateMarkdown =\n%s\nwant\n%s", got, want)
+	}
+	
+	// Files that are not issue files yet are encoded from scratch.
+	for _, prev := range []string{"", "no frontmatter\n", "---\n- a list\n---\n"} {
+		got, err := UpdateMarkdown([]byte(prev), model.Frontmatter{Title: "New"}, owned, []byte("b\n"))
+		if err != nil || string(got) != "---\ntitle: New\n---\nb\n" {
+			t.Errorf("UpdateMarkdown(%q) = %q, %v", prev, got, err)
+		}
+	}
+}
+
+func TestPRMarkdownRoundTrip(t *testing.T) {
+	fm := model.PRFrontmatter{Title: "Add dark mode", Base: "main", Head: "dark-mode", Draft: true, Reviewers: []string{"octo/design"}}
+	got, err := UpdatePRMarkdown(nil, fm, []byte("body\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\ntitle: Add dark mode\nbase: main\nhead: dark-mode\ndraft: true\nreviewers:\n  - octo/design\n---\nbody\n"
+	if string(got) != want {
+		t.Errorf("UpdatePRMarkdown =\n%s\nwant\n%s", got, want)
+	}
+	
+	decoded, body, err := DecodePRMarkdown(got)
+	if err != nil || string(body) != "body\n" || decoded.Title != fm.Title || !decoded.Draft || decoded.Reviewers[0] != "octo/design" {
+		t.Errorf("DecodePRMarkdown = %+v, %q, %v", decoded, body, err)
+	}
+	
+	// Ready for review drops the key; other keys are kept.
+	fm.Draft = false
+	got, err = UpdatePRMarkdown([]byte("---\nticket: X-1\ntitle: Add dark mode\ndraft: true\n---\nbody\n"), fm, []byte("body\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "---\nticket: X-1\ntitle: Add dark mode\nbase: main\nhead: dark-mode\nreviewers:\n  - octo/design\n---\nbody\n"
+	if string(got) != want {
+		t.Errorf("UpdatePRMarkdown =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDiscussionMarkdownThread(t *testing.T) {
+	fm := model.DiscussionFrontmatter{Title: "Plugin API", Category: "Ideas", Answered: true}
+	thread := []model.DiscussionComment{
+		{ID: 1, Author: "alice", CreatedAt: "2024-05-01T10:00:00Z", Body: "Hooks?", IsAnswer: true, Replies: []model.DiscussionComment{
+			{ID: 2, Author: "bob", CreatedAt: "2024-05-01T11:00:00Z", Body: "+1"},
+		}},
+	}
+	got, err := UpdateDiscussionMarkdown(nil, fm, []byte("body\n"), thread)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\ntitle: Plugin API\ncategory: Ideas\nanswered: true\n---\nbody\n\n" + threadHeader + "\n" +
+		"\n<!-- ghi:comment id=1 author=@alice created=2024-05-01T10:00:00Z answer -->\nHooks?\n<!-- /ghi:comment -->\n" +
+		"\n<!-- ghi:reply to=1 id=2 author=@bob created=2024-05-01T11:00:00Z -->\n+1\n<!-- /ghi:reply -->\n"
+	if string(got) != want {
+		t.Errorf("UpdateDiscussionMarkdown =\n%s\nwant\n%s", got, want)
+	}
+	
+	decoded, body, err := DecodeDiscussionMarkdown(got)
+	if err != nil || string(body) != "body\n" || *decoded != fm {
+		t.Errorf("DecodeDiscussionMarkdown = %+v, %q, %v", decoded, body, err)
+	}
+	
+	// Without comments the file still has a thread header, so later pulls
+	// put new comments in the same place.
+	got, _ = UpdateDiscussionMarkdown(nil, fm, []byte("body\n"), []model.DiscussionComment{})
+	if _, body, _ := DecodeDiscussionMarkdown(got); string(body) != "body\n" {
+		t.Errorf("body of a file with an empty thread = %q", body)
+	}
+}
\ No newline at end of file
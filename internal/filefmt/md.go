@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/nomnel/ghi/internal/model"
 	"gopkg.in/yaml.v3"
@@ -13,14 +12,28 @@ import (
 
 const frontmatterDelimiter = "---"
 
+// EncodeMarkdown writes the frontmatter between "---" fences, then the body
+// exactly as given: the closing fence is followed by a single newline and
+// nothing is added after the body.
 func EncodeMarkdown(fm model.Frontmatter, body []byte) ([]byte, error) {
+	return encodeMarkdown(issueFields(fm), body)
+}
+
+func encodeMarkdown(f fields, body []byte) ([]byte, error) {
 	var buf bytes.Buffer
 	
 	buf.WriteString(frontmatterDelimiter + "\n")
 	
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range f.keys {
+		if value := f.value(key); value != nil {
+			node.Content = append(node.Content, stringNode(key), valueNode(value))
+		}
+	}
+	
 	encoder := yaml.NewEncoder(&buf)
 	encoder.SetIndent(2)
-	if err := encoder.Encode(fm); err != nil {
+	if err := encoder.Encode(node); err != nil {
 		return nil, fmt.Errorf("failed to encode frontmatter: %w", err)
 	}
 	encoder.Close()
@@ -32,41 +45,97 @@ func EncodeMarkdown(fm model.Frontmatter, body []byte) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// DecodeMarkdown splits an issue file into its frontmatter and body. The body
+// is returned byte for byte as it follows the closing fence line, so
+// DecodeMarkdown(EncodeMarkdown(fm, body)) yields body for any body. Files
+// with a UTF-8 byte order mark or CRLF line endings are accepted.
 func DecodeMarkdown(raw []byte) (*model.Frontmatter, []byte, error) {
-	content := string(raw)
-	lines := strings.Split(content, "\n")
+	doc, err := splitMarkdown(raw)
+	if err != nil {
+		return nil, nil, err
+	}
 	
-	if len(lines) == 0 || strings.TrimSpace(lines[0]) != frontmatterDelimiter {
-		return nil, nil, fmt.Errorf("%w: file must start with '---'", model.ErrMalformedFrontmatter)
+	var fm model.Frontmatter
+	if err := yaml.Unmarshal(doc.header, &fm); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse frontmatter YAML: %w", err)
 	}
 	
-	closingIdx := -1
-	for i := 1; i < len(lines); i++ {
-		if strings.TrimSpace(lines[i]) == frontmatterDelimiter {
-			closingIdx = i
-			break
+	// "milestone:" with no value clears the milestone, unlike a missing key.
+	if fm.Milestone == "" {
+		var keys map[string]any
+		if yaml.Unmarshal(doc.header, &keys) == nil {
+			if v, ok := keys["milestone"]; ok && (v == nil || v == "") {
+				fm.NoMilestone = true
+			}
 		}
 	}
 	
-	if closingIdx == -1 {
-		return nil, nil, fmt.Errorf("%w: missing closing '---'", model.ErrMalformedFrontmatter)
+	return &fm, doc.body, nil
+}
+
+// markdownParts is an issue file cut at its fences. head is everything up to
+// and including the closing fence line, so head followed by body is the file.
+type markdownParts struct {
+	head   []byte
+	header []byte
+	body   []byte
+	bom    bool
+	crlf   bool
+}
+
+const byteOrderMark = "\ufeff"
+
+// splitMarkdown finds the frontmatter fences. A fence is a line that is
+// exactly "---", apart from trailing spaces and a CR; indented lines inside
+// YAML block scalars are not fences.
+func splitMarkdown(raw []byte) (markdownParts, error) {
+	var doc markdownParts
+	
+	start := 0
+	if bytes.HasPrefix(raw, []byte(byteOrderMark)) {
+		doc.bom = true
+		start = len(byteOrderMark)
 	}
 	
-	frontmatterContent := strings.Join(lines[1:closingIdx], "\n")
-	
-	var fm model.Frontmatter
-	if err := yaml.Unmarshal([]byte(frontmatterContent), &fm); err != nil {
-		return nil, nil, fmt.Errorf("failed to parse frontmatter YAML: %w", err)
+	line, next := nextLine(raw, start)
+	if !isFence(line) || next < 0 {
+		if isFence(line) {
+			return doc, fmt.Errorf("%w: missing closing '---'", model.ErrMalformedFrontmatter)
+		}
+		return doc, fmt.Errorf("%w: file must start with '---'", model.ErrMalformedFrontmatter)
 	}
-	
-	bodyStartIdx := closingIdx + 1
-	var bodyLines []string
-	if bodyStartIdx < len(lines) {
-		bodyLines = lines[bodyStartIdx:]
+	doc.crlf = bytes.HasSuffix(line, []byte("\r"))
+	
+	headerStart := next
+	for pos := next; pos >= 0; {
+		line, next = nextLine(raw, pos)
+		if isFence(line) {
+			end := len(raw)
+			if next >= 0 {
+				end = next
+			}
+			doc.header = raw[headerStart:pos]
+			doc.head = raw[:end]
+			doc.body = raw[end:]
+			return doc, nil
+		}
+		pos = next
 	}
-	body := []byte(strings.Join(bodyLines, "\n"))
 	
-	return &fm, body, nil
+	return doc, fmt.Errorf("%w: missing closing '---'", model.ErrMalformedFrontmatter)
+}
+
+// nextLine returns the line starting at pos without its "\n" and the offset
+// of the following line, or -1 if the line is the last one.
+func nextLine(raw []byte, pos int) ([]byte, int) {
+	if i := bytes.IndexByte(raw[pos:], '\n'); i >= 0 {
+		return raw[pos : pos+i], pos + i + 1
+	}
+	return raw[pos:], -1
+}
+
+func isFence(line []byte) bool {
+	return string(bytes.TrimRight(line, " \t\r")) == frontmatterDelimiter
 }
 
 func AtomicWriteFile(path string, data []byte, perm os.FileMode) error {
@@ -0,0 +1,149 @@
+package filefmt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func TestDecodeMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		body string
+	}{
+		{"lf", "---\ntitle: T\n---\nbody\n", "body\n"},
+		{"crlf", "---\r\ntitle: T\r\n---\r\nline one\r\nline two\r\n", "line one\r\nline two\r\n"},
+		{"bom", "\ufeff---\ntitle: T\n---\nbody", "body"},
+		{"empty body", "---\ntitle: T\n---\n", ""},
+		{"no newline after fence", "---\ntitle: T\n---", ""},
+		{"body starts with fence", "---\ntitle: T\n---\n---\nnot frontmatter\n", "---\nnot frontmatter\n"},
+		{"trailing spaces on fences", "--- \ntitle: T\n---  \nbody\n", "body\n"},
+		{"fence inside block scalar", "---\ntitle: |-\n  T\n  ---\n---\nbody\n", "body\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := DecodeMarkdown([]byte(tt.raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+			if fm.Title != "T" && fm.Title != "T\n---" {
+				t.Errorf("title = %q", fm.Title)
+			}
+		})
+	}
+	
+	for _, raw := range []string{"", "title: T\n", "---\ntitle: T\n", " ---\n---\n", "---"} {
+		if _, _, err := DecodeMarkdown([]byte(raw)); err == nil {
+			t.Errorf("DecodeMarkdown(%q) succeeded", raw)
+		}
+	}
+}
+
+func TestDecodeMarkdownEmptyValues(t *testing.T) {
+	tests := []struct {
+		header      string
+		labels      []string
+		noMilestone bool
+	}{
+		{"title: T\n", nil, false},
+		{"labels:\nmilestone:\n", nil, true},
+		{"labels: []\nmilestone: \"\"\n", []string{}, true},
+		{"labels: [bug]\nmilestone: v1.0\n", []string{"bug"}, false},
+	}
+	for _, tt := range tests {
+		fm, _, err := DecodeMarkdown([]byte("---\n" + tt.header + "---\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fm.Labels, tt.labels) || fm.NoMilestone != tt.noMilestone {
+			t.Errorf("DecodeMarkdown(%q) = %+v", tt.header, fm)
+		}
+	}
+}
+
+func TestUpdateMarkdownKeepsLineEndings(t *testing.T) {
+	prev := "\ufeff---\r\ntitle: Old\r\npriority: high\r\n---\r\nbody\r\n"
+	got, err := UpdateMarkdown([]byte(prev), model.Frontmatter{Title: "New"}, owned, []byte("body\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "\ufeff---\r\ntitle: New\r\npriority: high\r\n---\r\nbody\r\n"; string(got) != want {
+		t.Errorf("UpdateMarkdown = %q, want %q", got, want)
+	}
+}
+
+// FuzzEncodeDecode checks that pull followed by push is the identity: what
+// EncodeMarkdown writes, DecodeMarkdown reads back unchanged.
+func FuzzEncodeDecode(f *testing.F) {
+	f.Add("Fix login", "bug", []byte("line one\nline two\n"))
+	f.Add("", "", []byte(""))
+	f.Add("---", "---", []byte("---\n---\n"))
+	f.Add("a\n---\nb", "x: y", []byte("\r\n\r\n"))
+	f.Add("\ufeff", " ", []byte("\ufeff---\r\nbody"))
+	f.Add("title", "label", []byte{0xff, 0xfe, '\n', 0})
+	f.Add("\n", "\u2028", []byte(""))
+	
+	f.Fuzz(func(t *testing.T, title string, label string, body []byte) {
+		if !utf8.ValidString(title) || !utf8.ValidString(label) {
+			t.Skip("GitHub titles and labels are UTF-8")
+		}
+		fm := model.Frontmatter{Title: title, Milestone: label, State: "open"}
+		if label != "" {
+			fm.Labels = []string{label}
+		}
+		
+		raw, err := EncodeMarkdown(fm, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, gotBody, err := DecodeMarkdown(raw)
+		if err != nil {
+			t.Fatalf("DecodeMarkdown(%q): %v", raw, err)
+		}
+		if !bytes.Equal(gotBody, body) {
+			t.Errorf("body = %q, want %q", gotBody, body)
+		}
+		if !reflect.DeepEqual(*got, fm) {
+			t.Errorf("frontmatter = %#v, want %#v", *got, fm)
+		}
+	})
+}
+
+// FuzzDecodeUpdate checks that rewriting any readable file keeps its body and
+// frontmatter, whatever its line endings or extra keys.
+func FuzzDecodeUpdate(f *testing.F) {
+	f.Add([]byte("---\ntitle: T\nlabels: [a, b]\n---\nbody\n"))
+	f.Add([]byte("\ufeff---\r\ntitle: T\r\nnotes: x # c\r\n---\r\nbody\r\n"))
+	f.Add([]byte("---\n---\n---\n"))
+	f.Add([]byte("---\nstate: ~\nmilestone: 1\n---"))
+	f.Add([]byte("---\nlabels: []\n---\n"))
+	
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		fm, body, err := DecodeMarkdown(raw)
+		if err != nil {
+			return
+		}
+		
+		out, err := UpdateMarkdown(raw, *fm, owned, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, gotBody, err := DecodeMarkdown(out)
+		if err != nil {
+			t.Fatalf("DecodeMarkdown(%q): %v", out, err)
+		}
+		if !bytes.Equal(gotBody, body) {
+			t.Errorf("body = %q, want %q", gotBody, body)
+		}
+		if !reflect.DeepEqual(got, fm) {
+			t.Errorf("frontmatter = %#v, want %#v", *got, *fm)
+		}
+	})
+}
\ No newline at end of file
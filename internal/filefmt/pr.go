@@ -0,0 +1,67 @@
+package filefmt
+
+import (
+	"fmt"
+
+	"github.com/nomnel/ghi/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// prKeys are the keys ghi manages in pull request files, in the order it
+// writes them.
+var prKeys = []string{"title", "base", "head", "draft", "labels", "reviewers"}
+
+func prFields(fm model.PRFrontmatter) fields {
+	return fields{keys: prKeys, value: func(key string) any {
+		switch key {
+		case "title":
+			return nonEmpty(fm.Title)
+		case "base":
+			return nonEmpty(fm.Base)
+		case "head":
+			return nonEmpty(fm.Head)
+		case "draft":
+			if fm.Draft {
+				return true
+			}
+		case "labels":
+			if fm.Labels != nil {
+				return fm.Labels
+			}
+		case "reviewers":
+			if fm.Reviewers != nil {
+				return fm.Reviewers
+			}
+		}
+		return nil
+	}}
+}
+
+func nonEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// UpdatePRMarkdown encodes a pull request file on top of its previous
+// content, keeping keys ghi does not manage, like UpdateMarkdown does for
+// issue files.
+func UpdatePRMarkdown(prev []byte, fm model.PRFrontmatter, body []byte) ([]byte, error) {
+	return updateMarkdown(prev, prFields(fm), prKeys, body)
+}
+
+// DecodePRMarkdown splits a pull request file into its frontmatter and body.
+func DecodePRMarkdown(raw []byte) (*model.PRFrontmatter, []byte, error) {
+	doc, err := splitMarkdown(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	var fm model.PRFrontmatter
+	if err := yaml.Unmarshal(doc.header, &fm); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse frontmatter YAML: %w", err)
+	}
+	
+	return &fm, doc.body, nil
+}
\ No newline at end of file
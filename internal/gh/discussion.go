@@ -0,0 +1,24 @@
+package gh
+
+import (
+	"github.com/nomnel/ghi/internal/backend"
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// gh has no discussion commands, so discussions go through GraphQL.
+
+func (c *CLI) ViewDiscussion(number string) (*model.DiscussionData, error) {
+	return backend.ViewDiscussion(c, number)
+}
+
+func (c *CLI) ListDiscussions(category string, limit int) ([]model.DiscussionListItem, error) {
+	return backend.ListDiscussions(c, category, limit)
+}
+
+func (c *CLI) EditDiscussion(number string, edit model.DiscussionEdit) error {
+	return backend.EditDiscussion(c, number, edit)
+}
+
+func (c *CLI) CreateDiscussion(draft model.DiscussionDraft) (int, error) {
+	return backend.CreateDiscussion(c, draft)
+}
\ No newline at end of file
@@ -5,15 +5,43 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/nomnel/ghi/internal/backend"
 	"github.com/nomnel/ghi/internal/model"
 )
 
-const commandTimeout = 30 * time.Second
+const issueViewFields = "number,title,body,state,labels,assignees,milestone,updatedAt"
+
+// CLI implements backend.Backend by running the authenticated gh CLI.
+type CLI struct {
+	Timeout time.Duration
+	// Repo is the "owner/name" repository to operate on; empty means the
+	// repository gh resolves for the current directory.
+	Repo string
+	// Retry retries gh commands that failed on a rate limit, a 5xx response
+	// or a network error.
+	Retry *backend.Retrier
+	
+	calls atomic.Int64
+}
+
+var (
+	_ backend.Backend       = (*CLI)(nil)
+	_ backend.QuotaReporter = (*CLI)(nil)
+)
+
+func New() *CLI {
+	return &CLI{Timeout: backend.DefaultTimeout, Retry: backend.NewRetrier()}
+}
 
 func checkGHAvailable() error {
 	_, err := exec.LookPath("gh")
@@ -31,68 +59,228 @@ func checkGitAvailable() error {
 	return nil
 }
 
-func ViewIssue(issueNumber string) (*model.IssueData, error) {
+// run executes gh with args and returns what it wrote to stdout, retrying
+// transient failures. stdout is returned even on failure because some
+// commands (gh api) still print the response body there.
+func (c *CLI) run(args ...string) ([]byte, error) {
+	c.calls.Add(1)
+	var out []byte
+	err := c.Retry.Do(idempotent(args), func() error {
+		var err error
+		out, err = c.runOnce(args...)
+		if e, ok := err.(*backend.Error); ok && e.Kind == backend.KindRateLimited && e.RetryAfter == 0 {
+			e.RetryAfter = c.rateLimitReset()
+		}
+		return err
+	})
+	return out, err
+}
+
+// idempotent reports whether a gh command can safely run again after it
+// failed in a way that may have left it half done. Creating an issue or
+// adding a comment twice would duplicate it, and so would a GraphQL mutation
+// like createDiscussion; editing applies the same change again.
+func idempotent(args []string) bool {
+	if len(args) >= 2 && args[0] == "api" && args[1] == "graphql" {
+		return !slices.ContainsFunc(args, func(arg string) bool { return strings.HasPrefix(arg, "query=mutation") })
+	}
+	return len(args) < 2 || args[0] != "issue" || (args[1] != "create" && args[1] != "comment")
+}
+
+func (c *CLI) runOnce(args ...string) ([]byte, error) {
 	if err := checkGHAvailable(); err != nil {
 		return nil, err
 	}
 	
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
 	defer cancel()
 	
-	cmd := exec.CommandContext(ctx, "gh", "issue", "view", issueNumber, "--json", "title,body")
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	if c.Repo != "" {
+		// GH_REPO applies to every gh command, including the {owner} and
+		// {repo} placeholders of gh api.
+		cmd.Env = append(os.Environ(), "GH_REPO="+c.Repo)
+	}
 	
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	
 	if err := cmd.Run(); err != nil {
-		stderrStr := strings.TrimSpace(stderr.String())
-		if strings.Contains(stderrStr, "authentication") || strings.Contains(stderrStr, "auth") {
-			return nil, fmt.Errorf("gh error: verify authentication ('gh auth status') and run inside a Git repo")
+		if ctx.Err() == context.DeadlineExceeded {
+			return stdout.Bytes(), &backend.Error{Kind: backend.KindUnavailable, Message: fmt.Sprintf("gh error: timed out after %s", c.Timeout)}
+		}
+		return stdout.Bytes(), classify(strings.TrimSpace(stderr.String()))
+	}
+	
+	return stdout.Bytes(), nil
+}
+
+// rateLimit reads the current quotas with `gh api rate_limit`, which does not
+// count against them.
+func (c *CLI) rateLimit() ([]backend.Quota, error) {
+	out, err := c.runOnce("api", "rate_limit")
+	if err != nil {
+		return nil, err
+	}
+	
+	var response struct {
+		Resources map[string]struct {
+			Limit     int   `json:"limit"`
+			Remaining int   `json:"remaining"`
+			Reset     int64 `json:"reset"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(out, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+	
+	var quotas []backend.Quota
+	for _, resource := range []string{"core", "graphql", "search"} {
+		if r, ok := response.Resources[resource]; ok {
+			quotas = append(quotas, backend.Quota{Resource: resource, Limit: r.Limit, Remaining: r.Remaining, Reset: time.Unix(r.Reset, 0)})
+		}
+	}
+	return quotas, nil
+}
+
+// rateLimitReset returns how long until the exhausted quotas reset. gh does
+// not pass on the rate limit headers, so they are asked for separately; zero
+// means a secondary limit, which has no reset time.
+func (c *CLI) rateLimitReset() time.Duration {
+	quotas, err := c.rateLimit()
+	if err != nil {
+		return 0
+	}
+	var wait time.Duration
+	for _, q := range quotas {
+		if q.Remaining == 0 {
+			wait = max(wait, time.Until(q.Reset), time.Second)
 		}
-		if strings.Contains(stderrStr, "not found") {
-			return nil, fmt.Errorf("gh error: issue not found or repo not set. Authenticate with 'gh auth login' and run inside a repo")
+	}
+	return wait
+}
+
+// Quotas returns the current quotas once a gh command has run.
+func (c *CLI) Quotas() ([]backend.Quota, error) {
+	if c.calls.Load() == 0 {
+		return nil, nil
+	}
+	return c.rateLimit()
+}
+
+// classify turns gh's stderr into a typed error. gh does not expose HTTP
+// status codes for most commands, so matching its messages is the best we can
+// do; the api backend gets real status codes instead.
+func classify(stderr string) *backend.Error {
+	lower := strings.ToLower(stderr)
+	contains := func(subs ...string) bool {
+		for _, s := range subs {
+			if strings.Contains(lower, s) {
+				return true
+			}
 		}
-		return nil, fmt.Errorf("gh error: %s", stderrStr)
+		return false
+	}
+	
+	switch {
+	case contains("rate limit"):
+		return &backend.Error{Kind: backend.KindRateLimited, Message: "gh error: rate limit exceeded: " + stderr}
+	case contains("unknown flag", "invalid argument", "unrecognized"):
+		return &backend.Error{Kind: backend.KindInvalid, Message: stderr}
+	case contains("http 401", "authentication", "gh auth login", "not logged"):
+		return &backend.Error{Kind: backend.KindAuth, Message: "gh error: ensure you're authenticated ('gh auth login') and running inside a GitHub repo"}
+	case contains("not a git repository", "no git remotes"):
+		return &backend.Error{Kind: backend.KindNotFound, Message: "gh error: ensure you're authenticated ('gh auth login') and running inside a GitHub repo"}
+	case contains("http 404", "not found", "could not resolve"):
+		return &backend.Error{Kind: backend.KindNotFound, Message: "gh error: issue not found or repo not set"}
+	case contains("http 403", "permission", "forbidden"):
+		return &backend.Error{Kind: backend.KindForbidden, Message: "gh error: permission denied"}
+	case contains("http 5"):
+		return &backend.Error{Kind: backend.KindUnavailable, Message: "gh error: " + stderr}
+	case contains("connection reset", "connection refused", "dial tcp", "no such host", "i/o timeout", "tls handshake", "unexpected eof"):
+		return &backend.Error{Kind: backend.KindUnavailable, Message: "gh error: network error: " + stderr}
+	}
+	return &backend.Error{Message: "gh error: " + stderr}
+}
+
+func (c *CLI) Repository() (string, error) {
+	if c.Repo != "" {
+		return c.Repo, nil
+	}
+	owner, repo, err := GetRepositoryInfo()
+	if err != nil {
+		return "", err
+	}
+	return owner + "/" + repo, nil
+}
+
+func (c *CLI) ViewIssue(issueNumber string) (*model.IssueData, error) {
+	out, err := c.run("issue", "view", issueNumber, "--json", issueViewFields)
+	if err != nil {
+		return nil, err
 	}
 	
 	var issue model.IssueData
-	if err := json.Unmarshal(stdout.Bytes(), &issue); err != nil {
+	if err := json.Unmarshal(out, &issue); err != nil {
 		return nil, fmt.Errorf("failed to parse gh output: %w", err)
 	}
 	
 	return &issue, nil
 }
 
-func EditIssue(issueNumber string, title string, bodyFile string) error {
-	if err := checkGHAvailable(); err != nil {
+func (c *CLI) ViewIssues(issueNumbers []string) (map[string]*model.IssueData, error) {
+	return backend.ViewIssues(c, issueNumbers)
+}
+
+func (c *CLI) ListAllIssues(state string, since time.Time) ([]*model.IssueData, error) {
+	return backend.ListAllIssues(c, state, since)
+}
+
+func (c *CLI) EditIssue(issueNumber string, edit model.IssueEdit) error {
+	bodyFile, err := CreateTempBodyFile([]byte(edit.Body))
+	if err != nil {
 		return err
 	}
-	
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
-	defer cancel()
+	defer os.Remove(bodyFile)
 	
 	args := []string{"issue", "edit", issueNumber}
 	
-	if title != "" && strings.TrimSpace(title) != "" {
-		args = append(args, "--title", title)
+	if edit.Title != "" && strings.TrimSpace(edit.Title) != "" {
+		args = append(args, "--title", edit.Title)
 	}
 	
 	args = append(args, "--body-file", bodyFile)
 	
-	cmd := exec.CommandContext(ctx, "gh", args...)
-	
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+	for _, label := range edit.AddLabels {
+		args = append(args, "--add-label", label)
+	}
+	for _, label := range edit.RemoveLabels {
+		args = append(args, "--remove-label", label)
+	}
+	for _, assignee := range edit.AddAssignees {
+		args = append(args, "--add-assignee", assignee)
+	}
+	for _, assignee := range edit.RemoveAssignees {
+		args = append(args, "--remove-assignee", assignee)
+	}
+	if edit.Milestone != "" {
+		args = append(args, "--milestone", edit.Milestone)
+	}
+	if edit.RemoveMilestone {
+		args = append(args, "--remove-milestone")
+	}
 	
-	if err := cmd.Run(); err != nil {
-		stderrStr := strings.TrimSpace(stderr.String())
-		if strings.Contains(stderrStr, "authentication") || strings.Contains(stderrStr, "auth") {
-			return fmt.Errorf("gh error: verify authentication ('gh auth status') and run inside a Git repo")
-		}
-		return fmt.Errorf("gh error: %s", stderrStr)
+	if _, err := c.run(args...); err != nil {
+		return err
 	}
 	
+	switch edit.State {
+	case "closed":
+		return c.CloseIssue(issueNumber)
+	case "open":
+		return c.ReopenIssue(issueNumber)
+	}
 	return nil
 }
 
@@ -116,12 +304,12 @@ func CreateTempBodyFile(body []byte) (string, error) {
 	return tmp.Name(), nil
 }
 
-func RunGitDiff(localPath, remotePath string, extraArgs []string) (int, error) {
+func RunGitDiff(stdout, stderr io.Writer, localPath, remotePath string, extraArgs []string) (int, error) {
 	if err := checkGitAvailable(); err != nil {
 		return 2, err
 	}
 	
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), backend.DefaultTimeout)
 	defer cancel()
 	
 	args := []string{"--no-pager", "diff", "--no-index", "--exit-code"}
@@ -129,8 +317,8 @@ func RunGitDiff(localPath, remotePath string, extraArgs []string) (int, error) {
 	args = append(args, "--", localPath, remotePath)
 	
 	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 	
 	err := cmd.Run()
 	if err != nil {
@@ -143,32 +331,15 @@ func RunGitDiff(localPath, remotePath string, extraArgs []string) (int, error) {
 	return 0, nil
 }
 
+// GetRepositoryInfo returns the repository gh resolves for the current
+// directory.
 func GetRepositoryInfo() (owner string, repo string, err error) {
-	if err := checkGHAvailable(); err != nil {
+	out, err := New().run("repo", "view", "--json", "nameWithOwner", "-q", ".nameWithOwner")
+	if err != nil {
 		return "", "", err
 	}
 	
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
-	defer cancel()
-	
-	cmd := exec.CommandContext(ctx, "gh", "repo", "view", "--json", "nameWithOwner", "-q", ".nameWithOwner")
-	
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	
-	if err := cmd.Run(); err != nil {
-		stderrStr := strings.TrimSpace(stderr.String())
-		if strings.Contains(stderrStr, "authentication") || strings.Contains(stderrStr, "auth") {
-			return "", "", fmt.Errorf("gh CLI error: ensure you're authenticated ('gh auth login') and running inside a GitHub repo")
-		}
-		if strings.Contains(stderrStr, "not a git repository") || strings.Contains(stderrStr, "not found") {
-			return "", "", fmt.Errorf("gh CLI error: ensure you're authenticated ('gh auth login') and running inside a GitHub repo")
-		}
-		return "", "", fmt.Errorf("gh error: %s", stderrStr)
-	}
-	
-	nameWithOwner := strings.TrimSpace(stdout.String())
+	nameWithOwner := strings.TrimSpace(string(out))
 	parts := strings.Split(nameWithOwner, "/")
 	if len(parts) != 2 {
 		return "", "", fmt.Errorf("unexpected repository format: %s", nameWithOwner)
@@ -177,176 +348,157 @@ func GetRepositoryInfo() (owner string, repo string, err error) {
 	return parts[0], parts[1], nil
 }
 
-type CreateIssueResponse struct {
-	Number int `json:"number"`
+// AuthToken returns the token gh is logged in with.
+func AuthToken() (string, error) {
+	out, err := New().run("auth", "token")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
 }
 
-func CreateIssue(title string) (int, error) {
-	if err := checkGHAvailable(); err != nil {
-		return 0, err
-	}
-	
-	owner, repo, err := GetRepositoryInfo()
+var issueURLRegex = regexp.MustCompile(`/issues/([0-9]+)\s*$`)
+
+func (c *CLI) CreateIssue(draft model.IssueDraft) (int, error) {
+	bodyFile, err := CreateTempBodyFile([]byte(draft.Body))
 	if err != nil {
 		return 0, err
 	}
+	defer os.Remove(bodyFile)
 	
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
-	defer cancel()
-	
-	apiPath := fmt.Sprintf("repos/%s/%s/issues", owner, repo)
-	cmd := exec.CommandContext(ctx, "gh", "api", "--method", "POST",
-		"-H", "Accept: application/vnd.github+json",
-		apiPath,
-		"-f", "title="+title)
-	
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	
-	if err := cmd.Run(); err != nil {
-		stderrStr := strings.TrimSpace(stderr.String())
-		if strings.Contains(stderrStr, "authentication") || strings.Contains(stderrStr, "auth") {
-			return 0, fmt.Errorf("gh error: ensure you're authenticated ('gh auth login') and running inside a GitHub repo")
-		}
-		return 0, fmt.Errorf("gh api error: %s", stderrStr)
+	args := []string{"issue", "create", "--title", draft.Title, "--body-file", bodyFile}
+	for _, label := range draft.Labels {
+		args = append(args, "--label", label)
 	}
-	
-	var response CreateIssueResponse
-	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
-		return 0, fmt.Errorf("failed to parse API response: %w", err)
+	for _, assignee := range draft.Assignees {
+		args = append(args, "--assignee", assignee)
+	}
+	if draft.Milestone != "" {
+		args = append(args, "--milestone", draft.Milestone)
 	}
 	
-	if response.Number == 0 {
-		return 0, fmt.Errorf("API response missing issue number")
+	out, err := c.run(args...)
+	if err != nil {
+		return 0, err
 	}
 	
-	return response.Number, nil
+	// gh prints the URL of the new issue.
+	m := issueURLRegex.FindSubmatch(out)
+	if m == nil {
+		return 0, fmt.Errorf("unexpected gh issue create output: %s", strings.TrimSpace(string(out)))
+	}
+	return strconv.Atoi(string(m[1]))
 }
 
-func CloseIssue(issueNumber string) error {
-	if err := checkGHAvailable(); err != nil {
+func (c *CLI) ListCreatedIssues(since time.Time) ([]*model.IssueData, error) {
+	return backend.ListCreatedIssues(c, since)
+}
+
+func (c *CLI) CloseIssue(issueNumber string) error {
+	_, err := c.run("issue", "close", issueNumber)
+	return err
+}
+
+func (c *CLI) ReopenIssue(issueNumber string) error {
+	_, err := c.run("issue", "reopen", issueNumber)
+	return err
+}
+
+func (c *CLI) ListComments(issueNumber string) ([]model.Comment, error) {
+	return backend.ListComments(c, issueNumber)
+}
+
+func (c *CLI) AddComment(issueNumber string, body string) error {
+	bodyFile, err := CreateTempBodyFile([]byte(body))
+	if err != nil {
 		return err
 	}
+	defer os.Remove(bodyFile)
 	
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
-	defer cancel()
-	
-	cmd := exec.CommandContext(ctx, "gh", "issue", "close", issueNumber)
-	
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	
-	if err := cmd.Run(); err != nil {
-		stderrStr := strings.TrimSpace(stderr.String())
-		if strings.Contains(stderrStr, "authentication") || strings.Contains(stderrStr, "auth") {
-			return fmt.Errorf("gh error: ensure you're authenticated ('gh auth login') and running inside a GitHub repo")
-		}
-		if strings.Contains(stderrStr, "not found") || strings.Contains(stderrStr, "404") {
-			return fmt.Errorf("gh error: issue not found or repo not set")
-		}
-		if strings.Contains(stderrStr, "permission") || strings.Contains(stderrStr, "forbidden") {
-			return fmt.Errorf("gh error: permission denied")
-		}
-		return fmt.Errorf("gh error: %s", stderrStr)
-	}
-	
-	// Check if gh printed output - if not, we'll print our own success message
-	if stdoutStr := strings.TrimSpace(stdout.String()); stdoutStr != "" {
-		fmt.Print(stdoutStr)
-		if !strings.HasSuffix(stdoutStr, "\n") {
-			fmt.Println()
-		}
-	} else {
-		fmt.Printf("Closed issue #%s.\n", issueNumber)
-	}
-	
-	return nil
+	_, err = c.run("issue", "comment", issueNumber, "--body-file", bodyFile)
+	return err
 }
 
-func ReopenIssue(issueNumber string) error {
-	if err := checkGHAvailable(); err != nil {
+func (c *CLI) EditComment(id int64, body string) error {
+	// The body goes through --input so gh does not reinterpret its content.
+	input, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
 		return err
 	}
-	
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
-	defer cancel()
-	
-	cmd := exec.CommandContext(ctx, "gh", "issue", "reopen", issueNumber)
-	
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	
-	if err := cmd.Run(); err != nil {
-		stderrStr := strings.TrimSpace(stderr.String())
-		if strings.Contains(stderrStr, "authentication") || strings.Contains(stderrStr, "auth") {
-			return fmt.Errorf("gh error: ensure you're authenticated ('gh auth login') and running inside a GitHub repo")
-		}
-		if strings.Contains(stderrStr, "not found") || strings.Contains(stderrStr, "404") {
-			return fmt.Errorf("gh error: issue not found or repo not set")
-		}
-		if strings.Contains(stderrStr, "permission") || strings.Contains(stderrStr, "forbidden") {
-			return fmt.Errorf("gh error: permission denied")
-		}
-		return fmt.Errorf("gh error: %s", stderrStr)
-	}
-	
-	// Check if gh printed output - if not, we'll print our own success message
-	if stdoutStr := strings.TrimSpace(stdout.String()); stdoutStr != "" {
-		fmt.Print(stdoutStr)
-		if !strings.HasSuffix(stdoutStr, "\n") {
-			fmt.Println()
-		}
-	} else {
-		fmt.Printf("Reopened issue #%s.\n", issueNumber)
+	inputFile, err := CreateTempBodyFile(input)
+	if err != nil {
+		return err
 	}
+	defer os.Remove(inputFile)
 	
-	return nil
+	_, err = c.run("api", "--method", "PATCH",
+		"-H", "Accept: application/vnd.github+json",
+		fmt.Sprintf("repos/{owner}/{repo}/issues/comments/%d", id),
+		"--input", inputFile)
+	return err
 }
 
-func ListIssues(extraArgs []string) ([]model.IssueListItem, error) {
-	if err := checkGHAvailable(); err != nil {
-		return nil, err
-	}
-	
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
-	defer cancel()
-	
-	args := []string{"issue", "list", "--json", "number,title,url"}
-	args = append(args, extraArgs...)
+func (c *CLI) ListIssues(opts backend.ListOptions) ([]model.IssueListItem, error) {
+	args := []string{"issue", "list", "--json", "number,title,url,state,labels,assignees,author,updatedAt"}
 	
-	cmd := exec.CommandContext(ctx, "gh", args...)
-	
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	if opts.State != "" {
+		args = append(args, "--state", opts.State)
+	}
+	for _, label := range opts.Labels {
+		args = append(args, "--label", label)
+	}
+	if opts.Assignee != "" {
+		args = append(args, "--assignee", opts.Assignee)
+	}
+	if opts.Author != "" {
+		args = append(args, "--author", opts.Author)
+	}
+	if opts.Mention != "" {
+		args = append(args, "--mention", opts.Mention)
+	}
+	if opts.Milestone != "" {
+		args = append(args, "--milestone", opts.Milestone)
+	}
+	if opts.Search != "" {
+		args = append(args, "--search", opts.Search)
+	}
+	if opts.Limit > 0 {
+		args = append(args, "--limit", strconv.Itoa(opts.Limit))
+	}
 	
-	if err := cmd.Run(); err != nil {
-		stderrStr := strings.TrimSpace(stderr.String())
-		// Check for invalid options/flags first (these typically come with exit code 1)
-		if strings.Contains(stderrStr, "unknown flag") || strings.Contains(stderrStr, "invalid") {
-			// Return just the error message for usage errors
-			return nil, fmt.Errorf("%s", stderrStr)
-		}
-		if strings.Contains(stderrStr, "authentication") || strings.Contains(stderrStr, "auth") {
-			return nil, fmt.Errorf("gh error: ensure you're authenticated ('gh auth login') and running inside a GitHub repo")
-		}
-		if strings.Contains(stderrStr, "not found") || strings.Contains(stderrStr, "repository") {
-			return nil, fmt.Errorf("gh error: repository not found or not set")
-		}
-		return nil, fmt.Errorf("gh error: %s", stderrStr)
+	out, err := c.run(args...)
+	if err != nil {
+		return nil, err
 	}
 	
 	var issues []model.IssueListItem
-	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
+	if err := json.Unmarshal(out, &issues); err != nil {
 		return nil, fmt.Errorf("failed to parse gh output: %w", err)
 	}
 	
 	return issues, nil
 }
 
-func ListClosedIssues() ([]model.IssueListItem, error) {
-	return ListIssues([]string{"--state", "closed"})
+// GraphQL runs a query against the current repository through `gh api graphql`.
+// gh exits non-zero when the response carries errors, so the body is still
+// decoded whenever it is valid JSON and the caller inspects the errors.
+func (c *CLI) GraphQL(query string, vars map[string]string, out any) error {
+	args := []string{"api", "graphql",
+		"-F", "owner={owner}",
+		"-F", "name={repo}",
+		"-f", "query=" + query}
+	for k, v := range vars {
+		args = append(args, "-f", k+"="+v)
+	}
+	
+	stdout, err := c.run(args...)
+	if err != nil && !json.Valid(stdout) {
+		return err
+	}
+	
+	if err := json.Unmarshal(stdout, out); err != nil {
+		return fmt.Errorf("failed to parse API response: %w", err)
+	}
+	
+	return nil
 }
\ No newline at end of file
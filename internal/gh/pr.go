@@ -0,0 +1,96 @@
+package gh
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+const prViewFields = "number,title,body,state,isDraft,baseRefName,headRefName,labels,reviewRequests,updatedAt"
+
+func (c *CLI) ViewPR(prNumber string) (*model.PRData, error) {
+	out, err := c.run("pr", "view", prNumber, "--json", prViewFields)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Review requests are users (login) or teams (slug).
+	var pr struct {
+		model.PRData
+		ReviewRequests []struct {
+			Login string `json:"login"`
+			Slug  string `json:"slug"`
+		} `json:"reviewRequests"`
+	}
+	if err := json.Unmarshal(out, &pr); err != nil {
+		return nil, fmt.Errorf("failed to parse gh output: %w", err)
+	}
+	
+	for _, r := range pr.ReviewRequests {
+		login := r.Login
+		if login == "" && r.Slug != "" {
+			// gh takes teams as ORG/SLUG, like the file lists them.
+			login = r.Slug
+			if !strings.Contains(login, "/") {
+				repo, err := c.Repository()
+				if err != nil {
+					return nil, err
+				}
+				owner, _, _ := strings.Cut(repo, "/")
+				login = owner + "/" + r.Slug
+			}
+		}
+		pr.PRData.ReviewRequests = append(pr.PRData.ReviewRequests, model.Reviewer{Login: login})
+	}
+	
+	return &pr.PRData, nil
+}
+
+// EditPR runs gh pr edit, then gh pr ready to change the draft state, which
+// gh pr edit cannot do.
+func (c *CLI) EditPR(prNumber string, edit model.PREdit) error {
+	bodyFile, err := CreateTempBodyFile([]byte(edit.Body))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(bodyFile)
+	
+	args := []string{"pr", "edit", prNumber, "--body-file", bodyFile}
+	if strings.TrimSpace(edit.Title) != "" {
+		args = append(args, "--title", edit.Title)
+	}
+	if edit.Base != "" {
+		args = append(args, "--base", edit.Base)
+	}
+	for _, label := range edit.AddLabels {
+		args = append(args, "--add-label", label)
+	}
+	for _, label := range edit.RemoveLabels {
+		args = append(args, "--remove-label", label)
+	}
+	for _, reviewer := range edit.AddReviewers {
+		args = append(args, "--add-reviewer", reviewer)
+	}
+	for _, reviewer := range edit.RemoveReviewers {
+		args = append(args, "--remove-reviewer", reviewer)
+	}
+	
+	if _, err := c.run(args...); err != nil {
+		return err
+	}
+	
+	if edit.Draft != nil {
+		args := []string{"pr", "ready", prNumber}
+		if *edit.Draft {
+			args = append(args, "--undo")
+		}
+		if _, err := c.run(args...); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,350 @@
+package issuetemplate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Form is a GitHub issue form: a YAML template whose body is a list of
+// fields. ghi turns it into a markdown draft with one section per field and
+// renders the filled-in draft the way GitHub renders a submitted form.
+type Form struct {
+	Name        string    `yaml:"name"`
+	Description string    `yaml:"description"`
+	Title       string    `yaml:"title"`
+	Labels      list      `yaml:"labels"`
+	Assignees   list      `yaml:"assignees"`
+	Body        []Element `yaml:"body"`
+}
+
+// Element is one entry of a form's body.
+type Element struct {
+	Type        string      `yaml:"type"`
+	ID          string      `yaml:"id"`
+	Attributes  Attributes  `yaml:"attributes"`
+	Validations Validations `yaml:"validations"`
+}
+
+type Attributes struct {
+	Label       string   `yaml:"label"`
+	Description string   `yaml:"description"`
+	Placeholder string   `yaml:"placeholder"`
+	Value       string   `yaml:"value"`
+	Render      string   `yaml:"render"`
+	Multiple    bool     `yaml:"multiple"`
+	Options     []Option `yaml:"options"`
+	Default     *int     `yaml:"default"`
+}
+
+type Validations struct {
+	Required bool `yaml:"required"`
+}
+
+// Option is a dropdown choice (a plain string in the schema) or a checkbox
+// (a mapping with its own required flag).
+type Option struct {
+	Label    string `yaml:"label"`
+	Required bool   `yaml:"required"`
+}
+
+func (o *Option) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		o.Label = node.Value
+		return nil
+	}
+	type plain Option
+	return node.Decode((*plain)(o))
+}
+
+const (
+	typeMarkdown   = "markdown"
+	typeInput      = "input"
+	typeTextarea   = "textarea"
+	typeDropdown   = "dropdown"
+	typeCheckboxes = "checkboxes"
+)
+
+// noResponse is what GitHub shows for a field left empty.
+const noResponse = "_No response_"
+
+// ParseForm reads an issue form and checks the parts of the schema ghi
+// relies on.
+func ParseForm(raw []byte) (*Form, error) {
+	var f Form
+	if err := yaml.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse issue form: %w", err)
+	}
+	
+	if len(f.Body) == 0 {
+		return nil, errors.New("issue form has no body")
+	}
+	labels := map[string]bool{}
+	for i, e := range f.Body {
+		switch e.Type {
+		case typeMarkdown:
+			continue
+		case typeInput, typeTextarea, typeDropdown, typeCheckboxes:
+		default:
+			return nil, fmt.Errorf("body[%d]: unknown type %q", i, e.Type)
+		}
+		
+		label := strings.TrimSpace(e.Attributes.Label)
+		if label == "" {
+			return nil, fmt.Errorf("body[%d]: %s has no label", i, e.Type)
+		}
+		if labels[label] {
+			return nil, fmt.Errorf("body[%d]: duplicate label %q", i, label)
+		}
+		labels[label] = true
+		
+		if (e.Type == typeDropdown || e.Type == typeCheckboxes) && len(e.Attributes.Options) == 0 {
+			return nil, fmt.Errorf("body[%d]: %s %q has no options", i, e.Type, label)
+		}
+	}
+	
+	return &f, nil
+}
+
+// fields returns the elements that take input, skipping markdown.
+func (f *Form) fields() []Element {
+	var fields []Element
+	for _, e := range f.Body {
+		if e.Type != typeMarkdown {
+			fields = append(fields, e)
+		}
+	}
+	return fields
+}
+
+var formMarkerRegex = regexp.MustCompile(`^<!-- ghi:form template=([^\s:]+)`)
+
+// FormRef returns the template file name a draft body was generated from,
+// if it is a form draft.
+func FormRef(body string) (string, bool) {
+	m := formMarkerRegex.FindStringSubmatch(strings.TrimLeft(body, "\r\n"))
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// Draft returns the markdown draft for the form: a marker naming the
+// template file, then a "### label" section per field with a hint comment
+// and any default value.
+func (f *Form) Draft(file string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!-- ghi:form template=%s: fill in each section; required fields are checked when the issue is created -->\n", file)
+	
+	for _, e := range f.fields() {
+		fmt.Fprintf(&b, "\n### %s\n%s\n\n", strings.TrimSpace(e.Attributes.Label), hint(e))
+		if value := initialValue(e); value != "" {
+			b.WriteString(value + "\n")
+		}
+	}
+	
+	return b.String()
+}
+
+// hint is the comment under a field's heading in the draft, explaining what
+// to fill in.
+func hint(e Element) string {
+	a := e.Attributes
+	parts := []string{"Optional."}
+	if e.Validations.Required {
+		parts[0] = "Required."
+	}
+	if a.Description != "" {
+		parts = append(parts, oneLine(a.Description))
+	}
+	
+	switch e.Type {
+	case typeInput, typeTextarea:
+		if a.Placeholder != "" {
+			parts = append(parts, "e.g. "+oneLine(a.Placeholder))
+		}
+	case typeDropdown:
+		if a.Multiple {
+			parts = append(parts, "Choose any of (one per line):", optionList(a.Options))
+		} else {
+			parts = append(parts, "Choose one of:", optionList(a.Options))
+		}
+	case typeCheckboxes:
+		parts = append(parts, "Check with [x].")
+	}
+	
+	return "<!-- " + strings.Join(parts, " ") + " -->"
+}
+
+// initialValue is the default answer a field starts with in the draft.
+func initialValue(e Element) string {
+	a := e.Attributes
+	switch e.Type {
+	case typeDropdown:
+		if a.Default != nil && *a.Default >= 0 && *a.Default < len(a.Options) {
+			return a.Options[*a.Default].Label
+		}
+		return ""
+	case typeCheckboxes:
+		lines := make([]string, 0, len(a.Options))
+		for _, o := range a.Options {
+			lines = append(lines, "- [ ] "+o.Label)
+		}
+		return strings.Join(lines, "\n")
+	}
+	return strings.TrimSpace(a.Value)
+}
+
+// Render reads a filled-in draft, validates it against the form and returns
+// the issue body as GitHub renders a submitted form. Every problem is
+// reported, not just the first.
+func (f *Form) Render(draft string) (string, error) {
+	fields := f.fields()
+	values := splitSections(draft, fields)
+	
+	var problems []error
+	sections := make([]string, 0, len(fields))
+	for i, e := range fields {
+		label := strings.TrimSpace(e.Attributes.Label)
+		value, err := renderValue(e, values[i])
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%q: %w", label, err))
+			continue
+		}
+		sections = append(sections, "### "+label+"\n\n"+value)
+	}
+	
+	if len(problems) > 0 {
+		return "", errors.Join(problems...)
+	}
+	return strings.Join(sections, "\n\n"), nil
+}
+
+// trimChoice strips the spaces and list marker around a dropdown choice.
+func trimChoice(item string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(item), "- "))
+}
+
+func renderValue(e Element, text string) (string, error) {
+	a := e.Attributes
+	
+	switch e.Type {
+	case typeDropdown:
+		isOption := func(item string) bool {
+			return slices.ContainsFunc(a.Options, func(o Option) bool { return o.Label == item })
+		}
+		var chosen []string
+		for _, line := range strings.Split(text, "\n") {
+			// A whole line naming an option is taken as is, so labels may
+			// contain commas; otherwise the line may list several choices.
+			items := []string{line}
+			if !isOption(trimChoice(line)) {
+				items = strings.Split(line, ",")
+			}
+			for _, item := range items {
+				item = trimChoice(item)
+				if item == "" {
+					continue
+				}
+				if !isOption(item) {
+					return "", fmt.Errorf("%q is not one of %s", item, optionList(a.Options))
+				}
+				chosen = append(chosen, item)
+			}
+		}
+		switch {
+		case len(chosen) == 0 && e.Validations.Required:
+			return "", errors.New("is required")
+		case len(chosen) > 1 && !a.Multiple:
+			return "", errors.New("allows only one choice")
+		case len(chosen) == 0:
+			return noResponse, nil
+		}
+		return strings.Join(chosen, ", "), nil
+	
+	case typeCheckboxes:
+		checked := map[string]bool{}
+		for _, line := range strings.Split(text, "\n") {
+			line = strings.TrimSpace(line)
+			for _, box := range []string{"- [x] ", "- [X] "} {
+				if label, ok := strings.CutPrefix(line, box); ok {
+					checked[strings.TrimSpace(label)] = true
+				}
+			}
+		}
+		lines := make([]string, 0, len(a.Options))
+		for _, o := range a.Options {
+			if !checked[o.Label] {
+				if o.Required {
+					return "", fmt.Errorf("%q must be checked", o.Label)
+				}
+				lines = append(lines, "- [ ] "+o.Label)
+				continue
+			}
+			lines = append(lines, "- [X] "+o.Label)
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+	
+	if text == "" {
+		if e.Validations.Required {
+			return "", errors.New("is required")
+		}
+		return noResponse, nil
+	}
+	if e.Type == typeTextarea && a.Render != "" {
+		return "```" + a.Render + "\n" + text + "\n```", nil
+	}
+	return text, nil
+}
+
+// splitSections returns the text under each field's heading, in field order.
+// Only headings that match a later field's label start a section, so "###"
+// lines inside an answer are kept. The generated hints are dropped.
+func splitSections(draft string, fields []Element) []string {
+	values := make([]string, len(fields))
+	current := -1
+	var lines []string
+	flush := func() {
+		if current >= 0 {
+			values[current] = strings.TrimSpace(strings.Join(lines, "\n"))
+		}
+		lines = nil
+	}
+	
+	for _, line := range strings.Split(strings.ReplaceAll(draft, "\r\n", "\n"), "\n") {
+		if heading, ok := strings.CutPrefix(line, "### "); ok {
+			next := slices.IndexFunc(fields[current+1:], func(e Element) bool {
+				return strings.TrimSpace(e.Attributes.Label) == strings.TrimSpace(heading)
+			})
+			if next >= 0 {
+				flush()
+				current += 1 + next
+				continue
+			}
+		}
+		if current >= 0 && strings.TrimSpace(line) == hint(fields[current]) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	
+	return values
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, "-->", "->")), " ")
+}
+
+func optionList(options []Option) string {
+	labels := make([]string, 0, len(options))
+	for _, o := range options {
+		labels = append(labels, o.Label)
+	}
+	return strings.Join(labels, ", ")
+}
\ No newline at end of file
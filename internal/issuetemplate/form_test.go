@@ -0,0 +1,123 @@
+package issuetemplate
+
+import (
+	"strings"
+	"testing"
+)
+
+const bugForm = `name: Bug report
+description: File a bug report
+title: "[Bug]: "
+labels: ["bug", "triage"]
+body:
+  - type: markdown
+    attributes:
+      value: Thanks for taking the time to fill out this bug report!
+  - type: input
+    id: contact
+    attributes:
+      label: Contact details
+      placeholder: ex. email@example.com
+  - type: textarea
+    id: what-happened
+    attributes:
+      label: What happened?
+      description: Also tell us, what did you expect to happen?
+    validations:
+      required: true
+  - type: dropdown
+    id: version
+    attributes:
+      label: Version
+      options:
+        - 1.0.2 (Default)
+        - 1.0.3 (Edge)
+      default: 0
+    validations:
+      required: true
+  - type: textarea
+    id: logs
+    attributes:
+      label: Relevant log output
+      render: shell
+  - type: checkboxes
+    id: terms
+    attributes:
+      label: Code of Conduct
+      options:
+        - label: I agree to follow this project's Code of Conduct
+          required: true
+        - label: I searched existing issues
+`
+
+func TestFormDraftAndRender(t *testing.T) {
+	f, err := ParseForm([]byte(bugForm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	draft := f.Draft("bug.yml")
+	if file, ok := FormRef(draft); !ok || file != "bug.yml" {
+		t.Fatalf("FormRef = %q, %v", file, ok)
+	}
+	for _, want := range []string{"\n### Contact details\n<!-- Optional. e.g. ex. email@example.com -->\n", "\n### Version\n<!-- Required. Choose one of: 1.0.2 (Default), 1.0.3 (Edge) -->\n\n1.0.2 (Default)\n", "- [ ] I searched existing issues\n"} {
+		if !strings.Contains(draft, want) {
+			t.Errorf("draft lacks %q:\n%s", want, draft)
+		}
+	}
+	
+	if _, err := f.Render(draft); err == nil || !strings.Contains(err.Error(), `"What happened?": is required`) || !strings.Contains(err.Error(), "must be checked") {
+		t.Errorf("Render of an empty draft: %v", err)
+	}
+	
+	filled := strings.Replace(draft, "- [ ] I agree", "- [x] I agree", 1)
+	filled = strings.Replace(filled, "### Relevant log output\n", "### Relevant log output\npanic: boom\n", 1)
+	filled = strings.Replace(filled, "### What happened?\n<!-- Required. Also tell us, what did you expect to happen? -->\n", "### What happened?\nIt crashed.\n\n### Not a field\n", 1)
+	
+	got, err := f.Render(filled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "### Contact details\n\n_No response_\n\n" +
+		"### What happened?\n\nIt crashed.\n\n### Not a field\n\n" +
+		"### Version\n\n1.0.2 (Default)\n\n" +
+		"### Relevant log output\n\n```shell\npanic: boom\n```\n\n" +
+		"### Code of Conduct\n\n- [X] I agree to follow this project's Code of Conduct\n- [ ] I searched existing issues"
+	if got != want {
+		t.Errorf("Render =\n%s\nwant\n%s", got, want)
+	}
+	
+	if _, err := f.Render(strings.Replace(filled, "1.0.2 (Default)\n", "2.0\n", 1)); err == nil || !strings.Contains(err.Error(), `"2.0" is not one of`) {
+		t.Errorf("Render with an unknown option: %v", err)
+	}
+}
+
+func TestRenderDropdownLabelsWithCommas(t *testing.T) {
+	e := Element{Type: typeDropdown, Attributes: Attributes{Multiple: true, Options: []Option{{Label: "Yes, always"}, {Label: "No"}, {Label: "Sometimes"}}}}
+	tests := []struct{ text, want string }{
+		{"Yes, always\n", "Yes, always"},
+		{"- Yes, always\nNo\n", "Yes, always, No"},
+		{"No, Sometimes\n", "No, Sometimes"},
+	}
+	for _, tt := range tests {
+		if got, err := renderValue(e, tt.text); err != nil || got != tt.want {
+			t.Errorf("renderValue(%q) = %q, %v; want %q", tt.text, got, err, tt.want)
+		}
+	}
+	if _, err := renderValue(e, "Yes, never\n"); err == nil || !strings.Contains(err.Error(), `"Yes" is not one of`) {
+		t.Errorf("renderValue of an unknown option: %v", err)
+	}
+}
+
+func TestParseFormErrors(t *testing.T) {
+	for _, src := range []string{
+		"name: x\n",
+		"body:\n  - type: slider\n    attributes: {label: x}\n",
+		"body:\n  - type: input\n    attributes: {}\n",
+		"body:\n  - type: dropdown\n    attributes: {label: x}\n",
+	} {
+		if _, err := ParseForm([]byte(src)); err == nil {
+			t.Errorf("ParseForm(%q) succeeded", src)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,225 @@
+// Package issuetemplate reads a repository's GitHub issue templates from
+// .github/ISSUE_TEMPLATE/: markdown templates and YAML issue forms.
+package issuetemplate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template is an issue template. A markdown template's frontmatter supplies
+// the defaults for a new issue and the rest of the file is the body. For an
+// issue form, Form is set and Body is the form's draft.
+type Template struct {
+	Name      string   `yaml:"name"`
+	About     string   `yaml:"about"`
+	Title     string   `yaml:"title"`
+	Labels    []string `yaml:"-"`
+	Assignees []string `yaml:"-"`
+	Body      string   `yaml:"-"`
+	Form      *Form    `yaml:"-"`
+	
+	// Path is the template file.
+	Path string `yaml:"-"`
+}
+
+// Dir returns the issue template directory of the checkout containing start,
+// or "" if there is none. The search stops at the repository root.
+func Dir(start string) string {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return ""
+	}
+	
+	for {
+		candidate := filepath.Join(dir, ".github", "ISSUE_TEMPLATE")
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load reads every template in dir, sorted by file name. A missing directory
+// has no templates. config.yml configures GitHub's template chooser and is
+// not a template.
+func Load(dir string) ([]*Template, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || isChooserConfig(name) {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".md", ".yml", ".yaml":
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	
+	templates := make([]*Template, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var t *Template
+		if strings.EqualFold(filepath.Ext(name), ".md") {
+			t, err = Parse(raw)
+		} else {
+			t, err = formTemplate(raw, name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		t.Path = path
+		if t.Name == "" {
+			t.Name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		templates = append(templates, t)
+	}
+	
+	return templates, nil
+}
+
+// Parse reads a markdown template. The frontmatter is optional.
+func Parse(raw []byte) (*Template, error) {
+	content := strings.TrimPrefix(string(raw), "\ufeff")
+	
+	var t Template
+	header, body, ok := splitFrontmatter(content)
+	if !ok {
+		t.Body = content
+		return &t, nil
+	}
+	
+	var fm struct {
+		Template  `yaml:",inline"`
+		Labels    list `yaml:"labels"`
+		Assignees list `yaml:"assignees"`
+	}
+	if err := yaml.Unmarshal([]byte(header), &fm); err != nil {
+		return nil, fmt.Errorf("failed to parse template frontmatter: %w", err)
+	}
+	
+	t = fm.Template
+	t.Labels = fm.Labels
+	t.Assignees = fm.Assignees
+	t.Body = body
+	return &t, nil
+}
+
+func formTemplate(raw []byte, file string) (*Template, error) {
+	f, err := ParseForm(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{
+		Name:      f.Name,
+		About:     f.Description,
+		Title:     f.Title,
+		Labels:    f.Labels,
+		Assignees: f.Assignees,
+		Body:      f.Draft(file),
+		Form:      f,
+	}, nil
+}
+
+func isChooserConfig(name string) bool {
+	return strings.EqualFold(name, "config.yml") || strings.EqualFold(name, "config.yaml")
+}
+
+// FindFile returns the template loaded from the named file.
+func FindFile(templates []*Template, file string) *Template {
+	for _, t := range templates {
+		if filepath.Base(t.Path) == file {
+			return t
+		}
+	}
+	return nil
+}
+
+// Find returns the template whose name or file name (without extension)
+// matches name, ignoring case.
+func Find(templates []*Template, name string) *Template {
+	for _, t := range templates {
+		base := filepath.Base(t.Path)
+		if strings.EqualFold(t.Name, name) || strings.EqualFold(strings.TrimSuffix(base, filepath.Ext(base)), name) {
+			return t
+		}
+	}
+	return nil
+}
+
+func splitFrontmatter(content string) (header string, body string, ok bool) {
+	rest, found := strings.CutPrefix(content, "---\n")
+	if !found {
+		if rest, found = strings.CutPrefix(content, "---\r\n"); !found {
+			return "", "", false
+		}
+	}
+	
+	for offset := 0; offset < len(rest); {
+		end := strings.IndexByte(rest[offset:], '\n')
+		line := rest[offset:]
+		if end >= 0 {
+			line = rest[offset : offset+end]
+		}
+		if strings.TrimSpace(line) == "---" {
+			if end < 0 {
+				return rest[:offset], "", true
+			}
+			return rest[:offset], rest[offset+end+1:], true
+		}
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+	
+	return "", "", false
+}
+
+// list accepts both a YAML sequence and GitHub's comma-separated string form,
+// e.g. "bug, needs triage".
+type list []string
+
+func (l *list) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = nil
+		for _, item := range strings.Split(node.Value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*l = append(*l, item)
+			}
+		}
+		return nil
+	}
+	
+	var items []string
+	if err := node.Decode(&items); err != nil {
+		return err
+	}
+	*l = items
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,72 @@
+package merge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// CommentEdits returns the comments in a local section whose text differs
+// from the base version, i.e. the ones edited since the last pull.
+func CommentEdits(base []model.Comment, local *model.CommentSection) []model.Comment {
+	if local == nil {
+		return nil
+	}
+	
+	baseBodies := make(map[int64]string, len(base))
+	for _, c := range base {
+		baseBodies[c.ID] = c.Body
+	}
+	
+	var edits []model.Comment
+	for _, c := range local.Comments {
+		if body, ok := baseBodies[c.ID]; ok && body != c.Body {
+			edits = append(edits, c)
+		}
+	}
+	return edits
+}
+
+// CommentsModified reports whether a local section has comment edits or a new
+// comment to push.
+func CommentsModified(base []model.Comment, local *model.CommentSection) bool {
+	return local != nil && (strings.TrimSpace(local.New) != "" || len(CommentEdits(base, local)) > 0)
+}
+
+// Comments rebuilds a comments section from the remote comments, keeping
+// local work: the new comment text and edits to the user's own comments.
+// Edits to other people's comments are dropped since they cannot be pushed.
+// An own comment edited on both sides keeps the local text and is reported
+// as a conflict, like a conflicting title.
+func Comments(base []model.Comment, local *model.CommentSection, remote []model.Comment) (*model.CommentSection, []string) {
+	section := &model.CommentSection{Comments: make([]model.Comment, len(remote))}
+	copy(section.Comments, remote)
+	if local == nil {
+		return section, nil
+	}
+	section.New = local.New
+	
+	edited := make(map[int64]string)
+	for _, c := range CommentEdits(base, local) {
+		edited[c.ID] = c.Body
+	}
+	baseBodies := make(map[int64]string, len(base))
+	for _, c := range base {
+		baseBodies[c.ID] = c.Body
+	}
+	
+	var conflicts []string
+	for i, c := range section.Comments {
+		body, ok := edited[c.ID]
+		if !ok || !c.Mine || body == c.Body {
+			continue
+		}
+		if c.Body != baseBodies[c.ID] {
+			conflicts = append(conflicts, fmt.Sprintf("comment %d", c.ID))
+		}
+		section.Comments[i].Body = body
+	}
+	
+	return section, conflicts
+}
\ No newline at end of file
@@ -0,0 +1,417 @@
+package merge
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/nomnel/ghi/internal/diff"
+	"github.com/nomnel/ghi/internal/model"
+)
+
+const (
+	markerLocal  = "<<<<<<< local"
+	markerBase   = "======="
+	markerRemote = ">>>>>>> remote"
+	// markerScalarBase introduces the base value in a scalar conflict.
+	markerScalarBase = "||||||| base"
+)
+
+// Text performs a line-based three-way merge of local and remote against their
+// common base. Regions changed on only one side take that side; regions changed
+// differently on both sides are written with git-style conflict markers and
+// reported through the second return value.
+func Text(base, local, remote string) (string, bool) {
+	o := diff.SplitLines(base)
+	a := diff.SplitLines(local)
+	b := diff.SplitLines(remote)
+	
+	toA := matchMap(diff.Matches(o, a), len(o))
+	toB := matchMap(diff.Matches(o, b), len(o))
+	
+	var out strings.Builder
+	conflict := false
+	
+	i, j, k := 0, 0, 0
+	for i < len(o) || j < len(a) || k < len(b) {
+		// Copy lines that are unchanged on both sides.
+		n := 0
+		for i+n < len(o) && toA[i+n] == j+n && toB[i+n] == k+n {
+			n++
+		}
+		if n > 0 {
+			for _, line := range o[i : i+n] {
+				out.WriteString(line)
+			}
+			i, j, k = i+n, j+n, k+n
+			continue
+		}
+		
+		// Find the next base line both sides still share.
+		next := i
+		for next < len(o) && (toA[next] < 0 || toB[next] < 0) {
+			next++
+		}
+		endA, endB := len(a), len(b)
+		if next < len(o) {
+			endA, endB = toA[next], toB[next]
+		}
+		
+		if resolveChunk(&out, o[i:next], a[j:endA], b[k:endB]) {
+			conflict = true
+		}
+		i, j, k = next, endA, endB
+	}
+	
+	return out.String(), conflict
+}
+
+// HasConflictMarkers reports whether s still contains unresolved markers
+// written by Text.
+func HasConflictMarkers(s string) bool {
+	for _, line := range diff.SplitLines(s) {
+		line = strings.TrimRight(line, "\r\n")
+		if line == markerLocal || line == markerRemote {
+			return true
+		}
+	}
+	return false
+}
+
+// scalarConflict is the value of a frontmatter field changed differently on
+// both sides, for example
+//
+//	title: <<<<<<< local Fix sign-in ||||||| base Fix login ======= Fix logout >>>>>>> remote
+//
+// It stays in the file, and push refuses to run, until the field is
+// resolved by hand.
+func scalarConflict(base, local, remote string) string {
+	return markerLocal + " " + local + " " + markerScalarBase + " " + base + " " + markerBase + " " + remote + " " + markerRemote
+}
+
+// ScalarConflicts returns the frontmatter fields that still hold conflict
+// markers written by Issue.
+func ScalarConflicts(fm model.Frontmatter) []string {
+	var fields []string
+	for _, f := range []struct{ name, value string }{{"title", fm.Title}, {"milestone", fm.Milestone}, {"state", fm.State}} {
+		if strings.HasPrefix(f.value, markerLocal+" ") && strings.HasSuffix(f.value, " "+markerRemote) {
+			fields = append(fields, f.name)
+		}
+	}
+	return fields
+}
+
+func matchMap(matches []diff.Match, n int) []int {
+	m := make([]int, n)
+	for i := range m {
+		m[i] = -1
+	}
+	for _, match := range matches {
+		m[match.A] = match.B
+	}
+	return m
+}
+
+func resolveChunk(out *strings.Builder, o, a, b []string) bool {
+	switch {
+	case slices.Equal(o, a):
+		writeLines(out, b)
+	case slices.Equal(o, b), slices.Equal(a, b):
+		writeLines(out, a)
+	default:
+		writeMarker(out, markerLocal)
+		writeLines(out, a)
+		writeMarker(out, markerBase)
+		writeLines(out, b)
+		writeMarker(out, markerRemote)
+		return true
+	}
+	return false
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+func writeMarker(out *strings.Builder, marker string) {
+	if s := out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		out.WriteString("\n")
+	}
+	out.WriteString(marker + "\n")
+}
+
+// Result is the outcome of merging two edited versions of an issue.
+type Result struct {
+	Snapshot  model.Snapshot
+	Conflicts []string
+}
+
+// Issue merges local and remote edits of an issue against their common base.
+// Labels and assignees are merged as sets, so additions and removals from both
+// sides are kept. Scalar fields changed differently on both sides are
+// written with one-line conflict markers (see scalarConflict) and, like a
+// conflicting body, reported as conflicts.
+func Issue(base, local, remote model.Snapshot) Result {
+	var res Result
+	bf, lf, rf := base.Frontmatter, local.Frontmatter, remote.Frontmatter
+	
+	scalar := func(field, b, l, r string) string {
+		v, ok := mergeScalar(b, l, r)
+		if !ok {
+			res.Conflicts = append(res.Conflicts, field)
+			return scalarConflict(b, l, r)
+		}
+		return v
+	}
+	
+	res.Snapshot.Frontmatter = model.Frontmatter{
+		Title:     scalar("title", bf.Title, lf.Title, rf.Title),
+		Labels:    mergeSet(bf.Labels, lf.Labels, rf.Labels),
+		Assignees: mergeSet(bf.Assignees, lf.Assignees, rf.Assignees),
+		Milestone: scalar("milestone", bf.Milestone, lf.Milestone, rf.Milestone),
+		State:     scalar("state", bf.State, lf.State, rf.State),
+	}
+	
+	// A cleared milestone is a local edit, not an unmanaged field.
+	if lf.NoMilestone && bf.Milestone != "" {
+		if rf.Milestone != "" && rf.Milestone != bf.Milestone {
+			res.Conflicts = append(res.Conflicts, "milestone")
+			res.Snapshot.Frontmatter.Milestone = scalarConflict(bf.Milestone, "", rf.Milestone)
+		} else {
+			res.Snapshot.Frontmatter.Milestone, res.Snapshot.Frontmatter.NoMilestone = "", true
+		}
+	}
+	
+	body, conflict := Text(base.Body, local.Body, remote.Body)
+	if conflict {
+		res.Conflicts = append(res.Conflicts, "body")
+	}
+	res.Snapshot.Body = body
+	
+	return res
+}
+
+// Modified reports whether v differs from base in any field the local file
+// manages. Empty scalars and missing lists are unmanaged and never count.
+func Modified(base, v model.Snapshot) bool {
+	bf, vf := base.Frontmatter, v.Frontmatter
+	switch {
+	case v.Body != base.Body:
+		return true
+	case vf.Title != "" && vf.Title != bf.Title:
+		return true
+	case vf.Labels != nil && !sameSet(vf.Labels, bf.Labels):
+		return true
+	case vf.Assignees != nil && !sameSet(vf.Assignees, bf.Assignees):
+		return true
+	case vf.Milestone != "" && vf.Milestone != bf.Milestone:
+		return true
+	case vf.NoMilestone && bf.Milestone != "":
+		return true
+	case vf.State != "" && !strings.EqualFold(vf.State, bf.State):
+		return true
+	}
+	return false
+}
+
+// Equal reports whether a and b have the same content. Labels and assignees
+// are compared as sets and state case-insensitively.
+func Equal(a, b model.Snapshot) bool {
+	af, bf := a.Frontmatter, b.Frontmatter
+	return a.Body == b.Body &&
+		af.Title == bf.Title &&
+		sameSet(af.Labels, bf.Labels) &&
+		sameSet(af.Assignees, bf.Assignees) &&
+		af.Milestone == bf.Milestone &&
+		strings.EqualFold(af.State, bf.State)
+}
+
+// Fill returns v with every unmanaged field taken from from. A cleared
+// milestone stays cleared.
+func Fill(v, from model.Snapshot) model.Snapshot {
+	fm := &v.Frontmatter
+	if fm.Title == "" {
+		fm.Title = from.Frontmatter.Title
+	}
+	if fm.Labels == nil {
+		fm.Labels = from.Frontmatter.Labels
+	}
+	if fm.Assignees == nil {
+		fm.Assignees = from.Frontmatter.Assignees
+	}
+	if fm.Milestone == "" && !fm.NoMilestone {
+		fm.Milestone = from.Frontmatter.Milestone
+	}
+	if fm.State == "" {
+		fm.State = from.Frontmatter.State
+	}
+	return v
+}
+
+// mergeScalar merges a single value. An empty local value is unmanaged and
+// always yields the remote value.
+func mergeScalar(base, local, remote string) (string, bool) {
+	switch {
+	case local == "" || local == remote || local == base:
+		return remote, true
+	case remote == base:
+		return local, true
+	}
+	return local, false
+}
+
+// mergeSet keeps the remote order, drops entries removed locally and appends
+// entries added locally. A nil local list is unmanaged and yields remote.
+func mergeSet(base, local, remote []string) []string {
+	if local == nil {
+		return remote
+	}
+	
+	result := []string{}
+	for _, v := range remote {
+		if slices.Contains(base, v) && !slices.Contains(local, v) {
+			continue
+		}
+		result = append(result, v)
+	}
+	for _, v := range local {
+		if !slices.Contains(base, v) && !slices.Contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func sameSet(a, b []string) bool {
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			return false
+		}
+	}
+	for _, v := range b {
+		if !slices.Contains(a, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// PRResult is the outcome of merging two edited versions of a pull request.
+type PRResult struct {
+	Snapshot  model.PRSnapshot
+	Conflicts []string
+}
+
+// PR merges local and remote edits of a pull request like Issue does. The
+// head branch always comes from the remote, and a draft flag changed on
+// either side wins over the unchanged one.
+func PR(base, local, remote model.PRSnapshot) PRResult {
+	var res PRResult
+	bf, lf, rf := base.Frontmatter, local.Frontmatter, remote.Frontmatter
+	
+	scalar := func(field, b, l, r string) string {
+		v, ok := mergeScalar(b, l, r)
+		if !ok {
+			res.Conflicts = append(res.Conflicts, field)
+		}
+		return v
+	}
+	
+	draft := rf.Draft
+	if lf.Draft != bf.Draft {
+		draft = lf.Draft
+		if rf.Draft != bf.Draft && rf.Draft != lf.Draft {
+			res.Conflicts = append(res.Conflicts, "draft")
+		}
+	}
+	
+	res.Snapshot.Frontmatter = model.PRFrontmatter{
+		Title:     scalar("title", bf.Title, lf.Title, rf.Title),
+		Base:      scalar("base", bf.Base, lf.Base, rf.Base),
+		Head:      rf.Head,
+		Draft:     draft,
+		Labels:    mergeSet(bf.Labels, lf.Labels, rf.Labels),
+		Reviewers: mergeSet(bf.Reviewers, lf.Reviewers, rf.Reviewers),
+	}
+	
+	body, conflict := Text(base.Body, local.Body, remote.Body)
+	if conflict {
+		res.Conflicts = append(res.Conflicts, "body")
+	}
+	res.Snapshot.Body = body
+	
+	return res
+}
+
+// PRModified reports whether v differs from base in any field the local file
+// manages, like Modified.
+func PRModified(base, v model.PRSnapshot) bool {
+	bf, vf := base.Frontmatter, v.Frontmatter
+	switch {
+	case v.Body != base.Body:
+		return true
+	case vf.Title != "" && vf.Title != bf.Title:
+		return true
+	case vf.Base != "" && vf.Base != bf.Base:
+		return true
+	case vf.Draft != bf.Draft:
+		return true
+	case vf.Labels != nil && !sameSet(vf.Labels, bf.Labels):
+		return true
+	case vf.Reviewers != nil && !sameSet(vf.Reviewers, bf.Reviewers):
+		return true
+	}
+	return false
+}
+
+// DiscussionResult is the outcome of merging two edited versions of a
+// discussion.
+type DiscussionResult struct {
+	Snapshot  model.DiscussionSnapshot
+	Conflicts []string
+}
+
+// Discussion merges local and remote edits of a discussion like Issue does.
+// The answered flag always comes from the remote.
+func Discussion(base, local, remote model.DiscussionSnapshot) DiscussionResult {
+	var res DiscussionResult
+	bf, lf, rf := base.Frontmatter, local.Frontmatter, remote.Frontmatter
+	
+	scalar := func(field, b, l, r string) string {
+		v, ok := mergeScalar(b, l, r)
+		if !ok {
+			res.Conflicts = append(res.Conflicts, field)
+		}
+		return v
+	}
+	
+	res.Snapshot.Frontmatter = model.DiscussionFrontmatter{
+		Title:    scalar("title", bf.Title, lf.Title, rf.Title),
+		Category: scalar("category", bf.Category, lf.Category, rf.Category),
+		Answered: rf.Answered,
+	}
+	
+	body, conflict := Text(base.Body, local.Body, remote.Body)
+	if conflict {
+		res.Conflicts = append(res.Conflicts, "body")
+	}
+	res.Snapshot.Body = body
+	
+	return res
+}
+
+// DiscussionModified reports whether v differs from base in any field push
+// sends, like Modified.
+func DiscussionModified(base, v model.DiscussionSnapshot) bool {
+	bf, vf := base.Frontmatter, v.Frontmatter
+	switch {
+	case v.Body != base.Body:
+		return true
+	case vf.Title != "" && vf.Title != bf.Title:
+		return true
+	case vf.Category != "" && vf.Category != bf.Category:
+		return true
+	}
+	return false
+}
\ No newline at end of file
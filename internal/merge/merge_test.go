@@ -0,0 +1,77 @@
+package merge
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nomnel/ghi/internal/model"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name                string
+		base, local, remote string
+		want                string
+		conflict            bool
+	}{
+		{"unchanged", "a\nb\n", "a\nb\n", "a\nb\n", "a\nb\n", false},
+		{"local edit", "a\nb\nc\n", "a\nB\nc\n", "a\nb\nc\n", "a\nB\nc\n", false},
+		{"remote edit", "a\nb\nc\n", "a\nb\nc\n", "a\nb\nC\n", "a\nb\nC\n", false},
+		{"same edit on both sides", "a\nb\n", "a\nB\n", "a\nB\n", "a\nB\n", false},
+		{"separate hunks", "a\nb\nc\nd\ne\n", "A\nb\nc\nd\ne\n", "a\nb\nc\nd\nE\n", "A\nb\nc\nd\nE\n", false},
+		{"adjacent hunks", "a\nb\nc\n", "A\nb\nc\n", "a\nB\nc\n",
+			"<<<<<<< local\nA\nb\n=======\na\nB\n>>>>>>> remote\nc\n", true},
+		{"insert vs insert", "a\nc\n", "a\nb\nc\n", "a\nx\nc\n",
+			"a\n<<<<<<< local\nb\n=======\nx\n>>>>>>> remote\nc\n", true},
+		{"same insert on both sides", "a\nc\n", "a\nb\nc\n", "a\nb\nc\n", "a\nb\nc\n", false},
+		{"delete vs edit", "a\nb\nc\n", "a\nc\n", "a\nB\nc\n",
+			"a\n<<<<<<< local\n=======\nB\n>>>>>>> remote\nc\n", true},
+		{"delete vs unchanged", "a\nb\nc\n", "a\nc\n", "a\nb\nc\nd\n", "a\nc\nd\n", false},
+		{"last line without newline", "a\nb", "a\nB", "a\nb", "a\nB", false},
+		{"newline added to last line", "a\nb\nc", "a\nb\nc\n", "A\nb\nc", "A\nb\nc\n", false},
+		{"conflict on last line without newline", "a\nb", "a\nB", "a\nC",
+			"a\n<<<<<<< local\nB\n=======\nC\n>>>>>>> remote\n", true},
+		{"empty base", "", "local\n", "remote\n",
+			"<<<<<<< local\nlocal\n=======\nremote\n>>>>>>> remote\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflict := Text(tt.base, tt.local, tt.remote)
+			if got != tt.want || conflict != tt.conflict {
+				t.Errorf("Text() = %q, %v; want %q, %v", got, conflict, tt.want, tt.conflict)
+			}
+			if HasConflictMarkers(got) != tt.conflict {
+				t.Errorf("HasConflictMarkers(%q) = %v", got, !tt.conflict)
+			}
+		})
+	}
+}
+
+func TestIssueScalarConflicts(t *testing.T) {
+	snapshot := func(title, milestone, state string) model.Snapshot {
+		return model.Snapshot{Frontmatter: model.Frontmatter{Title: title, Milestone: milestone, State: state}, Body: "body\n"}
+	}
+	base := snapshot("Title", "v1", "open")
+	
+	res := Issue(base, snapshot("Local title", "v1", "closed"), snapshot("Remote title", "v2", "open"))
+	if want := []string{"title"}; !slices.Equal(res.Conflicts, want) {
+		t.Errorf("conflicts = %v, want %v", res.Conflicts, want)
+	}
+	fm := res.Snapshot.Frontmatter
+	if want := "<<<<<<< local Local title ||||||| base Title ======= Remote title >>>>>>> remote"; fm.Title != want || fm.Milestone != "v2" || fm.State != "closed" {
+		t.Errorf("merged = %+v", fm)
+	}
+	if got := ScalarConflicts(fm); !slices.Equal(got, []string{"title"}) {
+		t.Errorf("ScalarConflicts = %v", got)
+	}
+	if res.Snapshot.Body != "body\n" {
+		t.Errorf("scalar conflict wrote into the body: %q", res.Snapshot.Body)
+	}
+	
+	cleared := snapshot("Title", "", "open")
+	cleared.Frontmatter.NoMilestone = true
+	res = Issue(base, cleared, snapshot("Title", "v2", "open"))
+	if !slices.Equal(res.Conflicts, []string{"milestone"}) || !slices.Equal(ScalarConflicts(res.Snapshot.Frontmatter), []string{"milestone"}) {
+		t.Errorf("clearing a remotely changed milestone = %+v", res)
+	}
+}
\ No newline at end of file
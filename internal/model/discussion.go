@@ -0,0 +1,95 @@
+package model
+
+// DiscussionData is a GitHub discussion with its comment thread. ID is the
+// GraphQL node ID that mutations take.
+type DiscussionData struct {
+	ID        string              `json:"id"`
+	Number    int                 `json:"number"`
+	Title     string              `json:"title"`
+	Body      string              `json:"body"`
+	Category  string              `json:"category"`
+	Answered  bool                `json:"isAnswered"`
+	URL       string              `json:"url"`
+	UpdatedAt string              `json:"updatedAt"`
+	Comments  []DiscussionComment `json:"comments"`
+}
+
+// DiscussionComment is a top-level discussion comment or a reply to one.
+// Replies are only set on top-level comments; GitHub does not nest deeper.
+type DiscussionComment struct {
+	ID        int64               `json:"id"`
+	Author    string              `json:"author"`
+	CreatedAt string              `json:"createdAt"`
+	Body      string              `json:"body"`
+	IsAnswer  bool                `json:"isAnswer,omitempty"`
+	Replies   []DiscussionComment `json:"replies,omitempty"`
+}
+
+// DiscussionFrontmatter is the metadata of a discussion file. Answered is
+// informative: an answer is marked on a comment, on GitHub.
+type DiscussionFrontmatter struct {
+	Title    string `yaml:"title,omitempty" json:"title,omitempty"`
+	Category string `yaml:"category,omitempty" json:"category,omitempty"`
+	Answered bool   `yaml:"answered,omitempty" json:"answered,omitempty"`
+}
+
+// Frontmatter returns the local file metadata for the discussion.
+func (d *DiscussionData) Frontmatter() DiscussionFrontmatter {
+	return DiscussionFrontmatter{
+		Title:    d.Title,
+		Category: d.Category,
+		Answered: d.Answered,
+	}
+}
+
+// Snapshot returns the discussion content as it would be written to a local
+// file, without the comment thread.
+func (d *DiscussionData) Snapshot() DiscussionSnapshot {
+	return DiscussionSnapshot{Frontmatter: d.Frontmatter(), Body: d.Body}
+}
+
+// DiscussionSnapshot is the synced content of a discussion. The comment
+// thread is read-only and not part of it.
+type DiscussionSnapshot struct {
+	Frontmatter DiscussionFrontmatter `json:"frontmatter"`
+	Body        string                `json:"body"`
+}
+
+// DiscussionEdit describes the changes discussion push applies to a remote
+// discussion. An empty Category leaves the category alone.
+type DiscussionEdit struct {
+	Title    string
+	Body     string
+	Category string
+}
+
+// NewDiscussionEdit builds the edit that makes the remote discussion match
+// the local file.
+func NewDiscussionEdit(fm DiscussionFrontmatter, body string, remote *DiscussionData) DiscussionEdit {
+	edit := DiscussionEdit{
+		Title: fm.Title,
+		Body:  body,
+	}
+	if fm.Category != "" && fm.Category != remote.Category {
+		edit.Category = fm.Category
+	}
+	return edit
+}
+
+// DiscussionDraft is the content of a discussion to create.
+type DiscussionDraft struct {
+	Title    string
+	Body     string
+	Category string
+}
+
+// DiscussionListItem is one row of ListDiscussions.
+type DiscussionListItem struct {
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	Category  string `json:"category"`
+	Answered  bool   `json:"isAnswered"`
+	Author    string `json:"author"`
+	URL       string `json:"url"`
+	UpdatedAt string `json:"updatedAt"`
+}
\ No newline at end of file
@@ -0,0 +1,89 @@
+package model
+
+import "strings"
+
+// IssueDraft is the content of an issue to create.
+type IssueDraft struct {
+	Title     string
+	Body      string
+	Labels    []string
+	Assignees []string
+	Milestone string
+}
+
+// IssueEdit describes the changes push applies to a remote issue.
+type IssueEdit struct {
+	Title           string
+	Body            string
+	AddLabels       []string
+	RemoveLabels    []string
+	AddAssignees    []string
+	RemoveAssignees []string
+	Milestone       string
+	RemoveMilestone bool
+	State           string
+}
+
+// NewIssueEdit builds the edit that makes the remote issue match the local file.
+// Labels and assignees are reconciled as set differences, so only entries the
+// file adds or drops are touched. A missing key or a null list in the file
+// leaves the remote value alone, while "labels: []" removes every label and
+// an empty "milestone:" removes the milestone.
+func NewIssueEdit(fm Frontmatter, body string, remote *IssueData) IssueEdit {
+	edit := IssueEdit{
+		Title: fm.Title,
+		Body:  body,
+	}
+	
+	current := remote.Frontmatter()
+	
+	if fm.Labels != nil {
+		edit.AddLabels, edit.RemoveLabels = diffSets(fm.Labels, current.Labels)
+	}
+	
+	if fm.Assignees != nil {
+		edit.AddAssignees, edit.RemoveAssignees = diffSets(fm.Assignees, current.Assignees)
+	}
+	
+	if fm.Milestone != "" && fm.Milestone != current.Milestone {
+		edit.Milestone = fm.Milestone
+	}
+	if fm.NoMilestone && current.Milestone != "" {
+		edit.RemoveMilestone = true
+	}
+	
+	if state := strings.ToLower(fm.State); state != "" && state != current.State {
+		edit.State = state
+	}
+	
+	return edit
+}
+
+// ValidState reports whether s is a state value accepted in frontmatter.
+func ValidState(s string) bool {
+	switch strings.ToLower(s) {
+	case "", "open", "closed":
+		return true
+	}
+	return false
+}
+
+func diffSets(want, have []string) (add, remove []string) {
+	haveSet := make(map[string]bool, len(have))
+	for _, v := range have {
+		haveSet[v] = true
+	}
+	wantSet := make(map[string]bool, len(want))
+	for _, v := range want {
+		if !haveSet[v] && !wantSet[v] {
+			add = append(add, v)
+		}
+		wantSet[v] = true
+	}
+	for _, v := range have {
+		if !wantSet[v] {
+			remove = append(remove, v)
+		}
+	}
+	return add, remove
+}
\ No newline at end of file
@@ -0,0 +1,103 @@
+package model
+
+// PRData is a pull request with the fields of `gh pr view --json`. State is
+// upper case: OPEN, CLOSED or MERGED.
+type PRData struct {
+	Number         int        `json:"number"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	State          string     `json:"state"`
+	IsDraft        bool       `json:"isDraft"`
+	BaseRefName    string     `json:"baseRefName"`
+	HeadRefName    string     `json:"headRefName"`
+	Labels         []Label    `json:"labels"`
+	ReviewRequests []Reviewer `json:"reviewRequests"`
+	UpdatedAt      string     `json:"updatedAt"`
+}
+
+// Reviewer is a requested reviewer: a user's login, or "org/slug" for a team.
+type Reviewer struct {
+	Login string `json:"login"`
+}
+
+// PRFrontmatter is the metadata of a pull request file. Head is informative:
+// the branch of a pull request cannot be changed.
+type PRFrontmatter struct {
+	Title     string   `yaml:"title,omitempty" json:"title,omitempty"`
+	Base      string   `yaml:"base,omitempty" json:"base,omitempty"`
+	Head      string   `yaml:"head,omitempty" json:"head,omitempty"`
+	Draft     bool     `yaml:"draft,omitempty" json:"draft,omitempty"`
+	Labels    []string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Reviewers []string `yaml:"reviewers,omitempty" json:"reviewers,omitempty"`
+}
+
+// Frontmatter returns the local file metadata for the pull request.
+func (p *PRData) Frontmatter() PRFrontmatter {
+	fm := PRFrontmatter{
+		Title: p.Title,
+		Base:  p.BaseRefName,
+		Head:  p.HeadRefName,
+		Draft: p.IsDraft,
+	}
+	for _, l := range p.Labels {
+		fm.Labels = append(fm.Labels, l.Name)
+	}
+	for _, r := range p.ReviewRequests {
+		fm.Reviewers = append(fm.Reviewers, r.Login)
+	}
+	return fm
+}
+
+// Snapshot returns the pull request content as it would be written to a
+// local file.
+func (p *PRData) Snapshot() PRSnapshot {
+	return PRSnapshot{Frontmatter: p.Frontmatter(), Body: p.Body}
+}
+
+// PRSnapshot is the synced content of a pull request.
+type PRSnapshot struct {
+	Frontmatter PRFrontmatter `json:"frontmatter"`
+	Body        string        `json:"body"`
+}
+
+// PREdit describes the changes pr push applies to a remote pull request.
+// Draft is nil unless the draft state changes.
+type PREdit struct {
+	Title           string
+	Body            string
+	Base            string
+	AddLabels       []string
+	RemoveLabels    []string
+	AddReviewers    []string
+	RemoveReviewers []string
+	Draft           *bool
+}
+
+// NewPREdit builds the edit that makes the remote pull request match the
+// local file. Like NewIssueEdit, a missing list or base leaves the remote
+// value alone.
+func NewPREdit(fm PRFrontmatter, body string, remote *PRData) PREdit {
+	edit := PREdit{
+		Title: fm.Title,
+		Body:  body,
+	}
+	
+	if fm.Base != "" && fm.Base != remote.BaseRefName {
+		edit.Base = fm.Base
+	}
+	
+	current := remote.Frontmatter()
+	if fm.Labels != nil {
+		edit.AddLabels, edit.RemoveLabels = diffSets(fm.Labels, current.Labels)
+	}
+	if fm.Reviewers != nil {
+		edit.AddReviewers, edit.RemoveReviewers = diffSets(fm.Reviewers, current.Reviewers)
+	}
+	
+	if fm.Draft != remote.IsDraft {
+		draft := fm.Draft
+		edit.Draft = &draft
+	}
+	
+	return edit
+}
\ No newline at end of file
@@ -4,19 +4,30 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 )
 
+// Frontmatter is the metadata of an issue file. An empty scalar or a nil
+// list means the key is missing and leaves the remote value alone; an empty
+// non-nil list means "none". NoMilestone records a milestone key that is
+// present but empty, which clears the milestone.
 type Frontmatter struct {
-	Title string `yaml:"title,omitempty"`
+	Title       string   `yaml:"title,omitempty" json:"title,omitempty"`
+	Labels      []string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Assignees   []string `yaml:"assignees,omitempty" json:"assignees,omitempty"`
+	Milestone   string   `yaml:"milestone,omitempty" json:"milestone,omitempty"`
+	State       string   `yaml:"state,omitempty" json:"state,omitempty"`
+	NoMilestone bool     `yaml:"-" json:"-"`
 }
 
 type ErrorType int
 
 const (
-	ExitSuccess ErrorType = 0
-	ExitUsage   ErrorType = 1
-	ExitEnv     ErrorType = 2
-	ExitIO      ErrorType = 3
+	ExitSuccess  ErrorType = 0
+	ExitUsage    ErrorType = 1
+	ExitEnv      ErrorType = 2
+	ExitIO       ErrorType = 3
+	ExitConflict ErrorType = 4
 )
 
 type ExitError struct {
@@ -27,11 +38,18 @@ type ExitError struct {
 
 func (e *ExitError) Error() string {
 	if e.Err != nil {
+		if e.Message == "" {
+			return e.Err.Error()
+		}
 		return fmt.Sprintf("%s: %v", e.Message, e.Err)
 	}
 	return e.Message
 }
 
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
 func NewUsageError(msg string) *ExitError {
 	return &ExitError{Code: ExitUsage, Message: msg}
 }
@@ -44,21 +62,97 @@ func NewIOError(msg string, err error) *ExitError {
 	return &ExitError{Code: ExitIO, Message: msg, Err: err}
 }
 
+func NewConflictError(msg string) *ExitError {
+	return &ExitError{Code: ExitConflict, Message: msg}
+}
+
 var numericRegex = regexp.MustCompile(`^[0-9]+$`)
 
 func IsNumeric(s string) bool {
 	return numericRegex.MatchString(s)
 }
 
-type IssueData struct {
+type Label struct {
+	Name string `json:"name"`
+}
+
+type User struct {
+	Login string `json:"login"`
+}
+
+type Milestone struct {
 	Title string `json:"title"`
-	Body  string `json:"body"`
 }
 
+type IssueData struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"`
+	Labels    []Label    `json:"labels"`
+	Assignees []User     `json:"assignees"`
+	Milestone *Milestone `json:"milestone"`
+	UpdatedAt string     `json:"updatedAt"`
+}
+
+// Frontmatter returns the local file metadata for the issue.
+// State is lowercased so files read naturally ("open", "closed").
+func (i *IssueData) Frontmatter() Frontmatter {
+	fm := Frontmatter{
+		Title: i.Title,
+		State: strings.ToLower(i.State),
+	}
+	for _, l := range i.Labels {
+		fm.Labels = append(fm.Labels, l.Name)
+	}
+	for _, a := range i.Assignees {
+		fm.Assignees = append(fm.Assignees, a.Login)
+	}
+	if i.Milestone != nil {
+		fm.Milestone = i.Milestone.Title
+	}
+	return fm
+}
+
+// Snapshot returns the issue content as it would be written to a local file.
+func (i *IssueData) Snapshot() Snapshot {
+	return Snapshot{Frontmatter: i.Frontmatter(), Body: i.Body}
+}
+
+// Snapshot is the synced content of an issue: its frontmatter and body.
+type Snapshot struct {
+	Frontmatter Frontmatter `json:"frontmatter"`
+	Body        string      `json:"body"`
+}
+
+// Comment is an issue comment. ID is the REST ID used to edit it; Mine
+// reports whether the authenticated user wrote it and may therefore edit it.
+type Comment struct {
+	ID        int64  `json:"id"`
+	Author    string `json:"author"`
+	CreatedAt string `json:"createdAt"`
+	Body      string `json:"body"`
+	Mine      bool   `json:"mine,omitempty"`
+}
+
+// CommentSection is the comments part of an issue file: the existing comments
+// as they appear in the file and the text of a new comment to post on push.
+type CommentSection struct {
+	Comments []Comment
+	New      string
+}
+
+// IssueListItem is one row of ListIssues, with the fields of
+// `gh issue list --json`. State is upper case.
 type IssueListItem struct {
-	Number int    `json:"number"`
-	Title  string `json:"title"`
-	URL    string `json:"url"`
+	Number    int     `json:"number"`
+	Title     string  `json:"title"`
+	URL       string  `json:"url"`
+	State     string  `json:"state"`
+	Labels    []Label `json:"labels"`
+	Assignees []User  `json:"assignees"`
+	Author    User    `json:"author"`
+	UpdatedAt string  `json:"updatedAt"`
 }
 
 var (
@@ -0,0 +1,336 @@
+// Package search is the offline full-text search over the local issue files.
+// It keeps an inverted index in the issues directory's .ghi folder and
+// brings it up to date with the files on every search, re-reading only the
+// files whose size or modification time changed.
+package search
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/nomnel/ghi/internal/filefmt"
+)
+
+// version is bumped whenever the index format or tokenizer changes, which
+// makes the next search rebuild the index.
+const version = 1
+
+// titleWeight counts every title word as this many body words.
+const titleWeight = 3
+
+// BM25 parameters.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Document is an indexed issue file.
+type Document struct {
+	Number    int
+	Path      string
+	ModTime   int64
+	Size      int64
+	Title     string
+	State     string
+	Labels    []string
+	Assignees []string
+	Milestone string
+	// Length is the number of words, with title words weighted.
+	Length int
+	// Terms lists the distinct words, so the postings can be dropped when
+	// the file changes.
+	Terms []string
+	// Invalid is set for files that could not be parsed; they never match.
+	Invalid bool
+}
+
+// Posting is the number of times a word occurs in an issue, with title
+// words weighted.
+type Posting struct {
+	Number int
+	Freq   int
+}
+
+// Index is the inverted index over one issues directory.
+type Index struct {
+	Version  int
+	Docs     map[int]*Document
+	Postings map[string][]Posting
+	// TotalLength is the sum of all document lengths, for BM25.
+	TotalLength int
+	
+	path  string
+	dirty bool
+}
+
+// Path returns where the index of an issues directory is stored.
+func Path(issuesDir string) string {
+	return filepath.Join(issuesDir, ".ghi", "search", "index.gob")
+}
+
+// Open loads the index stored at path. A missing, unreadable or outdated
+// index is replaced by an empty one, which Update then fills.
+func Open(path string) *Index {
+	ix := &Index{}
+	if raw, err := os.ReadFile(path); err == nil {
+		if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(ix); err != nil || ix.Version != version {
+			ix = &Index{}
+		}
+	}
+	if ix.Docs == nil {
+		ix.Version = version
+		ix.Docs = map[int]*Document{}
+		ix.Postings = map[string][]Posting{}
+		ix.dirty = true
+	}
+	ix.path = path
+	return ix
+}
+
+// Update brings the index in line with files, which maps issue numbers to
+// their paths. Files that did not change since they were indexed are not
+// read. It returns how many files were (re)indexed or removed.
+func (ix *Index) Update(files map[string]string) (int, error) {
+	changed := 0
+	seen := map[int]bool{}
+	
+	for key, path := range files {
+		number, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		seen[number] = true
+		
+		info, err := os.Stat(path)
+		if err != nil {
+			return changed, err
+		}
+		if doc := ix.Docs[number]; doc != nil && doc.Path == path && doc.ModTime == info.ModTime().UnixNano() && doc.Size == info.Size() {
+			continue
+		}
+		
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return changed, err
+		}
+		ix.remove(number)
+		ix.add(number, path, info, raw)
+		changed++
+	}
+	
+	for number := range ix.Docs {
+		if !seen[number] {
+			ix.remove(number)
+			changed++
+		}
+	}
+	
+	if changed > 0 {
+		ix.dirty = true
+	}
+	return changed, nil
+}
+
+func (ix *Index) add(number int, path string, info os.FileInfo, raw []byte) {
+	doc := &Document{Number: number, Path: path, ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+	ix.Docs[number] = doc
+	
+	fm, body, err := filefmt.DecodeMarkdown(raw)
+	if err != nil {
+		doc.Invalid = true
+		return
+	}
+	doc.Title = fm.Title
+	doc.State = fm.State
+	doc.Labels = fm.Labels
+	doc.Assignees = fm.Assignees
+	doc.Milestone = fm.Milestone
+	
+	freq := map[string]int{}
+	for _, w := range tokenize(fm.Title) {
+		freq[w] += titleWeight
+		doc.Length += titleWeight
+	}
+	for _, w := range tokenize(string(body)) {
+		freq[w]++
+		doc.Length++
+	}
+	
+	for w, n := range freq {
+		doc.Terms = append(doc.Terms, w)
+		list := ix.Postings[w]
+		i := sort.Search(len(list), func(i int) bool { return list[i].Number >= number })
+		ix.Postings[w] = slices.Insert(list, i, Posting{Number: number, Freq: n})
+	}
+	sort.Strings(doc.Terms)
+	ix.TotalLength += doc.Length
+}
+
+func (ix *Index) remove(number int) {
+	doc := ix.Docs[number]
+	if doc == nil {
+		return
+	}
+	for _, w := range doc.Terms {
+		list := ix.Postings[w]
+		i := sort.Search(len(list), func(i int) bool { return list[i].Number >= number })
+		if i < len(list) && list[i].Number == number {
+			list = slices.Delete(list, i, i+1)
+		}
+		if len(list) == 0 {
+			delete(ix.Postings, w)
+		} else {
+			ix.Postings[w] = list
+		}
+	}
+	ix.TotalLength -= doc.Length
+	delete(ix.Docs, number)
+}
+
+// Save writes the index back if Update changed it.
+func (ix *Index) Save() error {
+	if !ix.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(ix); err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := filefmt.AtomicWriteFile(ix.path, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	ix.dirty = false
+	return nil
+}
+
+// Result is one matching issue.
+type Result struct {
+	*Document
+	Score float64
+}
+
+// Search returns the issues matching q, best first. Free-text words are
+// ranked with BM25; a query of filters only lists the newest issues first.
+func (ix *Index) Search(q Query) []Result {
+	var set docSet
+	if q.root == nil {
+		set = (&notNode{}).eval(ix)
+	} else {
+		set = q.root.eval(ix)
+	}
+	
+	scores := map[int]float64{}
+	n := float64(len(ix.Docs))
+	avg := 1.0
+	if len(ix.Docs) > 0 && ix.TotalLength > 0 {
+		avg = float64(ix.TotalLength) / n
+	}
+	for _, w := range uniq(q.Terms()) {
+		list := ix.Postings[w]
+		idf := math.Log(1 + (n-float64(len(list))+0.5)/(float64(len(list))+0.5))
+		for _, p := range list {
+			if _, ok := set[p.Number]; !ok {
+				continue
+			}
+			tf := float64(p.Freq)
+			length := float64(ix.Docs[p.Number].Length)
+			scores[p.Number] += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*length/avg))
+		}
+	}
+	
+	results := make([]Result, 0, len(set))
+	for number := range set {
+		results = append(results, Result{Document: ix.Docs[number], Score: scores[number]})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Number > results[j].Number
+	})
+	return results
+}
+
+// containsPhrase reads doc's file to check that the words occur in order in
+// its title or body.
+func (ix *Index) containsPhrase(doc *Document, words []string) bool {
+	if doc == nil {
+		return false
+	}
+	if hasPhrase(tokenize(doc.Title), words) {
+		return true
+	}
+	_, body, err := ReadFile(doc.Path)
+	return err == nil && hasPhrase(tokenize(body), words)
+}
+
+// ReadFile returns the title and body of an issue file.
+func ReadFile(path string) (string, string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	fm, body, err := filefmt.DecodeMarkdown(raw)
+	if err != nil {
+		return "", "", err
+	}
+	return fm.Title, string(body), nil
+}
+
+// tokenize splits text into lower-case words of letters and digits.
+func tokenize(text string) []string {
+	var words []string
+	for _, s := range spans(text) {
+		words = append(words, s.word)
+	}
+	return words
+}
+
+// span is a word and its byte offsets in the text it came from.
+type span struct {
+	start, end int
+	word       string
+}
+
+func spans(text string) []span {
+	var out []span
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			out = append(out, span{start, i, strings.ToLower(text[start:i])})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		out = append(out, span{start, len(text), strings.ToLower(text[start:])})
+	}
+	return out
+}
+
+func uniq(words []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
\ No newline at end of file
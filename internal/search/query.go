@@ -0,0 +1,380 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Query is a parsed search query. Terms are combined with AND unless joined
+// by OR; NOT (or a leading "-") negates a term, and parentheses group:
+//
+//	login crash label:bug -state:closed
+//	title:"dark mode" OR (assignee:alice AND NOT label:wontfix)
+//
+// Free text matches the title and body; "quoted text" must appear as a
+// phrase. label:, assignee:, state: and milestone: match the frontmatter
+// exactly (ignoring case) and title: matches words or a phrase in the title.
+type Query struct {
+	root node
+}
+
+// Fields that can prefix a term.
+var fields = map[string]bool{"label": true, "assignee": true, "state": true, "milestone": true, "title": true}
+
+type node interface {
+	eval(ix *Index) docSet
+	// terms appends the free-text terms that rank matches.
+	terms(dst []string) []string
+}
+
+type docSet map[int]struct{}
+
+type andNode struct{ left, right node }
+type orNode struct{ left, right node }
+type notNode struct{ child node }
+
+// textNode is free text: one word, or a phrase when it has several.
+type textNode struct{ words []string }
+
+// fieldNode matches a frontmatter field.
+type fieldNode struct {
+	field string
+	value string
+}
+
+// Parse parses a query. An empty query matches every issue.
+func Parse(s string) (Query, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return Query{}, err
+	}
+	
+	p := &parser{tokens: tokens}
+	if len(tokens) == 0 {
+		return Query{}, nil
+	}
+	root, err := p.or()
+	if err != nil {
+		return Query{}, err
+	}
+	if p.pos < len(p.tokens) {
+		return Query{}, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return Query{root: root}, nil
+}
+
+// Terms returns the free-text words of the query that are not negated.
+func (q Query) Terms() []string {
+	if q.root == nil {
+		return nil
+	}
+	return q.root.terms(nil)
+}
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokPhrase
+	tokField
+	tokAnd
+	tokOr
+	tokNot
+	tokOpen
+	tokClose
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	field string
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, token{kind: tokOpen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokClose, text: ")"})
+			i++
+		case r == '-' && i+1 < len(s) && !unicode.IsSpace(rune(s[i+1])):
+			tokens = append(tokens, token{kind: tokNot, text: "-"})
+			i++
+		case r == '"':
+			text, n, err := quoted(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokPhrase, text: text})
+			i += n
+		default:
+			start := i
+			for i < len(s) {
+				r, size := utf8.DecodeRuneInString(s[i:])
+				if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' {
+					break
+				}
+				i += size
+			}
+			word := s[start:i]
+			
+			if name, value, ok := strings.Cut(word, ":"); ok && fields[strings.ToLower(name)] {
+				if value == "" && i < len(s) && s[i] == '"' {
+					text, n, err := quoted(s[i:])
+					if err != nil {
+						return nil, err
+					}
+					value = text
+					i += n
+				}
+				if value == "" {
+					return nil, fmt.Errorf("missing value for %s:", name)
+				}
+				tokens = append(tokens, token{kind: tokField, field: strings.ToLower(name), text: value})
+				continue
+			}
+			
+			switch word {
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, text: word})
+			case "OR":
+				tokens = append(tokens, token{kind: tokOr, text: word})
+			case "NOT":
+				tokens = append(tokens, token{kind: tokNot, text: word})
+			default:
+				tokens = append(tokens, token{kind: tokWord, text: word})
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// quoted returns the text of the quoted string s starts with and its length
+// including the quotes.
+func quoted(s string) (string, int, error) {
+	end := strings.IndexByte(s[1:], '"')
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated quote in %s", s)
+	}
+	return s[1 : end+1], end + 2, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() *token {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t != nil && t.kind == tokOr; t = p.peek() {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t != nil && t.kind != tokOr && t.kind != tokClose; t = p.peek() {
+		if t.kind == tokAnd {
+			p.pos++
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (node, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	p.pos++
+	
+	switch t.kind {
+	case tokNot:
+		child, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{child}, nil
+	case tokOpen:
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t == nil || t.kind != tokClose {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return n, nil
+	case tokField:
+		return &fieldNode{field: t.field, value: t.text}, nil
+	case tokWord, tokPhrase:
+		words := tokenize(t.text)
+		if len(words) == 0 {
+			// Punctuation alone matches everything rather than nothing.
+			return &notNode{}, nil
+		}
+		return &textNode{words: words}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+func (n *andNode) eval(ix *Index) docSet {
+	left := n.left.eval(ix)
+	right := n.right.eval(ix)
+	set := docSet{}
+	for d := range left {
+		if _, ok := right[d]; ok {
+			set[d] = struct{}{}
+		}
+	}
+	return set
+}
+
+func (n *orNode) eval(ix *Index) docSet {
+	set := n.left.eval(ix)
+	for d := range n.right.eval(ix) {
+		set[d] = struct{}{}
+	}
+	return set
+}
+
+func (n *notNode) eval(ix *Index) docSet {
+	var excluded docSet
+	if n.child != nil {
+		excluded = n.child.eval(ix)
+	}
+	set := docSet{}
+	for number, doc := range ix.Docs {
+		if _, ok := excluded[number]; !ok && !doc.Invalid {
+			set[number] = struct{}{}
+		}
+	}
+	return set
+}
+
+func (n *textNode) eval(ix *Index) docSet {
+	set := docSet{}
+	for _, p := range ix.Postings[n.words[0]] {
+		set[p.Number] = struct{}{}
+	}
+	for _, word := range n.words[1:] {
+		next := docSet{}
+		for _, p := range ix.Postings[word] {
+			if _, ok := set[p.Number]; ok {
+				next[p.Number] = struct{}{}
+			}
+		}
+		set = next
+	}
+	
+	// Phrases need the words in order, which only the text can tell.
+	if len(n.words) > 1 {
+		for number := range set {
+			if !ix.containsPhrase(ix.Docs[number], n.words) {
+				delete(set, number)
+			}
+		}
+	}
+	return set
+}
+
+func (n *fieldNode) eval(ix *Index) docSet {
+	set := docSet{}
+	for number, doc := range ix.Docs {
+		if !doc.Invalid && n.match(doc) {
+			set[number] = struct{}{}
+		}
+	}
+	return set
+}
+
+func (n *fieldNode) match(doc *Document) bool {
+	switch n.field {
+	case "label":
+		return containsFold(doc.Labels, n.value)
+	case "assignee":
+		return containsFold(doc.Assignees, strings.TrimPrefix(n.value, "@"))
+	case "state":
+		return strings.EqualFold(doc.State, n.value)
+	case "milestone":
+		return strings.EqualFold(doc.Milestone, n.value)
+	case "title":
+		return hasPhrase(tokenize(doc.Title), tokenize(n.value))
+	}
+	return false
+}
+
+func (n *andNode) terms(dst []string) []string {
+	return n.right.terms(n.left.terms(dst))
+}
+
+func (n *orNode) terms(dst []string) []string {
+	return n.right.terms(n.left.terms(dst))
+}
+
+func (n *notNode) terms(dst []string) []string { return dst }
+
+func (n *textNode) terms(dst []string) []string { return append(dst, n.words...) }
+
+func (n *fieldNode) terms(dst []string) []string { return dst }
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPhrase reports whether phrase occurs as consecutive words in words.
+func hasPhrase(words []string, phrase []string) bool {
+	if len(phrase) == 0 {
+		return true
+	}
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, w := range phrase {
+			if words[i+j] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
\ No newline at end of file
@@ -0,0 +1,161 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testIssue struct {
+	number int
+	header string
+	body   string
+}
+
+var testIssues = []testIssue{
+	{1, "title: Login page crashes\nlabels: [bug]\nassignees: [alice]\nstate: open", "Clicking login crashes the app.\n"},
+	{2, "title: Add dark mode\nlabels: [enhancement, ui]\nstate: open", "Users want a dark mode for the login screen.\n"},
+	{3, "title: Crash on startup\nlabels: [bug]\nassignees: [bob]\nstate: closed", "The app crashes on startup when offline.\n"},
+	{4, "title: Docs\nstate: open", "Mode of operation is dark magic.\n"},
+}
+
+// writeIssues writes the issues to dir and returns the file map Update takes.
+func writeIssues(t *testing.T, dir string, issues []testIssue) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	for _, issue := range issues {
+		path := filepath.Join(dir, strconv.Itoa(issue.number)+".md")
+		if err := os.WriteFile(path, []byte("---\n"+issue.header+"\n---\n"+issue.body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files[strconv.Itoa(issue.number)] = path
+	}
+	return files
+}
+
+func numbers(results []Result) []int {
+	var out []int
+	for _, r := range results {
+		out = append(out, r.Number)
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	ix := Open(Path(dir))
+	if _, err := ix.Update(writeIssues(t, dir, testIssues)); err != nil {
+		t.Fatal(err)
+	}
+	
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"crash", []int{3}},
+		{"crashes", []int{1, 3}},
+		{"label:bug", []int{3, 1}},
+		{"label:BUG state:open", []int{1}},
+		{"crashes -state:closed", []int{1}},
+		{"crashes AND NOT label:bug", nil},
+		{"assignee:@bob OR assignee:alice", []int{3, 1}},
+		{`"dark mode"`, []int{2}},
+		{"dark mode", []int{2, 4}},
+		{`title:"dark mode"`, []int{2}},
+		{"title:crash", []int{3}},
+		{"(label:ui OR label:bug) login", []int{1, 2}},
+		{"milestone:v1", nil},
+		{"", []int{4, 3, 2, 1}},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.query, err)
+		}
+		if got := numbers(ix.Search(q)); !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchRanksTitleMatches(t *testing.T) {
+	dir := t.TempDir()
+	ix := Open(Path(dir))
+	ix.Update(writeIssues(t, dir, testIssues))
+	
+	q, _ := Parse("login")
+	results := ix.Search(q)
+	// #1 has login in its title, #2 only in the body.
+	if got := numbers(results); !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("results = %v, want [1 2]", got)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("scores %v, %v not descending", results[0].Score, results[1].Score)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, q := range []string{`"open`, "(crash", "crash)", "label:", "crash OR", "NOT"} {
+		if _, err := Parse(q); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", q)
+		}
+	}
+}
+
+func TestUpdateIsIncremental(t *testing.T) {
+	dir := t.TempDir()
+	files := writeIssues(t, dir, testIssues)
+	ix := Open(Path(dir))
+	if n, _ := ix.Update(files); n != len(testIssues) {
+		t.Fatalf("first update indexed %d files, want %d", n, len(testIssues))
+	}
+	if err := ix.Save(); err != nil {
+		t.Fatal(err)
+	}
+	
+	// A reopened index only reads what changed.
+	ix = Open(Path(dir))
+	if n, _ := ix.Update(files); n != 0 {
+		t.Errorf("unchanged files reindexed: %d", n)
+	}
+	
+	writeIssues(t, dir, []testIssue{{2, "title: Add light mode\nstate: open", "Bright colours.\n"}})
+	later := time.Now().Add(time.Second)
+	os.Chtimes(files["2"], later, later)
+	os.Remove(files["4"])
+	delete(files, "4")
+	
+	if n, _ := ix.Update(files); n != 2 {
+		t.Errorf("update touched %d files, want 2", n)
+	}
+	for query, want := range map[string][]int{"dark": nil, "light": {2}, "magic": nil, "crashes": {1, 3}} {
+		q, _ := Parse(query)
+		if got := numbers(ix.Search(q)); !slices.Equal(got, want) {
+			t.Errorf("Search(%q) = %v, want %v", query, got, want)
+		}
+	}
+	if _, ok := ix.Postings["dark"]; ok {
+		t.Error("postings of removed words were kept")
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	body := "Some intro text.\n\nThe  login\nbutton fails when the network is down. Unrelated text follows here and goes on and on for a while."
+	
+	s := MakeSnippet(body, []string{"login", "network"}, 60)
+	if got := s.Mark("[", "]"); got != "…text. The [login] button fails when the [network] is down.…" {
+		t.Errorf("snippet = %q", got)
+	}
+	
+	s = MakeSnippet("short body", []string{"missing"}, 60)
+	if s.Text != "short body" || len(s.Highlights) != 0 {
+		t.Errorf("snippet without match = %+v", s)
+	}
+	
+	if got := Highlight("Login page crashes", []string{"login"}).Mark("*", "*"); got != "*Login* page crashes" {
+		t.Errorf("highlighted title = %q", got)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,155 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Snippet is an excerpt of an issue with the query words marked. Highlights
+// are byte ranges into Text.
+type Snippet struct {
+	Text       string   `json:"text"`
+	Highlights [][2]int `json:"highlights,omitempty"`
+}
+
+// Mark returns the text with every highlight wrapped in open and close.
+func (s Snippet) Mark(open, close string) string {
+	var sb strings.Builder
+	last := 0
+	for _, h := range s.Highlights {
+		sb.WriteString(s.Text[last:h[0]])
+		sb.WriteString(open)
+		sb.WriteString(s.Text[h[0]:h[1]])
+		sb.WriteString(close)
+		last = h[1]
+	}
+	sb.WriteString(s.Text[last:])
+	return sb.String()
+}
+
+// Highlight marks the query words in a short text such as a title, which is
+// kept whole.
+func Highlight(text string, words []string) Snippet {
+	return excerpt(text, 0, len(text), wordSet(words))
+}
+
+// MakeSnippet picks the part of a body, at most about width bytes long,
+// that holds the most distinct query words, and marks them. Runs of white
+// space are collapsed so the snippet fits on one line.
+func MakeSnippet(body string, words []string, width int) Snippet {
+	want := wordSet(words)
+	all := spans(body)
+	
+	best, bestCount := -1, 0
+	for i, s := range all {
+		if !want[s.word] {
+			continue
+		}
+		found := map[string]bool{}
+		for _, t := range all[i:] {
+			if t.start >= s.start+width {
+				break
+			}
+			if want[t.word] {
+				found[t.word] = true
+			}
+		}
+		if len(found) > bestCount {
+			best, bestCount = i, len(found)
+		}
+	}
+	
+	start := 0
+	if best >= 0 && all[best].start > width/4 {
+		// Show a little context before the first match, from a word start.
+		from := all[best].start - width/4
+		start = all[best].start
+		for i := best - 1; i >= 0 && all[i].start >= from; i-- {
+			start = all[i].start
+		}
+	}
+	end := start + width
+	if end >= len(body) {
+		end = len(body)
+	} else {
+		// End on a word boundary.
+		for end > start && !isBoundary(body, end) {
+			end--
+		}
+	}
+	
+	s := excerpt(body, start, end, want)
+	if start > 0 {
+		s = shift(s, "…")
+	}
+	if end < len(body) && strings.TrimSpace(body[end:]) != "" {
+		s.Text += "…"
+	}
+	return s
+}
+
+// excerpt copies text[start:end] with white space collapsed and records
+// where the wanted words ended up.
+func excerpt(text string, start, end int, want map[string]bool) Snippet {
+	var s Snippet
+	var sb strings.Builder
+	marks := map[int]int{}
+	for _, sp := range spans(text[start:end]) {
+		if want[sp.word] {
+			marks[sp.start] = sp.end
+		}
+	}
+	
+	space := false
+	part := text[start:end]
+	for i := 0; i < len(part); {
+		if e, ok := marks[i]; ok {
+			if space && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			space = false
+			from := sb.Len()
+			sb.WriteString(part[i:e])
+			s.Highlights = append(s.Highlights, [2]int{from, sb.Len()})
+			i = e
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(part[i:])
+		if unicode.IsSpace(r) {
+			space = true
+		} else {
+			if space && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			space = false
+			sb.WriteString(part[i : i+size])
+		}
+		i += size
+	}
+	s.Text = sb.String()
+	return s
+}
+
+// shift prepends prefix to the snippet's text.
+func shift(s Snippet, prefix string) Snippet {
+	s.Text = prefix + s.Text
+	for i := range s.Highlights {
+		s.Highlights[i][0] += len(prefix)
+		s.Highlights[i][1] += len(prefix)
+	}
+	return s
+}
+
+func isBoundary(text string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return utf8.RuneStart(text[i]) && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func wordSet(words []string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
\ No newline at end of file
@@ -0,0 +1,280 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nomnel/ghi/internal/filefmt"
+	"github.com/nomnel/ghi/internal/model"
+)
+
+// Dir is the hidden directory ghi keeps its bookkeeping in, relative to the
+// issues directory.
+const Dir = ".ghi"
+
+// Base is the remote version of an issue as last seen by pull or push. It is
+// the common ancestor for three-way merges between local and remote edits.
+// Comments is only recorded for files that sync comments, which
+// CommentsSynced marks even when the issue has none.
+type Base struct {
+	Number    string `json:"number"`
+	UpdatedAt string `json:"updatedAt"`
+	BodyHash  string `json:"bodyHash"`
+	model.Snapshot
+	Comments       []model.Comment `json:"comments,omitempty"`
+	CommentsSynced bool            `json:"commentsSynced,omitempty"`
+}
+
+// SyncsComments reports whether the issue's file ends with a comments
+// section. Only then is the section split off the body; without a base the
+// whole file after the frontmatter is the body.
+func (b *Base) SyncsComments() bool {
+	return b != nil && (b.CommentsSynced || len(b.Comments) > 0)
+}
+
+// NewBase records the given snapshot as the base for an issue.
+func NewBase(issueNumber string, updatedAt string, snap model.Snapshot) *Base {
+	return &Base{
+		Number:    issueNumber,
+		UpdatedAt: updatedAt,
+		BodyHash:  HashBody(snap.Body),
+		Snapshot:  snap,
+	}
+}
+
+// PRBase is the remote version of a pull request as last seen by pr pull or
+// pr push: the Base of a file in the pull requests directory.
+type PRBase struct {
+	Number    string `json:"number"`
+	UpdatedAt string `json:"updatedAt"`
+	model.PRSnapshot
+}
+
+// DiscussionBase is the remote version of a discussion as last seen by
+// discussion pull or push.
+type DiscussionBase struct {
+	Number    string `json:"number"`
+	UpdatedAt string `json:"updatedAt"`
+	model.DiscussionSnapshot
+}
+
+// Store reads and writes base snapshots under <issuesDir>/.ghi/base, draft
+// journals under <issuesDir>/.ghi/drafts and pull watermarks in
+// <issuesDir>/.ghi/watermark.json.
+type Store struct {
+	dir        string
+	drafts     string
+	watermarks string
+}
+
+func Open(issuesDir string) *Store {
+	return &Store{
+		dir:        filepath.Join(issuesDir, Dir, "base"),
+		drafts:     filepath.Join(issuesDir, Dir, "drafts"),
+		watermarks: filepath.Join(issuesDir, Dir, "watermark.json"),
+	}
+}
+
+func (s *Store) path(issueNumber string) string {
+	return filepath.Join(s.dir, issueNumber+".json")
+}
+
+// loadBase reads the base stored under number into a new T, or returns nil
+// if none was recorded. Issue, pull request and discussion bases share it.
+func loadBase[T any](s *Store, number string) (*T, error) {
+	raw, err := os.ReadFile(s.path(number))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read base snapshot: %w", err)
+	}
+	
+	base := new(T)
+	if err := json.Unmarshal(raw, base); err != nil {
+		return nil, fmt.Errorf("failed to parse base snapshot %s: %w", s.path(number), err)
+	}
+	
+	return base, nil
+}
+
+func (s *Store) saveBase(number string, base any) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create base directory: %w", err)
+	}
+	
+	data, err := json.MarshalIndent(base, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode base snapshot: %w", err)
+	}
+	
+	return filefmt.AtomicWriteFile(s.path(number), append(data, '\n'), 0o644)
+}
+
+// Load returns the stored base for an issue, or nil if none was recorded.
+func (s *Store) Load(issueNumber string) (*Base, error) {
+	return loadBase[Base](s, issueNumber)
+}
+
+func (s *Store) Save(base *Base) error {
+	return s.saveBase(base.Number, base)
+}
+
+func (s *Store) Delete(issueNumber string) error {
+	if err := os.Remove(s.path(issueNumber)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete base snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadPR returns the stored base for a pull request, or nil if none was
+// recorded. Bases are keyed by number alone, so callers open the store on
+// the pull requests directory to keep them apart from issue bases.
+func (s *Store) LoadPR(prNumber string) (*PRBase, error) {
+	return loadBase[PRBase](s, prNumber)
+}
+
+func (s *Store) SavePR(base *PRBase) error {
+	return s.saveBase(base.Number, base)
+}
+
+// LoadDiscussion returns the stored base for a discussion, or nil if none
+// was recorded. Like pull request bases, callers keep them apart by opening
+// the store on the discussions directory.
+func (s *Store) LoadDiscussion(number string) (*DiscussionBase, error) {
+	return loadBase[DiscussionBase](s, number)
+}
+
+func (s *Store) SaveDiscussion(base *DiscussionBase) error {
+	return s.saveBase(base.Number, base)
+}
+
+// Journal records an attempt to create an issue from a draft file. It is
+// written before the issue is created and kept until the draft has been
+// renamed, so a retry after a crash can find the issue instead of creating
+// it twice. Number is zero until GitHub has answered.
+type Journal struct {
+	Draft     string    `json:"draft"`
+	Title     string    `json:"title"`
+	BodyHash  string    `json:"bodyHash"`
+	StartedAt time.Time `json:"startedAt"`
+	Number    int       `json:"number,omitempty"`
+}
+
+func (s *Store) journalPath(draft string) string {
+	return filepath.Join(s.drafts, draft+".json")
+}
+
+// LoadJournal returns the journal for a draft file name, or nil if there is none.
+func (s *Store) LoadJournal(draft string) (*Journal, error) {
+	raw, err := os.ReadFile(s.journalPath(draft))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read draft journal: %w", err)
+	}
+	
+	var j Journal
+	if err := json.Unmarshal(raw, &j); err != nil {
+		return nil, fmt.Errorf("failed to parse draft journal %s: %w", s.journalPath(draft), err)
+	}
+	
+	return &j, nil
+}
+
+func (s *Store) SaveJournal(j *Journal) error {
+	if err := os.MkdirAll(s.drafts, 0o755); err != nil {
+		return fmt.Errorf("failed to create drafts directory: %w", err)
+	}
+	
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode draft journal: %w", err)
+	}
+	
+	return filefmt.AtomicWriteFile(s.journalPath(j.Draft), append(data, '\n'), 0o644)
+}
+
+// Journals returns the draft names that have a journal.
+func (s *Store) Journals() ([]string, error) {
+	entries, err := os.ReadDir(s.drafts)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read drafts directory: %w", err)
+	}
+	
+	var drafts []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			drafts = append(drafts, name)
+		}
+	}
+	return drafts, nil
+}
+
+func (s *Store) DeleteJournal(draft string) error {
+	if err := os.Remove(s.journalPath(draft)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete draft journal: %w", err)
+	}
+	return nil
+}
+
+// A watermark is the newest update time pull --all has brought in for one
+// state filter ("open", "closed" or "all"), so the next pull only asks for
+// issues updated since.
+func (s *Store) loadWatermarks() (map[string]time.Time, error) {
+	raw, err := os.ReadFile(s.watermarks)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]time.Time{}, nil
+		}
+		return nil, fmt.Errorf("failed to read watermark: %w", err)
+	}
+	
+	marks := map[string]time.Time{}
+	if err := json.Unmarshal(raw, &marks); err != nil {
+		return nil, fmt.Errorf("failed to parse watermark %s: %w", s.watermarks, err)
+	}
+	return marks, nil
+}
+
+// LoadWatermark returns the watermark for a state filter, or the zero time
+// if none was recorded.
+func (s *Store) LoadWatermark(state string) (time.Time, error) {
+	marks, err := s.loadWatermarks()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return marks[state], nil
+}
+
+func (s *Store) SaveWatermark(state string, mark time.Time) error {
+	marks, err := s.loadWatermarks()
+	if err != nil {
+		return err
+	}
+	marks[state] = mark.UTC()
+	
+	if err := os.MkdirAll(filepath.Dir(s.watermarks), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", Dir, err)
+	}
+	data, err := json.MarshalIndent(marks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode watermark: %w", err)
+	}
+	return filefmt.AtomicWriteFile(s.watermarks, append(data, '\n'), 0o644)
+}
+
+func HashBody(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
\ No newline at end of file